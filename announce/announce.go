@@ -0,0 +1,103 @@
+package announce
+
+import (
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// imetaFields são as tags NIP-94 copiadas para a imeta da nota, na ordem em
+// que aparecem na tag.
+var imetaFields = []string{"url", "m", "x", "ox", "size", "dim", "blurhash", "thumb", "image", "alt", "fallback"}
+
+// IMeta devolve a tag imeta (NIP-92) do arquivo principal do evento: a
+// primeira imeta de um vídeo NIP-71 ou, em eventos NIP-94, uma imeta montada a
+// partir das tags url, m, x, size, dim e demais. ok é false se o evento não
+// tiver URL.
+func IMeta(evt nostr.Event) (url string, tag nostr.Tag, ok bool) {
+	for _, t := range evt.Tags {
+		if len(t) < 2 || t[0] != "imeta" {
+			continue
+		}
+		for _, field := range t[1:] {
+			if u, found := strings.CutPrefix(field, "url "); found {
+				return u, t, true
+			}
+		}
+	}
+
+	tag = nostr.Tag{"imeta"}
+	for _, name := range imetaFields {
+		for _, t := range evt.Tags {
+			if len(t) >= 2 && t[0] == name && t[1] != "" {
+				tag = append(tag, name+" "+t[1])
+				if name == "url" {
+					url = t[1]
+				}
+			}
+		}
+	}
+	return url, tag, url != ""
+}
+
+// Hashtags normaliza uma lista de hashtags separadas por vírgulas ou espaços,
+// removendo o "#" e as repetidas.
+func Hashtags(s string) []string {
+	var out []string
+	for _, h := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' || r == '\n' }) {
+		h = strings.TrimPrefix(strings.TrimSpace(h), "#")
+		if h == "" {
+			continue
+		}
+		dup := false
+		for _, o := range out {
+			if strings.EqualFold(o, h) {
+				dup = true
+			}
+		}
+		if !dup {
+			out = append(out, h)
+		}
+	}
+	return out
+}
+
+// Note monta, sem assinar, a nota kind 1 que anuncia um evento publicado. O
+// conteúdo traz o texto, a URL do arquivo (descrita pela tag imeta), a menção
+// nostr: ao evento e as hashtags. quote é o id (ou a coordenada, em eventos
+// endereçáveis) citado na tag "q" (NIP-18), com a dica de relay relayHint.
+func Note(target nostr.Event, mention, quote, relayHint, text string, hashtags []string) nostr.Event {
+	var parts []string
+	if text = strings.TrimSpace(text); text != "" {
+		parts = append(parts, text)
+	}
+	var tags nostr.Tags
+	if url, imeta, ok := IMeta(target); ok {
+		parts = append(parts, url)
+		tags = append(tags, imeta)
+	}
+	parts = append(parts, mention)
+
+	q := nostr.Tag{"q", quote, relayHint}
+	if !nostr.IsAddressableKind(target.Kind) {
+		q = append(q, target.PubKey)
+	}
+	tags = append(tags, q)
+
+	if len(hashtags) > 0 {
+		words := make([]string, len(hashtags))
+		for i, h := range hashtags {
+			words[i] = "#" + h
+			tags = append(tags, nostr.Tag{"t", strings.ToLower(h)})
+		}
+		parts = append(parts, strings.Join(words, " "))
+	}
+
+	return nostr.Event{
+		Kind:      nostr.KindTextNote,
+		Content:   strings.Join(parts, "\n\n"),
+		Tags:      tags,
+		CreatedAt: nostr.Now(),
+		PubKey:    target.PubKey,
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"NostrFilePublisher/announce"
+	"NostrFilePublisher/nip09"
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// showAnnounceDialog escreve uma nota kind 1 anunciando o evento publicado,
+// com o texto e as hashtags editáveis, e a envia pelo fluxo normal de publicação.
+func showAnnounceDialog(win fyne.Window, target nostr.Event, relays []string) {
+	if App.Nsec == "" {
+		dialog.ShowInformation("Atenção", "Por favor, configure sua chave NSEC na aba de Configurações.", win)
+		return
+	}
+	links, err := eventShareLinks(target, relays)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("Erro ao gerar os links do evento: %w", err), win)
+		return
+	}
+	quote := target.ID
+	if coordinate := nip09.Coordinate(target); coordinate != "" {
+		quote = coordinate
+	}
+	var relayHint string
+	if len(relays) > 0 {
+		relayHint = relays[0]
+	}
+
+	// O texto inicial vem do título e do resumo; as hashtags, das tags "t"
+	var text []string
+	for _, name := range []string{"title", "summary"} {
+		if tag := target.Tags.Find(name); tag != nil && tag[1] != "" {
+			text = append(text, tag[1])
+		}
+	}
+	var hashtags []string
+	for _, tag := range target.Tags {
+		if len(tag) >= 2 && tag[0] == "t" {
+			hashtags = append(hashtags, tag[1])
+		}
+	}
+
+	textEntry := widget.NewMultiLineEntry()
+	textEntry.Wrapping = fyne.TextWrapWord
+	textEntry.SetText(strings.Join(text, "\n\n"))
+	textEntry.SetMinRowsVisible(4)
+	hashtagsEntry := widget.NewEntry()
+	hashtagsEntry.SetPlaceHolder("nostr, video, arte")
+	hashtagsEntry.SetText(strings.Join(hashtags, ", "))
+
+	preview := widget.NewMultiLineEntry()
+	preview.Wrapping = fyne.TextWrapWord
+	preview.SetMinRowsVisible(6)
+	preview.Disable()
+	build := func() nostr.Event {
+		return announce.Note(target, links.URI, quote, relayHint, textEntry.Text, announce.Hashtags(hashtagsEntry.Text))
+	}
+	updatePreview := func(string) { preview.SetText(build().Content) }
+	textEntry.OnChanged = updatePreview
+	hashtagsEntry.OnChanged = updatePreview
+	updatePreview("")
+
+	content := container.NewVBox(
+		widget.NewLabel("Texto da nota:"),
+		textEntry,
+		widget.NewLabel("Hashtags (separadas por vírgula):"),
+		hashtagsEntry,
+		widget.NewLabel("Pré-visualização:"),
+		preview,
+	)
+	d := dialog.NewCustomConfirm("Anunciar Publicação", "Publicar Nota", "Cancelar", content, func(ok bool) {
+		if !ok {
+			return
+		}
+		note := build()
+		if err := note.Sign(App.Nsec); err != nil {
+			dialog.ShowError(fmt.Errorf("Erro ao assinar a nota: %w", err), win)
+			return
+		}
+		showPublishDialog(win, note)
+	}, win)
+	d.Resize(fyne.NewSize(600, 0))
+	d.Show()
+}
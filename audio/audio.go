@@ -0,0 +1,151 @@
+package audio
+
+import (
+	"NostrFilePublisher/ffmpeg"
+	"NostrFilePublisher/mediainfo"
+	"NostrFilePublisher/mpegaudio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// ErrUnsupported indica que o formato do áudio não é reconhecido.
+var ErrUnsupported = errors.New("unsupported audio format")
+
+// Info reúne os metadados técnicos de um arquivo de áudio.
+type Info struct {
+	// Format é o formato do arquivo: "wav", "mp3", "flac", "ogg", "opus", "mp4" ou "matroska".
+	Format string
+
+	// Duration é a duração em segundos.
+	Duration float64
+
+	SampleRate int
+	Channels   int
+
+	// Bitrate é a taxa média em bits por segundo, calculada a partir do tamanho do arquivo.
+	Bitrate int64
+}
+
+// String devolve um resumo legível dos metadados.
+func (i Info) String() string {
+	var parts []string
+	if i.Format != "" {
+		parts = append(parts, "Formato: "+i.Format)
+	}
+	if i.Duration > 0 {
+		parts = append(parts, "Duração: "+(time.Duration(i.Duration*float64(time.Second))).Round(time.Second).String())
+	}
+	if i.SampleRate > 0 {
+		parts = append(parts, fmt.Sprintf("%d Hz", i.SampleRate))
+	}
+	if i.Channels > 0 {
+		parts = append(parts, fmt.Sprintf("%d canal(is)", i.Channels))
+	}
+	if i.Bitrate > 0 {
+		parts = append(parts, fmt.Sprintf("%d kbps", i.Bitrate/1000))
+	}
+	return strings.Join(parts, " | ")
+}
+
+// Probe lê a duração e o formato do arquivo. WAV, MP3, FLAC e Ogg são lidos
+// pelos cabeçalhos, MP4 e Matroska pelo pacote mediainfo; para os demais
+// formatos é usado o ffprobe, se disponível.
+func Probe(ctx context.Context, path string) (Info, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Info{}, err
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil {
+		return Info{}, err
+	}
+
+	info, err := ProbeReader(f, stat.Size())
+	if errors.Is(err, ErrUnsupported) {
+		duration, ffErr := ffmpeg.Duration(ctx, path)
+		if ffErr != nil {
+			return Info{}, err
+		}
+		info, err = Info{Duration: duration}, nil
+	}
+	if err != nil {
+		return Info{}, err
+	}
+	if info.Duration > 0 {
+		info.Bitrate = int64(float64(stat.Size()*8) / info.Duration)
+	}
+	return info, nil
+}
+
+// ProbeReader identifica o formato pelo cabeçalho e lê seus metadados.
+func ProbeReader(r io.ReadSeeker, size int64) (Info, error) {
+	// O cabeçalho alcança o segundo quadro de um MP3 sem tag ID3
+	header := make([]byte, 4096)
+	n, err := io.ReadFull(r, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return Info{}, err
+	}
+	header = header[:n]
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return Info{}, err
+	}
+
+	switch {
+	case len(header) >= 12 && string(header[:4]) == "RIFF" && string(header[8:12]) == "WAVE":
+		format, err := readWAVHeader(r)
+		if err != nil {
+			return Info{}, err
+		}
+		return format.info(), nil
+	case len(header) >= 4 && string(header[:4]) == "fLaC":
+		return probeFLAC(r)
+	case len(header) >= 4 && string(header[:4]) == "OggS":
+		return probeOgg(r, size)
+	case len(header) >= 3 && string(header[:3]) == "ID3",
+		mpegaudio.IsStream(header, size):
+		return probeMP3(r, size)
+	}
+
+	mi, err := mediainfo.ProbeReader(r, size)
+	if err != nil {
+		if errors.Is(err, mediainfo.ErrUnsupported) {
+			return Info{}, ErrUnsupported
+		}
+		return Info{}, err
+	}
+	return Info{Format: mi.Container, Duration: mi.Duration}, nil
+}
+
+// analysisRate é a taxa de amostragem usada ao decodificar com o ffmpeg,
+// suficiente para a forma de onda e leve para arquivos longos.
+const analysisRate = 8000
+
+// Analyze decodifica o áudio e acumula suas amostras em um Envelope, do qual
+// saem a duração e a forma de onda. WAV PCM é lido diretamente; os demais
+// formatos são decodificados pelo ffmpeg, se disponível.
+func Analyze(ctx context.Context, path string) (*Envelope, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	env, err := DecodeWAV(ctx, f)
+	f.Close()
+	if !errors.Is(err, ErrUnsupported) {
+		return env, err
+	}
+
+	env = NewEnvelope(analysisRate)
+	err = ffmpeg.PCM(ctx, path, analysisRate, func(r io.Reader) error {
+		return env.ReadPCM16(ctx, r)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return env, nil
+}
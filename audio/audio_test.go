@@ -0,0 +1,155 @@
+package audio
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"math"
+	"slices"
+	"testing"
+)
+
+// wavFile monta um WAV PCM de 16 bits mono com as amostras informadas. Com
+// dataSize zero, o tamanho do bloco "data" fica zerado, como em gravações
+// interrompidas.
+func wavFile(sampleRate int, samples []int16, zeroSize bool) []byte {
+	data := make([]byte, 0, 2*len(samples))
+	for _, s := range samples {
+		data = binary.LittleEndian.AppendUint16(data, uint16(s))
+	}
+	b := []byte("RIFF")
+	b = binary.LittleEndian.AppendUint32(b, uint32(36+len(data)))
+	b = append(b, "WAVEfmt "...)
+	b = binary.LittleEndian.AppendUint32(b, 16)
+	b = binary.LittleEndian.AppendUint16(b, wavPCM)
+	b = binary.LittleEndian.AppendUint16(b, 1)
+	b = binary.LittleEndian.AppendUint32(b, uint32(sampleRate))
+	b = binary.LittleEndian.AppendUint32(b, uint32(2*sampleRate))
+	b = binary.LittleEndian.AppendUint16(b, 2)
+	b = binary.LittleEndian.AppendUint16(b, 16)
+	b = append(b, "data"...)
+	if zeroSize {
+		b = binary.LittleEndian.AppendUint32(b, 0)
+	} else {
+		b = binary.LittleEndian.AppendUint32(b, uint32(len(data)))
+	}
+	return append(b, data...)
+}
+
+// mp3Frames devolve n quadros MPEG-1 Layer III estéreo de 128 kbps a 44,1 kHz.
+func mp3Frames(n int) []byte {
+	frame := append([]byte{0xFF, 0xFB, 0x90, 0x04}, make([]byte, 413)...)
+	return bytes.Repeat(frame, n)
+}
+
+// xingFrames devolve um quadro com o cabeçalho Xing informando total quadros.
+func xingFrames(total uint32) []byte {
+	frame := mp3Frames(1)
+	copy(frame[4+32:], "Xing")
+	binary.BigEndian.PutUint32(frame[4+32+4:], 1)
+	binary.BigEndian.PutUint32(frame[4+32+8:], total)
+	return frame
+}
+
+// flacFile monta o início de um FLAC com o bloco STREAMINFO.
+func flacFile(sampleRate, channels int, total int64) []byte {
+	b := []byte("fLaC")
+	b = append(b, 0x80, 0, 0, 34) // último bloco, STREAMINFO, 34 bytes
+	info := make([]byte, 34)
+	packed := uint64(sampleRate)<<44 | uint64(channels-1)<<41 | uint64(15)<<36 | uint64(total)
+	binary.BigEndian.PutUint64(info[10:], packed)
+	return append(b, info...)
+}
+
+func TestProbeReader(t *testing.T) {
+	id3 := append([]byte("ID3\x04\x00\x00\x00\x00\x00\x14"), make([]byte, 20)...)
+	tests := []struct {
+		name string
+		data []byte
+		want Info
+	}{
+		{"WAV", wavFile(8000, make([]int16, 8000), false), Info{Format: "wav", Duration: 1, SampleRate: 8000, Channels: 1}},
+		{"WAV interrompido", wavFile(8000, make([]int16, 4000), true), Info{Format: "wav", Duration: 0.5, SampleRate: 8000, Channels: 1}},
+		{"MP3 CBR", mp3Frames(100), Info{Format: "mp3", Duration: 100 * 417 * 8 / 128000.0, SampleRate: 44100, Channels: 2}},
+		{"MP3 com ID3", append(id3, mp3Frames(100)...), Info{Format: "mp3", Duration: 100 * 417 * 8 / 128000.0, SampleRate: 44100, Channels: 2}},
+		{"MP3 com Xing", append(xingFrames(1000), mp3Frames(3)...), Info{Format: "mp3", Duration: 1000 * 1152 / 44100.0, SampleRate: 44100, Channels: 2}},
+		{"FLAC", flacFile(44100, 2, 441000), Info{Format: "flac", Duration: 10, SampleRate: 44100, Channels: 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ProbeReader(bytes.NewReader(tt.data), int64(len(tt.data)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Format != tt.want.Format || got.SampleRate != tt.want.SampleRate || got.Channels != tt.want.Channels ||
+				math.Abs(got.Duration-tt.want.Duration) > 1e-9 {
+				t.Errorf("ProbeReader = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProbeReaderUnsupported(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		// O BOM UTF-16LE começa com 0xFF 0xFE, mas não é um quadro MPEG
+		{"texto UTF-16", []byte("\xff\xfeH\x00e\x00l\x00l\x00o\x00")},
+		{"taxa de bits livre", []byte{0xFF, 0xFB, 0x00, 0x04, 0, 0, 0, 0, 0, 0, 0, 0}},
+		{"texto", []byte("just some text")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ProbeReader(bytes.NewReader(tt.data), int64(len(tt.data))); !errors.Is(err, ErrUnsupported) {
+				t.Errorf("ProbeReader = %v, want ErrUnsupported", err)
+			}
+		})
+	}
+}
+
+func TestDecodeWAVWaveform(t *testing.T) {
+	// Meio segundo a 50% da amplitude e meio segundo a 25%, em onda quadrada
+	const rate = 8000
+	samples := make([]int16, rate)
+	for i := range samples {
+		amp := int16(16384)
+		if i >= rate/2 {
+			amp = 8192
+		}
+		if i%2 == 1 {
+			amp = -amp
+		}
+		samples[i] = amp
+	}
+	env, err := DecodeWAV(context.Background(), bytes.NewReader(wavFile(rate, samples, false)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if env.Samples() != rate || env.Duration() != 1 {
+		t.Errorf("Samples, Duration = %d, %v, want %d, 1", env.Samples(), env.Duration(), rate)
+	}
+
+	tests := []struct {
+		points int
+		want   []int
+	}{
+		{2, []int{100, 50}},
+		{4, []int{100, 100, 50, 50}},
+		{0, nil},
+	}
+	for _, tt := range tests {
+		if got := env.Waveform(tt.points); !slices.Equal(got, tt.want) {
+			t.Errorf("Waveform(%d) = %v, want %v", tt.points, got, tt.want)
+		}
+	}
+
+	silence := NewEnvelope(rate)
+	for range rate {
+		silence.Add(0)
+	}
+	if got := silence.Waveform(4); !slices.Equal(got, []int{0, 0, 0, 0}) {
+		t.Errorf("silent Waveform = %v, want zeros", got)
+	}
+}
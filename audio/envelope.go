@@ -0,0 +1,111 @@
+package audio
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+)
+
+// blocksPerSecond é a resolução do envelope. A energia é guardada por bloco,
+// e não por amostra, para que arquivos longos ocupem pouca memória.
+const blocksPerSecond = 20
+
+// Envelope acumula a energia (RMS) de amostras mono em blocos curtos.
+type Envelope struct {
+	SampleRate int
+
+	blockSize int
+	blocks    []float64 // média dos quadrados de cada bloco completo
+	sum       float64
+	n         int
+	samples   int64
+}
+
+// NewEnvelope cria um envelope para amostras na taxa informada.
+func NewEnvelope(sampleRate int) *Envelope {
+	return &Envelope{SampleRate: sampleRate, blockSize: max(sampleRate/blocksPerSecond, 1)}
+}
+
+// Add acumula uma amostra entre -1 e 1.
+func (e *Envelope) Add(sample float64) {
+	e.sum += sample * sample
+	e.n++
+	e.samples++
+	if e.n == e.blockSize {
+		e.blocks = append(e.blocks, e.sum/float64(e.n))
+		e.sum, e.n = 0, 0
+	}
+}
+
+// ReadPCM16 acumula amostras mono de 16 bits little-endian até o fim de r.
+func (e *Envelope) ReadPCM16(ctx context.Context, r io.Reader) error {
+	buf := make([]byte, 32<<10)
+	var carry []byte
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		n, err := r.Read(buf)
+		data := append(carry, buf[:n]...)
+		for len(data) >= 2 {
+			e.Add(float64(int16(binary.LittleEndian.Uint16(data))) / 32768)
+			data = data[2:]
+		}
+		carry = append(carry[:0], data...)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Samples devolve o número de amostras acumuladas.
+func (e *Envelope) Samples() int64 {
+	return e.samples
+}
+
+// Duration devolve a duração, em segundos, das amostras acumuladas.
+func (e *Envelope) Duration() float64 {
+	if e.SampleRate <= 0 {
+		return 0
+	}
+	return float64(e.samples) / float64(e.SampleRate)
+}
+
+// Waveform reduz o envelope a até `points` amplitudes entre 0 e 100,
+// normalizadas pelo trecho mais alto do áudio.
+func (e *Envelope) Waveform(points int) []int {
+	blocks := e.blocks
+	if e.n > 0 {
+		blocks = append(blocks[:len(blocks):len(blocks)], e.sum/float64(e.n))
+	}
+	if len(blocks) == 0 || points <= 0 {
+		return nil
+	}
+	points = min(points, len(blocks))
+
+	rms := make([]float64, points)
+	peak := 0.0
+	for i := range rms {
+		start, end := i*len(blocks)/points, (i+1)*len(blocks)/points
+		var sum float64
+		for _, b := range blocks[start:end] {
+			sum += b
+		}
+		rms[i] = math.Sqrt(sum / float64(end-start))
+		peak = max(peak, rms[i])
+	}
+
+	waveform := make([]int, points)
+	if peak == 0 {
+		return waveform
+	}
+	for i, v := range rms {
+		waveform[i] = int(math.Round(v / peak * 100))
+	}
+	return waveform
+}
@@ -0,0 +1,34 @@
+package audio
+
+import (
+	"errors"
+	"io"
+)
+
+// probeFLAC lê o bloco STREAMINFO, que é sempre o primeiro bloco de metadados
+// e traz a taxa de amostragem, os canais e o total de amostras.
+func probeFLAC(r io.Reader) (Info, error) {
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return Info{}, err
+	}
+	if header[4]&0x7F != 0 {
+		return Info{}, errors.New("FLAC STREAMINFO block not found")
+	}
+	block := make([]byte, 34)
+	if _, err := io.ReadFull(r, block); err != nil {
+		return Info{}, err
+	}
+
+	b := block[10:18]
+	info := Info{
+		Format:     "flac",
+		SampleRate: int(b[0])<<12 | int(b[1])<<4 | int(b[2])>>4,
+		Channels:   int(b[2]>>1&0x07) + 1,
+	}
+	total := int64(b[3]&0x0F)<<32 | int64(b[4])<<24 | int64(b[5])<<16 | int64(b[6])<<8 | int64(b[7])
+	if info.SampleRate > 0 {
+		info.Duration = float64(total) / float64(info.SampleRate)
+	}
+	return info, nil
+}
@@ -0,0 +1,98 @@
+package audio
+
+import (
+	"NostrFilePublisher/mpegaudio"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// mp3SearchLimit é o trecho lido após a tag ID3v2 ao procurar o primeiro quadro.
+const mp3SearchLimit = 64 << 10
+
+// probeMP3 calcula a duração pelo cabeçalho Xing/Info ou VBRI do primeiro
+// quadro, que traz o total de quadros dos arquivos VBR. Sem eles, o arquivo é
+// tratado como CBR e a duração é estimada pelo tamanho e pela taxa de bits.
+func probeMP3(r io.ReadSeeker, size int64) (Info, error) {
+	var start int64
+	header := make([]byte, 10)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return Info{}, err
+	}
+	if string(header[:3]) == "ID3" {
+		start = 10 + int64(syncsafe(header[6:10]))
+		if header[5]&0x10 != 0 {
+			start += 10 // rodapé
+		}
+	}
+	if _, err := r.Seek(start, io.SeekStart); err != nil {
+		return Info{}, err
+	}
+	data, err := io.ReadAll(io.LimitReader(r, mp3SearchLimit))
+	if err != nil {
+		return Info{}, err
+	}
+
+	for i := 0; i+4 <= len(data); i++ {
+		f, ok := mpegaudio.ParseFrame(data[i : i+4])
+		if !ok {
+			continue
+		}
+		info := Info{Format: "mp3", SampleRate: f.SampleRate, Channels: f.Channels}
+		if frames := vbrFrames(data[i:], f); frames > 0 {
+			info.Duration = float64(frames) * float64(f.Samples) / float64(f.SampleRate)
+			return info, nil
+		}
+
+		audioSize := size - start - int64(i)
+		if hasID3v1(r, size) {
+			audioSize -= 128
+		}
+		info.Duration = float64(audioSize*8) / float64(f.Bitrate)
+		return info, nil
+	}
+	return Info{}, errors.New("MPEG audio frame not found")
+}
+
+// vbrFrames devolve o total de quadros informado pelo cabeçalho Xing/Info ou VBRI, ou zero.
+func vbrFrames(frame []byte, f mpegaudio.Frame) int64 {
+	// O cabeçalho Xing fica depois das informações laterais do quadro
+	side := 32
+	switch {
+	case f.MPEG1 && f.Channels == 1:
+		side = 17
+	case !f.MPEG1 && f.Channels == 1:
+		side = 9
+	case !f.MPEG1:
+		side = 17
+	}
+	if x := frame[min(4+side, len(frame)):]; len(x) >= 12 && (string(x[:4]) == "Xing" || string(x[:4]) == "Info") {
+		if binary.BigEndian.Uint32(x[4:8])&0x01 != 0 {
+			return int64(binary.BigEndian.Uint32(x[8:12]))
+		}
+	}
+	if len(frame) >= 36+18 && string(frame[36:40]) == "VBRI" {
+		return int64(binary.BigEndian.Uint32(frame[36+14 : 36+18]))
+	}
+	return 0
+}
+
+// hasID3v1 informa se os últimos 128 bytes do arquivo são uma tag ID3v1.
+func hasID3v1(r io.ReadSeeker, size int64) bool {
+	if size < 128 {
+		return false
+	}
+	if _, err := r.Seek(size-128, io.SeekStart); err != nil {
+		return false
+	}
+	tag := make([]byte, 3)
+	if _, err := io.ReadFull(r, tag); err != nil {
+		return false
+	}
+	return string(tag) == "TAG"
+}
+
+// syncsafe decodifica um inteiro de 28 bits em que o bit mais alto de cada byte é zero.
+func syncsafe(b []byte) int {
+	return int(b[0])<<21 | int(b[1])<<14 | int(b[2])<<7 | int(b[3])
+}
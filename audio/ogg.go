@@ -0,0 +1,90 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// oggTailSize é o trecho final do arquivo onde a última página é procurada.
+const oggTailSize = 64 << 10
+
+// opusGranuleRate é a taxa de referência das posições de um fluxo Opus,
+// qualquer que seja a taxa original.
+const opusGranuleRate = 48000
+
+// probeOgg lê o cabeçalho de identificação da primeira página (Vorbis ou
+// Opus) e calcula a duração pela posição (granule) da última página.
+func probeOgg(r io.ReadSeeker, size int64) (Info, error) {
+	page := make([]byte, 27)
+	if _, err := io.ReadFull(r, page); err != nil {
+		return Info{}, err
+	}
+	segments := make([]byte, page[26])
+	if _, err := io.ReadFull(r, segments); err != nil {
+		return Info{}, err
+	}
+	var packetSize int
+	for _, s := range segments {
+		packetSize += int(s)
+		if s < 255 {
+			break
+		}
+	}
+	packet := make([]byte, packetSize)
+	if _, err := io.ReadFull(r, packet); err != nil {
+		return Info{}, err
+	}
+	serial := binary.LittleEndian.Uint32(page[14:18])
+
+	var info Info
+	var preSkip int64
+	granuleRate := 0
+	switch {
+	case len(packet) >= 16 && string(packet[:7]) == "\x01vorbis":
+		info = Info{Format: "ogg", Channels: int(packet[11]), SampleRate: int(binary.LittleEndian.Uint32(packet[12:16]))}
+		granuleRate = info.SampleRate
+	case len(packet) >= 16 && string(packet[:8]) == "OpusHead":
+		info = Info{Format: "opus", Channels: int(packet[9]), SampleRate: int(binary.LittleEndian.Uint32(packet[12:16]))}
+		preSkip = int64(binary.LittleEndian.Uint16(packet[10:12]))
+		granuleRate = opusGranuleRate
+	default:
+		return Info{}, ErrUnsupported
+	}
+
+	granule, err := lastGranule(r, size, serial)
+	if err != nil {
+		return Info{}, err
+	}
+	if granuleRate > 0 && granule > preSkip {
+		info.Duration = float64(granule-preSkip) / float64(granuleRate)
+	}
+	return info, nil
+}
+
+// lastGranule procura no fim do arquivo a última página do fluxo e devolve sua posição.
+func lastGranule(r io.ReadSeeker, size int64, serial uint32) (int64, error) {
+	start := max(size-oggTailSize, 0)
+	if _, err := r.Seek(start, io.SeekStart); err != nil {
+		return 0, err
+	}
+	tail, err := io.ReadAll(io.LimitReader(r, oggTailSize))
+	if err != nil {
+		return 0, err
+	}
+	for end := len(tail); ; {
+		i := bytes.LastIndex(tail[:end], []byte("OggS"))
+		if i < 0 {
+			return 0, errors.New("last Ogg page not found")
+		}
+		if i+27 <= len(tail) && binary.LittleEndian.Uint32(tail[i+14:i+18]) == serial {
+			granule := int64(binary.LittleEndian.Uint64(tail[i+6 : i+14]))
+			// -1 indica uma página sem pacote terminado
+			if granule >= 0 {
+				return granule, nil
+			}
+		}
+		end = i
+	}
+}
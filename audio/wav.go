@@ -0,0 +1,192 @@
+package audio
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Códigos de formato do bloco "fmt " de arquivos WAV.
+const (
+	wavPCM        = 1
+	wavFloat      = 3
+	wavExtensible = 0xFFFE
+)
+
+// wavFormat descreve o bloco "fmt " e o tamanho do bloco "data".
+type wavFormat struct {
+	code       uint16
+	channels   int
+	sampleRate int
+	byteRate   int
+	blockAlign int
+	bits       int
+	dataSize   int64
+}
+
+// info converte o cabeçalho em Info.
+func (f wavFormat) info() Info {
+	info := Info{Format: "wav", SampleRate: f.sampleRate, Channels: f.channels}
+	if f.byteRate > 0 {
+		info.Duration = float64(f.dataSize) / float64(f.byteRate)
+	}
+	return info
+}
+
+// decodable informa se as amostras podem ser lidas sem um decodificador externo.
+func (f wavFormat) decodable() bool {
+	switch f.code {
+	case wavPCM:
+		return f.bits == 8 || f.bits == 16 || f.bits == 24 || f.bits == 32
+	case wavFloat:
+		return f.bits == 32 || f.bits == 64
+	}
+	return false
+}
+
+// readWAVHeader percorre os blocos do RIFF até o início do bloco "data",
+// deixando r posicionado nas amostras.
+func readWAVHeader(r io.ReadSeeker) (wavFormat, error) {
+	var f wavFormat
+	riff := make([]byte, 12)
+	if _, err := io.ReadFull(r, riff); err != nil {
+		return f, err
+	}
+	if string(riff[:4]) != "RIFF" || string(riff[8:12]) != "WAVE" {
+		return f, ErrUnsupported
+	}
+
+	haveFormat := false
+	chunk := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(r, chunk); err != nil {
+			return f, fmt.Errorf("WAV data chunk not found: %w", err)
+		}
+		id := string(chunk[:4])
+		size := int64(binary.LittleEndian.Uint32(chunk[4:8]))
+
+		switch id {
+		case "fmt ":
+			if size < 16 || size > 1024 {
+				return f, errors.New("invalid WAV fmt chunk")
+			}
+			body := make([]byte, size)
+			if _, err := io.ReadFull(r, body); err != nil {
+				return f, err
+			}
+			f.code = binary.LittleEndian.Uint16(body[0:2])
+			f.channels = int(binary.LittleEndian.Uint16(body[2:4]))
+			f.sampleRate = int(binary.LittleEndian.Uint32(body[4:8]))
+			f.byteRate = int(binary.LittleEndian.Uint32(body[8:12]))
+			f.blockAlign = int(binary.LittleEndian.Uint16(body[12:14]))
+			f.bits = int(binary.LittleEndian.Uint16(body[14:16]))
+			// No formato extensível o código real está no início do GUID do subformato
+			if f.code == wavExtensible && size >= 40 {
+				f.code = binary.LittleEndian.Uint16(body[24:26])
+			}
+			if f.channels <= 0 || f.sampleRate <= 0 || f.blockAlign <= 0 {
+				return f, errors.New("invalid WAV fmt chunk")
+			}
+			haveFormat = true
+			if size%2 == 1 {
+				if _, err := r.Seek(1, io.SeekCurrent); err != nil {
+					return f, err
+				}
+			}
+		case "data":
+			if !haveFormat {
+				return f, errors.New("WAV data chunk before fmt chunk")
+			}
+			f.dataSize = size
+			// Gravações interrompidas deixam o tamanho zerado ou no máximo
+			if size == 0 || size == math.MaxUint32 {
+				pos, err := r.Seek(0, io.SeekCurrent)
+				if err != nil {
+					return f, err
+				}
+				end, err := r.Seek(0, io.SeekEnd)
+				if err != nil {
+					return f, err
+				}
+				if _, err := r.Seek(pos, io.SeekStart); err != nil {
+					return f, err
+				}
+				f.dataSize = end - pos
+			}
+			return f, nil
+		default:
+			if _, err := r.Seek(size+size%2, io.SeekCurrent); err != nil {
+				return f, err
+			}
+		}
+	}
+}
+
+// DecodeWAV lê as amostras de um WAV PCM ou de ponto flutuante, misturando
+// os canais em mono. Formatos comprimidos devolvem ErrUnsupported.
+func DecodeWAV(ctx context.Context, r io.ReadSeeker) (*Envelope, error) {
+	f, err := readWAVHeader(r)
+	if err != nil {
+		return nil, err
+	}
+	if !f.decodable() {
+		return nil, ErrUnsupported
+	}
+	if f.blockAlign < f.channels*f.bits/8 {
+		return nil, errors.New("invalid WAV block alignment")
+	}
+
+	env := NewEnvelope(f.sampleRate)
+	width := f.bits / 8
+	buf := make([]byte, f.blockAlign*4096)
+	remaining := f.dataSize
+	for remaining > 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		want := min(int64(len(buf)), remaining)
+		want -= want % int64(f.blockAlign)
+		if want == 0 {
+			break
+		}
+		n, err := io.ReadFull(r, buf[:want])
+		n -= n % f.blockAlign
+		for frame := buf[:n]; len(frame) >= f.blockAlign; frame = frame[f.blockAlign:] {
+			var sum float64
+			for c := 0; c < f.channels; c++ {
+				sum += f.sample(frame[c*width:])
+			}
+			env.Add(sum / float64(f.channels))
+		}
+		remaining -= int64(n)
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return env, nil
+}
+
+// sample converte uma amostra para o intervalo entre -1 e 1.
+func (f wavFormat) sample(b []byte) float64 {
+	switch {
+	case f.code == wavFloat && f.bits == 32:
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+	case f.code == wavFloat:
+		return math.Float64frombits(binary.LittleEndian.Uint64(b))
+	case f.bits == 8:
+		return (float64(b[0]) - 128) / 128
+	case f.bits == 16:
+		return float64(int16(binary.LittleEndian.Uint16(b))) / (1 << 15)
+	case f.bits == 24:
+		v := int32(b[0]) | int32(b[1])<<8 | int32(int8(b[2]))<<16
+		return float64(v) / (1 << 23)
+	default:
+		return float64(int32(binary.LittleEndian.Uint32(b))) / (1 << 31)
+	}
+}
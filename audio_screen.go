@@ -0,0 +1,340 @@
+package main
+
+import (
+	"NostrFilePublisher/announce"
+	"NostrFilePublisher/audio"
+	"NostrFilePublisher/extract"
+	"NostrFilePublisher/nip71"
+	"NostrFilePublisher/nipa0"
+	"NostrFilePublisher/sniff"
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// audioExtensions são as extensões oferecidas no seletor de áudio.
+var audioExtensions = []string{".mp3", ".m4a", ".aac", ".ogg", ".opus", ".oga", ".flac", ".wav", ".webm"}
+
+const (
+	audioModeFile  = "Arquivo de Áudio (Kind 1063)"
+	audioModeVoice = "Mensagem de Voz (Kind 1222)"
+)
+
+// waveformLevels desenham a forma de onda na pré-visualização.
+var waveformLevels = []rune("▁▂▃▄▅▆▇█")
+
+// waveformPreview representa as amplitudes (0 a 100) como uma linha de barras.
+func waveformPreview(values []int) string {
+	var b strings.Builder
+	for _, v := range values {
+		b.WriteRune(waveformLevels[min(v*len(waveformLevels)/101, len(waveformLevels)-1)])
+	}
+	return b.String()
+}
+
+// audioFile reúne o áudio enviado e os dados lidos dele.
+type audioFile struct {
+	path     string
+	mimeType string
+	info     audio.Info
+	waveform []int
+	upload   localUpload
+
+	// cid é o CIDv1 IPFS do arquivo enviado, se calculado.
+	cid string
+}
+
+// fallbacks devolve as URLs dos demais servidores que receberam o arquivo.
+func (a *audioFile) fallbacks() []string {
+	var urls []string
+	for _, r := range a.upload.Responses[1:] {
+		if r.URL != "" && r.URL != a.upload.Responses[0].URL {
+			urls = append(urls, r.URL)
+		}
+	}
+	return urls
+}
+
+// audioScreen monta a aba de áudio, que publica o arquivo como evento de
+// arquivo (kind 1063) ou como mensagem de voz da NIP-A0 (kind 1222).
+func audioScreen(win fyne.Window) fyne.CanvasObject {
+	var current *audioFile
+
+	titleEntry := widget.NewEntry()
+	titleEntry.SetPlaceHolder("Título do áudio...")
+
+	summaryEntry := widget.NewEntry()
+	summaryEntry.SetPlaceHolder("Resumo (ex: artista, álbum ou episódio)...")
+
+	altEntry := widget.NewEntry()
+	altEntry.SetPlaceHolder("Descrição acessível do áudio (alt)...")
+
+	hashtagsEntry := widget.NewEntry()
+	hashtagsEntry.SetPlaceHolder("podcast, música, entrevista")
+
+	descriptionEntry := widget.NewMultiLineEntry()
+	descriptionEntry.SetPlaceHolder("Descrição detalhada...")
+	descriptionEntry.Wrapping = fyne.TextWrapWord
+
+	// A capa vira a tag "image" do evento de arquivo
+	coverInput := newImageInput(win, "URL da Capa (opcional)")
+
+	fileInfoLabel := widget.NewLabel("Arquivo: (selecione um áudio)")
+	fileInfoLabel.Wrapping = fyne.TextWrapWord
+	waveformLabel := widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Monospace: true})
+
+	// Mensagens de voz têm apenas a URL do áudio no conteúdo, sem título nem capa
+	fileOnly := []fyne.Disableable{titleEntry, summaryEntry, altEntry, descriptionEntry, coverInput.entry}
+	modeRadio := widget.NewRadioGroup([]string{audioModeFile, audioModeVoice}, func(mode string) {
+		for _, w := range fileOnly {
+			if mode == audioModeVoice {
+				w.Disable()
+			} else {
+				w.Enable()
+			}
+		}
+	})
+	modeRadio.Horizontal = true
+	modeRadio.Required = true
+	modeRadio.SetSelected(audioModeFile)
+
+	ipfsOpts := newIPFSOptions()
+
+	eventOutput := widget.NewMultiLineEntry()
+	eventOutput.SetPlaceHolder("O evento Nostr gerado aparecerá aqui...")
+	eventOutput.Disable()
+
+	// loadAudio lê a duração, as tags e a forma de onda do arquivo e o envia
+	// aos servidores Blossom
+	loadAudio := func(path string) {
+		mimeType, err := sniff.File(path)
+		if err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		if !strings.HasPrefix(mimeType, "audio/") && mimeType != "video/webm" {
+			dialog.ShowInformation("Atenção", "Por favor, selecione um arquivo de áudio.", win)
+			return
+		}
+
+		// As tags do próprio arquivo pré-preenchem os campos ainda vazios
+		if md, err := extract.Extract(path, mimeType); err != nil {
+			log.Println("Erro ao extrair metadados:", err)
+		} else {
+			fillIfEmpty(titleEntry, md.Title)
+			fillIfEmpty(summaryEntry, md.Summary)
+			fillIfEmpty(altEntry, md.Alt)
+			fillIfEmpty(hashtagsEntry, strings.Join(md.Tags, ", "))
+		}
+
+		loaded := &audioFile{path: path, mimeType: mimeType}
+		var pinErr error
+		withCID, apiURL := ipfsOpts.enabled.Checked, ipfsOpts.pinAPI(win)
+		runCancellable(win, "Processando Áudio", "Lendo a duração, calculando a forma de onda e enviando para os servidores Blossom...",
+			func(ctx context.Context) error {
+				var err error
+				if loaded.info, err = audio.Probe(ctx, path); err != nil {
+					log.Println("Erro ao ler a duração do áudio:", err)
+				}
+				// A duração das amostras decodificadas prevalece sobre a do cabeçalho
+				env, err := audio.Analyze(ctx, path)
+				if err != nil {
+					if ctx.Err() != nil {
+						return ctx.Err()
+					}
+					log.Println("Forma de onda indisponível:", err)
+				} else {
+					loaded.waveform = env.Waveform(nipa0.WaveformPoints)
+					if d := env.Duration(); d > 0 {
+						loaded.info.Duration = d
+					}
+				}
+				if loaded.upload, err = uploadLocalFile(path, mimeType); err != nil || !withCID {
+					return err
+				}
+				if loaded.cid, pinErr, err = fileIPFS(ctx, path, loaded.upload, apiURL); err != nil {
+					return fmt.Errorf("Erro ao calcular o CID IPFS: %w", err)
+				}
+				return nil
+			},
+			func(err error) {
+				if err != nil {
+					dialog.ShowError(err, win)
+					return
+				}
+				current = loaded
+				if pinErr != nil {
+					dialog.ShowError(fmt.Errorf("Erro ao fixar o arquivo no IPFS: %w", pinErr), win)
+				}
+
+				info := fmt.Sprintf("Tamanho: %d bytes | MIME: %s\n", loaded.upload.PreEvent.Size, mimeType)
+				if s := loaded.info.String(); s != "" {
+					info += s + "\n"
+				}
+				for _, r := range loaded.upload.Responses {
+					info += fmt.Sprintf("URL: %s\n", r.URL)
+				}
+				if loaded.cid != "" {
+					info += "CID IPFS: " + loaded.cid + "\n"
+				}
+				if len(loaded.waveform) == 0 {
+					info += "Forma de onda indisponível: instale o ffmpeg para formatos além de WAV.\n"
+				}
+				if len(loaded.upload.Removed) > 0 {
+					info += removedMetadataText(loaded.upload.Removed)
+				}
+				fileInfoLabel.SetText(info)
+				waveformLabel.SetText(waveformPreview(loaded.waveform))
+
+				if modeRadio.Selected == audioModeVoice && loaded.info.Duration > nipa0.MaxDuration {
+					dialog.ShowInformation("Atenção", fmt.Sprintf("O áudio tem %.0f segundos. A NIP-A0 recomenda mensagens de voz de até %d segundos.",
+						loaded.info.Duration, nipa0.MaxDuration), win)
+				}
+			})
+	}
+
+	selectAudioButton := widget.NewButton("Selecionar Áudio", func() {
+		fd := dialog.NewFileOpen(func(file fyne.URIReadCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, win)
+				return
+			}
+			if file == nil {
+				return
+			}
+			file.Close()
+			loadAudio(file.URI().Path())
+		}, win)
+		fd.SetFilter(storage.NewExtensionFileFilter(audioExtensions))
+		fd.Show()
+	})
+
+	publish := func(evt nostr.Event) {
+		evt.CreatedAt = nostr.Now()
+		evt.PubKey = App.Npub
+		if err := evt.Sign(App.Nsec); err != nil {
+			dialog.ShowError(fmt.Errorf("Erro ao assinar o evento: %w", err), win)
+			return
+		}
+		eventOutput.SetText(fmt.Sprintf("ID: %s\nKind: %d", evt.ID, evt.Kind))
+		eventOutput.Enable()
+
+		showPublishDialog(win, evt)
+	}
+
+	publishButton := widget.NewButton("Gerar e Publicar", func() {
+		if current == nil {
+			dialog.ShowInformation("Atenção", "Por favor, selecione um arquivo de áudio primeiro.", win)
+			return
+		}
+		if App.Nsec == "" {
+			dialog.ShowInformation("Atenção", "Por favor, configure sua chave NSEC.", win)
+			return
+		}
+		hashtags := announce.Hashtags(hashtagsEntry.Text)
+		uploaded := current.upload
+		ids, ipfsFallbacks := ipfsOpts.refs(current.cid)
+		fallbacks := append(current.fallbacks(), ipfsFallbacks...)
+
+		if modeRadio.Selected == audioModeVoice {
+			publish(nipa0.Event(nipa0.VoiceMessage{
+				URL:         uploaded.Responses[0].URL,
+				MimeType:    current.mimeType,
+				Sha256:      uploaded.PreEvent.Sha256,
+				Size:        uploaded.PreEvent.Size,
+				Duration:    current.info.Duration,
+				Waveform:    current.waveform,
+				Fallbacks:   fallbacks,
+				Identifiers: ids,
+			}, hashtags))
+			return
+		}
+
+		t := nostr.Tags{
+			nostr.Tag{"url", uploaded.Responses[0].URL},
+			nostr.Tag{"m", current.mimeType},
+			nostr.Tag{"x", uploaded.PreEvent.Sha256},
+			nostr.Tag{"size", fmt.Sprintf("%d", uploaded.PreEvent.Size)},
+		}
+		if uploaded.OriginalSha256 != "" {
+			t = append(t, nostr.Tag{"ox", uploaded.OriginalSha256})
+		}
+		if current.info.Duration > 0 {
+			t = append(t, nostr.Tag{"duration", nip71.FormatDuration(current.info.Duration)})
+		}
+		if title := strings.TrimSpace(titleEntry.Text); title != "" {
+			t = append(t, nostr.Tag{"title", title})
+		}
+		if summary := strings.TrimSpace(summaryEntry.Text); summary != "" {
+			t = append(t, nostr.Tag{"summary", summary})
+		}
+		if alt := strings.TrimSpace(altEntry.Text); alt != "" {
+			t = append(t, nostr.Tag{"alt", alt})
+		}
+		if image := coverInput.image; image.URL != "" {
+			t = append(t, image.Tag("image"))
+		}
+		for _, fb := range fallbacks {
+			t = append(t, nostr.Tag{"fallback", fb})
+		}
+		for _, id := range ids {
+			t = append(t, nostr.Tag{"i", id})
+		}
+		t = append(t, nostr.Tag{"service", "blossom"})
+		for _, h := range hashtags {
+			t = append(t, nostr.Tag{"t", strings.ToLower(h)})
+		}
+		publish(nostr.Event{
+			Kind:    nostr.KindFileMetadata,
+			Content: descriptionEntry.Text,
+			Tags:    t,
+		})
+	})
+
+	resetFormButton := widget.NewButton("Limpar Formulário", func() {
+		current = nil
+		for _, entry := range []*widget.Entry{titleEntry, summaryEntry, altEntry, hashtagsEntry, descriptionEntry} {
+			entry.SetText("")
+		}
+		coverInput.Reset()
+		fileInfoLabel.SetText("Arquivo: (selecione um áudio)")
+		waveformLabel.SetText("")
+		eventOutput.SetText("")
+		eventOutput.Disable()
+	})
+
+	form := &widget.Form{
+		Items: []*widget.FormItem{
+			{Text: "Publicar como", Widget: modeRadio},
+			{Text: "Título", Widget: titleEntry},
+			{Text: "Resumo", Widget: summaryEntry},
+			{Text: "Texto Alternativo", Widget: altEntry},
+			{Text: "Capa", Widget: coverInput.widget},
+			{Text: "Hashtags", Widget: hashtagsEntry},
+			{Text: "IPFS", Widget: ipfsOpts.widget()},
+		},
+	}
+	inputContainer := container.NewVBox(
+		selectAudioButton,
+		fileInfoLabel,
+		waveformLabel,
+		form,
+		widget.NewLabel("Descrição"),
+		descriptionEntry,
+	)
+	actionsContainer := container.NewVBox(
+		container.NewCenter(container.NewHBox(publishButton, resetFormButton)),
+		widget.NewLabelWithStyle("Evento Gerado", fyne.TextAlignCenter, fyne.TextStyle{Bold: true}),
+		eventOutput,
+	)
+
+	return container.NewBorder(nil, actionsContainer, nil, nil, container.NewScroll(inputContainer))
+}
@@ -0,0 +1,65 @@
+package blossom
+
+import (
+	"NostrFilePublisher/model"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// ParseBlobURL extrai de uma URL Blossom (https://servidor/<sha256>[.ext]) o
+// endereço do servidor e o hash do arquivo. ok é false se o último segmento
+// do caminho não for um hash SHA-256.
+func ParseBlobURL(blobURL string) (server, sha256 string, ok bool) {
+	parsed, err := url.Parse(blobURL)
+	if err != nil || parsed.Host == "" {
+		return "", "", false
+	}
+	name := path.Base(parsed.Path)
+	if ext := path.Ext(name); ext != "" {
+		name = strings.TrimSuffix(name, ext)
+	}
+	if _, err := hex.DecodeString(name); err != nil || len(name) != 64 {
+		return "", "", false
+	}
+	return parsed.Scheme + "://" + parsed.Host, strings.ToLower(name), true
+}
+
+// Delete apaga um arquivo de um servidor Blossom (BUD-02, DELETE /<sha256>),
+// autorizado por um evento com a ação "delete". Um arquivo que o servidor já
+// não possui (404) não é considerado erro.
+func Delete(httpClient *http.Client, server, sha256 string, appState model.AppState) error {
+	authHex, err := signAuth("delete", sha256, fmt.Sprintf("Delete %s", sha256), appState)
+	if err != nil {
+		return fmt.Errorf("error signing event: %w", err)
+	}
+
+	parsedURL, err := url.Parse(server)
+	if err != nil {
+		return fmt.Errorf("invalid URL %s: %w", server, err)
+	}
+	parsedURL.Path = "/" + sha256
+
+	req, err := http.NewRequest(http.MethodDelete, parsedURL.String(), nil)
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Nostr %s", authHex))
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error deleting from %s: %w", parsedURL.String(), err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusAccepted, http.StatusNotFound:
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("delete failed (%d): %s", resp.StatusCode, strings.TrimSpace(string(body)))
+}
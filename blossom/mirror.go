@@ -0,0 +1,86 @@
+package blossom
+
+import (
+	"NostrFilePublisher/model"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// MirrorTo pede aos servidores Blossom informados que copiem o arquivo
+// hospedado em sourceURL (BUD-04, PUT /mirror), sem que ele precise ser enviado
+// pelo aplicativo. preEvt.Sha256 deve ser o hash do arquivo: ele vai na
+// autorização e o servidor só aceita a cópia se o conteúdo corresponder.
+// Falhas em um servidor são devolvidas como *UploadError.
+func MirrorTo(httpClient *http.Client, sourceURL string, preEvt model.PreEvent, appState model.AppState, servers []string) ([]model.BlossomResponse, []error) {
+	var (
+		errs      []error
+		responses []model.BlossomResponse
+	)
+	if len(servers) == 0 {
+		return nil, []error{fmt.Errorf("no Blossom servers configured")}
+	}
+
+	authHex, err := buildAuthHeader(preEvt, appState, path.Base(sourceURL))
+	if err != nil {
+		return nil, []error{fmt.Errorf("error signing event: %w", err)}
+	}
+
+	for _, bURL := range servers {
+		resp, err := mirrorFile(httpClient, bURL, sourceURL, preEvt, authHex)
+		if err != nil {
+			errs = append(errs, &UploadError{Server: bURL, Err: err})
+			continue
+		}
+		responses = append(responses, *resp)
+	}
+
+	return responses, errs
+}
+
+// mirrorFile pede a um único servidor Blossom que copie o arquivo de sourceURL.
+func mirrorFile(httpClient *http.Client, blossomURL, sourceURL string, preEvt model.PreEvent, authHex string) (*model.BlossomResponse, error) {
+	parsedURL, err := url.Parse(blossomURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL %s: %w", blossomURL, err)
+	}
+	parsedURL.Path = "/mirror"
+
+	body, _ := json.Marshal(map[string]string{"url": sourceURL})
+	req, err := http.NewRequest(http.MethodPut, parsedURL.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Nostr %s", authHex))
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error mirroring to %s: %w", parsedURL.String(), err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("mirror failed (%d): %s", resp.StatusCode, string(respBody))
+	}
+
+	var blossomResp model.BlossomResponse
+	if err := json.Unmarshal(respBody, &blossomResp); err != nil {
+		return nil, fmt.Errorf("error unmarshalling response: %w", err)
+	}
+	if !strings.EqualFold(blossomResp.Sha256, preEvt.Sha256) {
+		return nil, fmt.Errorf("mirrored blob has sha256 %s, expected %s", blossomResp.Sha256, preEvt.Sha256)
+	}
+
+	return &blossomResp, nil
+}
@@ -10,16 +10,49 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"strings"
 	"time"
 )
 
+// UploadError identifica o servidor Blossom em que um upload falhou, permitindo
+// que o envio seja repetido apenas para ele.
+type UploadError struct {
+	Server string
+	Err    error
+}
+
+func (e *UploadError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Server, e.Err)
+}
+
+func (e *UploadError) Unwrap() error {
+	return e.Err
+}
+
+// BlobURL devolve a URL em que um servidor Blossom serve o arquivo com o hash
+// informado (BUD-01, GET /<sha256>). Ela é usada nos eventos preparados
+// enquanto o envio ao servidor ainda está na fila de saída.
+func BlobURL(server, sha256 string) string {
+	return strings.TrimSuffix(server, "/") + "/" + sha256
+}
+
 // SendFile envia um arquivo para múltiplos endpoints Blossom e retorna as respostas e erros.
 func SendFile(httpClient *http.Client, preEvt model.PreEvent, appState model.AppState) ([]model.BlossomResponse, []error) {
+	servers := make([]string, 0, len(appState.BlossomServers))
+	for _, bURL := range appState.BlossomServers {
+		servers = append(servers, bURL)
+	}
+	return SendFileTo(httpClient, preEvt, appState, servers)
+}
+
+// SendFileTo envia um arquivo apenas para os servidores Blossom informados.
+// Falhas de envio em um servidor são devolvidas como *UploadError.
+func SendFileTo(httpClient *http.Client, preEvt model.PreEvent, appState model.AppState, servers []string) ([]model.BlossomResponse, []error) {
 	var (
 		errs      []error
 		responses []model.BlossomResponse
 	)
-	if len(appState.BlossomServers) == 0 {
+	if len(servers) == 0 {
 		return nil, []error{fmt.Errorf("no Blossom servers configured")}
 	}
 
@@ -34,10 +67,10 @@ func SendFile(httpClient *http.Client, preEvt model.PreEvent, appState model.App
 		return nil, []error{fmt.Errorf("error signing event: %w", err)}
 	}
 
-	for _, bURL := range appState.BlossomServers {
+	for _, bURL := range servers {
 		resp, err := uploadFile(httpClient, bURL, file, preEvt, authHex)
 		if err != nil {
-			errs = append(errs, err)
+			errs = append(errs, &UploadError{Server: bURL, Err: err})
 			continue
 		}
 		responses = append(responses, *resp)
@@ -48,16 +81,22 @@ func SendFile(httpClient *http.Client, preEvt model.PreEvent, appState model.App
 
 // buildAuthHeader cria e assina o evento Nostr para autenticação.
 func buildAuthHeader(preEvt model.PreEvent, appState model.AppState, fileName string) (string, error) {
+	return signAuth("upload", preEvt.Sha256, fmt.Sprintf("Upload %s", fileName), appState)
+}
+
+// signAuth assina o evento de autorização Blossom (kind 24242) para a ação
+// informada ("upload", "delete"...) e devolve-o codificado para o cabeçalho.
+func signAuth(action, sha256, content string, appState model.AppState) (string, error) {
 	tags := nostr.Tags{
-		{"t", "upload"},
-		{"x", preEvt.Sha256},
+		{"t", action},
+		{"x", sha256},
 		{"expiration", fmt.Sprintf("%d", time.Now().Add(10*time.Minute).Unix())},
 	}
 
 	evt := &nostr.Event{
 		CreatedAt: nostr.Now(),
 		Tags:      tags,
-		Content:   fmt.Sprintf("Upload %s", fileName),
+		Content:   content,
 		Kind:      nostr.KindBlobs,
 		PubKey:    appState.Npub,
 	}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"NostrFilePublisher/blossom"
+	"NostrFilePublisher/history"
+	"NostrFilePublisher/nip09"
+	"NostrFilePublisher/nip71"
+	"NostrFilePublisher/relay"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// blobRef identifica um arquivo em um servidor Blossom.
+type blobRef struct {
+	Server, Sha256 string
+}
+
+// eventBlobs devolve os arquivos Blossom citados nas tags url e fallback do
+// evento, inclusive as das tags imeta dos vídeos. URLs que não seguem o
+// formato Blossom (/<sha256>) são ignoradas.
+func eventBlobs(evt nostr.Event) []blobRef {
+	var urls []string
+	for _, tag := range evt.Tags {
+		if len(tag) < 2 {
+			continue
+		}
+		switch tag[0] {
+		case "url", "fallback":
+			urls = append(urls, tag[1])
+		case "imeta":
+			if v, ok := nip71.ParseIMeta(tag); ok {
+				urls = append(urls, v.URL)
+				urls = append(urls, v.Fallbacks...)
+			}
+		}
+	}
+
+	var blobs []blobRef
+	for _, u := range urls {
+		server, sha, ok := blossom.ParseBlobURL(u)
+		if !ok {
+			continue
+		}
+		if ref := (blobRef{server, sha}); !slices.Contains(blobs, ref) {
+			blobs = append(blobs, ref)
+		}
+	}
+	return blobs
+}
+
+// showDeleteDialog confirma a exclusão de uma publicação: um pedido de
+// exclusão (NIP-09) é enviado aos relays de escrita e aos que aceitaram o
+// evento e, opcionalmente, os arquivos são apagados dos servidores Blossom.
+func showDeleteDialog(win fyne.Window, entry history.Entry) {
+	App.Mutex.Lock()
+	pubkey := App.Npub
+	relays := App.WriteRelays()
+	App.Mutex.Unlock()
+	if pubkey == "" {
+		dialog.ShowInformation("Atenção", "Por favor, configure sua chave NSEC na aba de Configurações.", win)
+		return
+	}
+	if entry.Event.PubKey != pubkey {
+		dialog.ShowInformation("Atenção", "Este evento foi publicado com outra chave e não pode ser excluído com a atual.", win)
+		return
+	}
+	if entry.Event.Kind == nip09.KindDeletion {
+		dialog.ShowInformation("Atenção", "Pedidos de exclusão não podem ser excluídos.", win)
+		return
+	}
+	for _, url := range entry.AcceptedRelays() {
+		if !slices.Contains(relays, url) {
+			relays = append(relays, url)
+		}
+	}
+	if len(relays) == 0 {
+		dialog.ShowInformation("Atenção", "Nenhum relay de escrita configurado. Por favor, adicione um relay na aba Configurações.", win)
+		return
+	}
+
+	reasonEntry := widget.NewEntry()
+	reasonEntry.SetPlaceHolder("Motivo (opcional)")
+
+	blobs := eventBlobs(entry.Event)
+	blobLines := make([]string, len(blobs))
+	for i, b := range blobs {
+		blobLines[i] = fmt.Sprintf("%s/%s", b.Server, b.Sha256)
+	}
+	blobsCheck := widget.NewCheck("Apagar também os arquivos dos servidores Blossom", nil)
+	blobsLabel := widget.NewLabel(strings.Join(blobLines, "\n"))
+	blobsLabel.Wrapping = fyne.TextWrapBreak
+	if len(blobs) == 0 {
+		blobsCheck.Disable()
+		blobsLabel.SetText("O evento não cita arquivos hospedados em servidores Blossom.")
+	}
+
+	message := fmt.Sprintf("Enviar um pedido de exclusão de \"%s\" (kind %d) para %d relay(s)?", entry.Title(), entry.Event.Kind, len(relays))
+	if coordinate := nip09.Coordinate(entry.Event); coordinate != "" {
+		message += "\nTodas as versões do endereço " + coordinate + " serão excluídas."
+	}
+	info := widget.NewLabel(message)
+	info.Wrapping = fyne.TextWrapWord
+	content := container.NewVBox(
+		info,
+		widget.NewLabel("Os relays e clientes podem ignorar o pedido; cópias já baixadas não são apagadas."),
+		reasonEntry,
+		blobsCheck,
+		blobsLabel,
+	)
+	d := dialog.NewCustomConfirm("Excluir Publicação", "Excluir", "Cancelar", content, func(ok bool) {
+		if !ok {
+			return
+		}
+		var toDelete []blobRef
+		if blobsCheck.Checked {
+			toDelete = blobs
+		}
+		runDeletion(win, entry.Event, strings.TrimSpace(reasonEntry.Text), relays, toDelete)
+	}, win)
+	d.Resize(fyne.NewSize(600, 0))
+	d.Show()
+}
+
+// runDeletion assina e publica o pedido de exclusão, apaga os arquivos
+// informados e exibe o resultado de cada relay e servidor.
+func runDeletion(win fyne.Window, target nostr.Event, reason string, relays []string, blobs []blobRef) {
+	evt := nip09.Request(target, reason)
+	if err := evt.Sign(App.Nsec); err != nil {
+		dialog.ShowError(fmt.Errorf("Erro ao assinar o pedido de exclusão: %w", err), win)
+		return
+	}
+
+	logOutput := widget.NewMultiLineEntry()
+	logOutput.Wrapping = fyne.TextWrapWord
+	statusLabel := widget.NewLabel("Enviando o pedido de exclusão...")
+	d := dialog.NewCustom("Exclusão", "Fechar", container.NewBorder(statusLabel, nil, nil, nil, logOutput), win)
+	d.Resize(fyne.NewSize(600, 400))
+	d.Show()
+
+	appendLog := func(line string) {
+		fyne.Do(func() { logOutput.SetText(logOutput.Text + line + "\n") })
+	}
+
+	go func() {
+		log.Println("Pedido de exclusão:", evt.String())
+		results, _ := relay.Publish(evt, relays, 0, nil)
+		accepted := 0
+		for _, r := range results {
+			appendLog(fmt.Sprintf("Relay %s: %s", r.URL, r.Status()))
+			if r.Err == nil {
+				accepted++
+				if err := History.RecordAccepted(evt, r.URL); err != nil {
+					log.Println("Erro ao registrar publicação no histórico:", err)
+				}
+			}
+		}
+
+		App.Mutex.Lock()
+		state := *App
+		App.Mutex.Unlock()
+		deleted := 0
+		for _, b := range blobs {
+			if err := blossom.Delete(state.HttpClient, b.Server, b.Sha256, state); err != nil {
+				appendLog(fmt.Sprintf("Servidor %s: Falha ao apagar %s: %v", b.Server, b.Sha256, err))
+				continue
+			}
+			deleted++
+			appendLog(fmt.Sprintf("Servidor %s: %s apagado", b.Server, b.Sha256))
+		}
+
+		fyne.Do(func() {
+			status := fmt.Sprintf("Pedido de exclusão aceito por %d de %d relay(s).", accepted, len(relays))
+			if len(blobs) > 0 {
+				status += fmt.Sprintf(" Arquivos apagados: %d de %d.", deleted, len(blobs))
+			}
+			statusLabel.SetText(status)
+		})
+	}()
+}
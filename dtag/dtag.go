@@ -0,0 +1,104 @@
+package dtag
+
+import (
+	"crypto/rand"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Estratégias de geração do identificador "d" de eventos endereçáveis. Um
+// evento com o mesmo kind, autor e "d" substitui o anterior nos relays.
+const (
+	// Timestamp usa o identificador da instalação e o horário da publicação;
+	// cada publicação cria um endereço novo.
+	Timestamp = "timestamp"
+
+	// Slug deriva o identificador do título. Publicar outro vídeo com o mesmo
+	// título substitui o anterior.
+	Slug = "slug"
+
+	// Hash usa o SHA-256 do arquivo principal, de modo que o mesmo arquivo
+	// sempre tenha o mesmo endereço.
+	Hash = "hash"
+
+	// UUID gera um identificador aleatório (UUID v4).
+	UUID = "uuid"
+)
+
+// Strategies lista as estratégias disponíveis, na ordem de exibição.
+var Strategies = []string{Timestamp, Slug, Hash, UUID}
+
+// maxSlugLength limita o tamanho do identificador gerado a partir do título.
+const maxSlugLength = 64
+
+// Input reúne os dados do evento usados pelas estratégias.
+type Input struct {
+	// Prefix identifica a instalação (usado por Timestamp).
+	Prefix string
+	Title  string
+	Sha256 string
+	Now    time.Time
+}
+
+// Generate gera o identificador "d" com a estratégia informada. Um erro é
+// devolvido se faltar o dado de que a estratégia precisa.
+func Generate(strategy string, in Input) (string, error) {
+	switch strategy {
+	case Timestamp, "":
+		return fmt.Sprintf("%s.%d", in.Prefix, in.Now.Unix()), nil
+	case Slug:
+		slug := Slugify(in.Title)
+		if slug == "" {
+			return "", fmt.Errorf("slug strategy requires a title")
+		}
+		return slug, nil
+	case Hash:
+		if in.Sha256 == "" {
+			return "", fmt.Errorf("hash strategy requires the file sha256")
+		}
+		return strings.ToLower(in.Sha256), nil
+	case UUID:
+		return newUUID()
+	default:
+		return "", fmt.Errorf("unknown d tag strategy %q", strategy)
+	}
+}
+
+// Slugify converte um título em um identificador legível: letras sem acento
+// em minúsculas e dígitos, separados por hífens.
+func Slugify(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range norm.NFD.String(s) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// Remove os acentos decompostos pela normalização
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			dash = false
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			dash = true
+		}
+		if b.Len() >= maxSlugLength {
+			break
+		}
+	}
+	return strings.TrimRight(b.String()[:min(b.Len(), maxSlugLength)], "-")
+}
+
+func newUUID() (string, error) {
+	var u [16]byte
+	if _, err := rand.Read(u[:]); err != nil {
+		return "", err
+	}
+	u[6] = u[6]&0x0f | 0x40 // versão 4
+	u[8] = u[8]&0x3f | 0x80 // variante RFC 4122
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:]), nil
+}
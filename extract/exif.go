@@ -0,0 +1,137 @@
+package extract
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+)
+
+func init() {
+	// Só o EXIF de JPEG é lido; PNG, WebP e HEIC guardam-no em outros contêineres.
+	Register("image/jpeg", extractEXIF)
+}
+
+// Tags EXIF do IFD0 lidas pelo extrator.
+const (
+	tagImageDescription = 0x010E
+	tagXPTitle          = 0x9C9B
+	tagXPComment        = 0x9C9C
+	tagXPKeywords       = 0x9C9E
+	tagXPSubject        = 0x9C9F
+)
+
+// maxJPEGHeader limita a leitura dos segmentos antes dos dados da imagem.
+const maxJPEGHeader = 1 << 20
+
+// extractEXIF lê o IFD0 do EXIF de um JPEG: título e assunto do Windows
+// (XPTitle, XPSubject) e as palavras-chave como tags. Sem assunto, o resumo
+// vem do comentário ou da descrição da imagem.
+func extractEXIF(r io.ReadSeeker, size int64) (Metadata, error) {
+	tiff, err := findJPEGEXIF(io.LimitReader(r, maxJPEGHeader))
+	if err != nil {
+		return Metadata{}, err
+	}
+	fields, err := readIFD0(tiff)
+	if err != nil {
+		return Metadata{}, err
+	}
+
+	md := Metadata{
+		Title:   fields[tagXPTitle],
+		Summary: fields[tagXPSubject],
+		Tags:    splitKeywords(fields[tagXPKeywords]),
+	}
+	if md.Summary == "" {
+		md.Summary = fields[tagXPComment]
+	}
+	if md.Summary == "" {
+		md.Summary = fields[tagImageDescription]
+	}
+	return md, nil
+}
+
+// findJPEGEXIF devolve o bloco TIFF do segmento APP1 "Exif".
+func findJPEGEXIF(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(data, []byte{0xFF, 0xD8}) {
+		return nil, errors.New("EXIF not found")
+	}
+	pos := 2
+	for pos+4 <= len(data) && data[pos] == 0xFF {
+		marker := data[pos+1]
+		if marker == 0xDA || marker == 0xD9 {
+			break
+		}
+		length := int(binary.BigEndian.Uint16(data[pos+2 : pos+4]))
+		end := pos + 2 + length
+		if length < 2 || end > len(data) {
+			break
+		}
+		body := data[pos+4 : end]
+		if marker == 0xE1 && bytes.HasPrefix(body, []byte("Exif\x00\x00")) {
+			return body[6:], nil
+		}
+		pos = end
+	}
+	return nil, errors.New("EXIF not found")
+}
+
+// readIFD0 lê as tags de texto (ASCII) e as tags XP* (UTF-16LE) do IFD0.
+func readIFD0(tiff []byte) (map[uint16]string, error) {
+	if len(tiff) < 8 {
+		return nil, errors.New("truncated EXIF")
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return nil, errors.New("invalid EXIF byte order")
+	}
+
+	ifd := int(order.Uint32(tiff[4:8]))
+	if ifd+2 > len(tiff) {
+		return nil, errors.New("invalid EXIF IFD offset")
+	}
+	fields := map[uint16]string{}
+	count := int(order.Uint16(tiff[ifd : ifd+2]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			break
+		}
+		tag := order.Uint16(tiff[entry : entry+2])
+		typ := order.Uint16(tiff[entry+2 : entry+4])
+		n := int(order.Uint32(tiff[entry+4 : entry+8]))
+		// Tipo 2 é ASCII; as tags XP* usam o tipo 1 (BYTE) com texto UTF-16LE.
+		if (typ != 2 && typ != 1) || n <= 0 || n > len(tiff) {
+			continue
+		}
+		value := tiff[entry+8 : entry+12]
+		if n > 4 {
+			off := int(order.Uint32(value))
+			if off+n > len(tiff) {
+				continue
+			}
+			value = tiff[off : off+n]
+		} else {
+			value = value[:n]
+		}
+
+		var text string
+		if typ == 1 {
+			text = decodeUTF16(value, binary.LittleEndian)
+		} else {
+			text = string(value)
+		}
+		fields[tag] = strings.TrimSpace(strings.TrimRight(text, "\x00"))
+	}
+	return fields, nil
+}
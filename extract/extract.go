@@ -0,0 +1,143 @@
+package extract
+
+import (
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Metadata são os dados sugeridos por um extrator para preencher o formulário.
+type Metadata struct {
+	Title   string
+	Summary string
+	Alt     string
+	Tags    []string
+}
+
+// Empty informa se nenhum campo foi preenchido.
+func (m Metadata) Empty() bool {
+	return m.Title == "" && m.Summary == "" && m.Alt == "" && len(m.Tags) == 0
+}
+
+// merge completa os campos vazios de m com os de other e acrescenta as tags
+// que ainda não existem.
+func (m *Metadata) merge(other Metadata) {
+	if m.Title == "" {
+		m.Title = strings.TrimSpace(other.Title)
+	}
+	if m.Summary == "" {
+		m.Summary = strings.TrimSpace(other.Summary)
+	}
+	if m.Alt == "" {
+		m.Alt = strings.TrimSpace(other.Alt)
+	}
+	for _, tag := range other.Tags {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && !containsFold(m.Tags, tag) {
+			m.Tags = append(m.Tags, tag)
+		}
+	}
+}
+
+// Extractor lê metadados de um arquivo. r está posicionado no início do arquivo.
+type Extractor func(r io.ReadSeeker, size int64) (Metadata, error)
+
+var (
+	mu         sync.RWMutex
+	extractors = map[string][]Extractor{}
+)
+
+// Register associa um extrator a um tipo MIME. O padrão pode ser um tipo exato
+// ("audio/mpeg") ou um curinga para a família inteira ("image/*"). Os
+// extratores embutidos se registram nas funções init deste pacote.
+func Register(pattern string, fn Extractor) {
+	mu.Lock()
+	defer mu.Unlock()
+	pattern = strings.ToLower(pattern)
+	extractors[pattern] = append(extractors[pattern], fn)
+}
+
+// Patterns devolve os padrões MIME com extratores registrados, em ordem alfabética.
+func Patterns() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	patterns := make([]string, 0, len(extractors))
+	for p := range extractors {
+		patterns = append(patterns, p)
+	}
+	sort.Strings(patterns)
+	return patterns
+}
+
+// lookup devolve os extratores do tipo exato seguidos dos do curinga da família.
+func lookup(mimeType string) []Extractor {
+	mimeType = strings.ToLower(strings.TrimSpace(mimeType))
+	if i := strings.IndexByte(mimeType, ';'); i >= 0 {
+		mimeType = strings.TrimSpace(mimeType[:i])
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	found := append([]Extractor{}, extractors[mimeType]...)
+	if family, _, ok := strings.Cut(mimeType, "/"); ok {
+		found = append(found, extractors[family+"/*"]...)
+	}
+	return found
+}
+
+// Extract executa todos os extratores registrados para o tipo MIME e combina
+// os resultados: o primeiro extrator a preencher um campo prevalece. Falhas de
+// um extrator não impedem os demais; o erro só é devolvido se nenhum produzir
+// dados.
+func Extract(path, mimeType string) (Metadata, error) {
+	var result Metadata
+	fns := lookup(mimeType)
+	if len(fns) == 0 {
+		return result, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return result, err
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil {
+		return result, err
+	}
+
+	var firstErr error
+	for _, fn := range fns {
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return result, err
+		}
+		md, err := fn(f, stat.Size())
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		result.merge(md)
+	}
+	if result.Empty() && firstErr != nil {
+		return result, firstErr
+	}
+	return result, nil
+}
+
+// containsFold informa se a lista contém s, ignorando maiúsculas e minúsculas.
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitKeywords separa palavras-chave por vírgula ou ponto e vírgula.
+func splitKeywords(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ';' })
+}
@@ -0,0 +1,183 @@
+package extract
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+)
+
+func init() {
+	Register("audio/mpeg", extractID3)
+	Register("audio/mp3", extractID3)
+}
+
+// maxID3Size limita o tamanho da tag ID3v2 lida.
+const maxID3Size = 4 << 20
+
+// extractID3 lê a tag ID3v2 do início do arquivo ou, na falta dela, a ID3v1
+// dos últimos 128 bytes. O título vai para Title, artista e álbum para
+// Summary (ou, na falta deles, o comentário) e o gênero para Tags.
+func extractID3(r io.ReadSeeker, size int64) (Metadata, error) {
+	frames, err := readID3v2(r)
+	if err != nil {
+		frames, err = readID3v1(r, size)
+		if err != nil {
+			return Metadata{}, err
+		}
+	}
+
+	md := Metadata{Title: frames["TIT2"]}
+	var parts []string
+	for _, id := range []string{"TPE1", "TALB", "TYER"} {
+		if frames[id] != "" {
+			parts = append(parts, frames[id])
+		}
+	}
+	md.Summary = strings.Join(parts, " - ")
+	// O comentário não descreve o conteúdo para acessibilidade, então não vai para Alt
+	if md.Summary == "" {
+		md.Summary = frames["COMM"]
+	}
+	if genre := frames["TCON"]; genre != "" {
+		md.Tags = append(md.Tags, cleanGenre(genre))
+	}
+	return md, nil
+}
+
+// readID3v2 lê os quadros de texto (T***) e o comentário (COMM) da tag ID3v2.3/2.4.
+func readID3v2(r io.Reader) (map[string]string, error) {
+	header := make([]byte, 10)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	if string(header[:3]) != "ID3" {
+		return nil, errors.New("ID3v2 tag not found")
+	}
+	version := header[3]
+	if version < 3 {
+		return nil, fmt.Errorf("unsupported ID3v2.%d tag", version)
+	}
+	tagSize := syncsafe(header[6:10])
+	if tagSize > maxID3Size {
+		return nil, fmt.Errorf("ID3v2 tag too large")
+	}
+	data := make([]byte, tagSize)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+
+	frames := map[string]string{}
+	for len(data) >= 10 && data[0] != 0 {
+		id := string(data[:4])
+		var frameSize int
+		if version >= 4 {
+			frameSize = syncsafe(data[4:8])
+		} else {
+			frameSize = int(binary.BigEndian.Uint32(data[4:8]))
+		}
+		if frameSize <= 0 || 10+frameSize > len(data) {
+			break
+		}
+		body := data[10 : 10+frameSize]
+		data = data[10+frameSize:]
+
+		switch {
+		case id == "COMM" && len(body) > 4:
+			// Codificação (1), idioma (3), descrição terminada em nulo e o texto.
+			enc := body[0]
+			text := decodeID3Text(enc, body[4:])
+			if _, after, ok := strings.Cut(text, "\x00"); ok {
+				text = after
+			}
+			if frames[id] == "" {
+				frames[id] = strings.Trim(text, "\x00 ")
+			}
+		case strings.HasPrefix(id, "T") && id != "TXXX" && len(body) > 1:
+			if frames[id] == "" {
+				frames[id] = strings.Trim(decodeID3Text(body[0], body[1:]), "\x00 ")
+			}
+		}
+	}
+	return frames, nil
+}
+
+// readID3v1 lê a tag ID3v1 dos últimos 128 bytes.
+func readID3v1(r io.ReadSeeker, size int64) (map[string]string, error) {
+	if size < 128 {
+		return nil, errors.New("ID3 tag not found")
+	}
+	if _, err := r.Seek(size-128, io.SeekStart); err != nil {
+		return nil, err
+	}
+	tag := make([]byte, 128)
+	if _, err := io.ReadFull(r, tag); err != nil {
+		return nil, err
+	}
+	if string(tag[:3]) != "TAG" {
+		return nil, errors.New("ID3 tag not found")
+	}
+	field := func(b []byte) string {
+		return strings.TrimSpace(string(bytes.TrimRight(b, "\x00")))
+	}
+	return map[string]string{
+		"TIT2": field(tag[3:33]),
+		"TPE1": field(tag[33:63]),
+		"TALB": field(tag[63:93]),
+		"TYER": field(tag[93:97]),
+		"COMM": field(tag[97:127]),
+	}, nil
+}
+
+// decodeID3Text decodifica um texto ID3 conforme o byte de codificação:
+// 0 ISO-8859-1, 1 UTF-16 com BOM, 2 UTF-16BE e 3 UTF-8.
+func decodeID3Text(enc byte, b []byte) string {
+	switch enc {
+	case 0:
+		runes := make([]rune, len(b))
+		for i, c := range b {
+			runes[i] = rune(c)
+		}
+		return string(runes)
+	case 1, 2:
+		var order binary.ByteOrder = binary.BigEndian
+		if len(b) >= 2 && enc == 1 {
+			switch {
+			case b[0] == 0xFF && b[1] == 0xFE:
+				order, b = binary.LittleEndian, b[2:]
+			case b[0] == 0xFE && b[1] == 0xFF:
+				b = b[2:]
+			}
+		}
+		return decodeUTF16(b, order)
+	default:
+		return string(b)
+	}
+}
+
+// decodeUTF16 decodifica texto UTF-16 na ordem de bytes informada.
+func decodeUTF16(b []byte, order binary.ByteOrder) string {
+	units := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		units = append(units, order.Uint16(b[i:i+2]))
+	}
+	return string(utf16.Decode(units))
+}
+
+// syncsafe decodifica um inteiro "syncsafe" de 28 bits do ID3v2.
+func syncsafe(b []byte) int {
+	return int(b[0]&0x7F)<<21 | int(b[1]&0x7F)<<14 | int(b[2]&0x7F)<<7 | int(b[3]&0x7F)
+}
+
+// cleanGenre remove a referência numérica de gênero do ID3v1, ex: "(17)Rock".
+func cleanGenre(genre string) string {
+	if strings.HasPrefix(genre, "(") {
+		if i := strings.IndexByte(genre, ')'); i > 0 && i < len(genre)-1 {
+			return genre[i+1:]
+		}
+	}
+	return genre
+}
@@ -0,0 +1,199 @@
+package extract
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	Register("application/pdf", extractPDF)
+}
+
+// pdfWindow é quanto do início e do fim de um PDF grande é lido. O trailer,
+// com a referência ao dicionário Info, fica no fim do arquivo; o próprio
+// dicionário costuma estar em uma das pontas.
+const pdfWindow = 4 << 20
+
+var (
+	pdfInfoRef = regexp.MustCompile(`/Info\s+(\d+)\s+(\d+)\s+R`)
+	pdfKeys    = []string{"Title", "Subject", "Keywords"}
+)
+
+// extractPDF lê o dicionário Info do PDF: Title vai para Title, Subject para
+// Summary e Keywords para Tags. PDFs cujo dicionário está em um fluxo de
+// objetos comprimido não são tratados.
+func extractPDF(r io.ReadSeeker, size int64) (Metadata, error) {
+	data, err := readEnds(r, size, pdfWindow)
+	if err != nil {
+		return Metadata{}, err
+	}
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		return Metadata{}, errors.New("not a PDF file")
+	}
+
+	// O último trailer do arquivo é o mais recente.
+	refs := pdfInfoRef.FindAllSubmatch(data, -1)
+	if len(refs) == 0 {
+		return Metadata{}, errors.New("PDF Info dictionary not found")
+	}
+	ref := refs[len(refs)-1]
+	objHeader := regexp.MustCompile(`(?:^|\s)` + string(ref[1]) + `\s+` + string(ref[2]) + `\s+obj`)
+	loc := objHeader.FindAllIndex(data, -1)
+	if len(loc) == 0 {
+		return Metadata{}, errors.New("PDF Info object not found")
+	}
+	obj := data[loc[len(loc)-1][1]:]
+	if end := bytes.Index(obj, []byte("endobj")); end >= 0 {
+		obj = obj[:end]
+	}
+
+	values := map[string]string{}
+	for _, key := range pdfKeys {
+		if v, ok := pdfValue(obj, key); ok {
+			values[key] = v
+		}
+	}
+	return Metadata{
+		Title:   values["Title"],
+		Summary: values["Subject"],
+		Tags:    splitKeywords(values["Keywords"]),
+	}, nil
+}
+
+// readEnds lê o arquivo inteiro, se couber em duas janelas, ou apenas o
+// início e o fim.
+func readEnds(r io.ReadSeeker, size, window int64) ([]byte, error) {
+	if size <= 2*window {
+		return io.ReadAll(r)
+	}
+	head := make([]byte, window)
+	if _, err := io.ReadFull(r, head); err != nil {
+		return nil, err
+	}
+	if _, err := r.Seek(size-window, io.SeekStart); err != nil {
+		return nil, err
+	}
+	tail := make([]byte, window)
+	if _, err := io.ReadFull(r, tail); err != nil {
+		return nil, err
+	}
+	return append(append(head, '\n'), tail...), nil
+}
+
+// pdfValue lê o valor de uma chave do dicionário, em string literal "(...)"
+// ou hexadecimal "<...>".
+func pdfValue(dict []byte, key string) (string, bool) {
+	i := bytes.Index(dict, []byte("/"+key))
+	if i < 0 {
+		return "", false
+	}
+	rest := bytes.TrimLeft(dict[i+len(key)+1:], " \t\r\n")
+	if len(rest) == 0 {
+		return "", false
+	}
+	switch rest[0] {
+	case '(':
+		return decodePDFText(pdfLiteral(rest[1:])), true
+	case '<':
+		end := bytes.IndexByte(rest, '>')
+		if end < 0 {
+			return "", false
+		}
+		return decodePDFText(pdfHex(rest[1:end])), true
+	}
+	return "", false
+}
+
+// pdfLiteral decodifica uma string literal, tratando parênteses aninhados e escapes.
+func pdfLiteral(b []byte) []byte {
+	var out []byte
+	depth := 0
+	for i := 0; i < len(b); i++ {
+		c := b[i]
+		switch c {
+		case '\\':
+			if i+1 >= len(b) {
+				return out
+			}
+			i++
+			switch e := b[i]; e {
+			case 'n':
+				out = append(out, '\n')
+			case 'r':
+				out = append(out, '\r')
+			case 't':
+				out = append(out, '\t')
+			case 'b':
+				out = append(out, '\b')
+			case 'f':
+				out = append(out, '\f')
+			case '\r', '\n':
+				// Continuação de linha.
+			default:
+				if e >= '0' && e <= '7' {
+					j := i
+					for j < len(b) && j < i+3 && b[j] >= '0' && b[j] <= '7' {
+						j++
+					}
+					v, _ := strconv.ParseUint(string(b[i:j]), 8, 8)
+					out = append(out, byte(v))
+					i = j - 1
+				} else {
+					out = append(out, e)
+				}
+			}
+		case '(':
+			depth++
+			out = append(out, c)
+		case ')':
+			if depth == 0 {
+				return out
+			}
+			depth--
+			out = append(out, c)
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// pdfHex decodifica uma string hexadecimal, ignorando espaços.
+func pdfHex(b []byte) []byte {
+	digits := strings.Map(func(r rune) rune {
+		if strings.ContainsRune("0123456789abcdefABCDEF", r) {
+			return r
+		}
+		return -1
+	}, string(b))
+	if len(digits)%2 != 0 {
+		digits += "0"
+	}
+	out := make([]byte, len(digits)/2)
+	for i := range out {
+		v, _ := strconv.ParseUint(digits[2*i:2*i+2], 16, 8)
+		out[i] = byte(v)
+	}
+	return out
+}
+
+// decodePDFText converte o texto do PDF: UTF-16BE quando há BOM, senão
+// PDFDocEncoding, tratado aqui como Latin-1.
+func decodePDFText(b []byte) string {
+	if len(b) >= 2 && b[0] == 0xFE && b[1] == 0xFF {
+		return decodeUTF16(b[2:], binary.BigEndian)
+	}
+	if bytes.HasPrefix(b, []byte("\xEF\xBB\xBF")) {
+		return string(b[3:])
+	}
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
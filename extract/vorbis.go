@@ -0,0 +1,170 @@
+package extract
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+func init() {
+	Register("audio/flac", extractFLAC)
+	Register("audio/x-flac", extractFLAC)
+	Register("audio/ogg", extractOgg)
+	Register("audio/opus", extractOgg)
+	Register("application/ogg", extractOgg)
+}
+
+// maxCommentSize limita o tamanho do bloco de comentários lido.
+const maxCommentSize = 4 << 20
+
+// extractFLAC percorre os blocos de metadados do FLAC até o VORBIS_COMMENT.
+func extractFLAC(r io.ReadSeeker, size int64) (Metadata, error) {
+	magic := make([]byte, 4)
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return Metadata{}, err
+	}
+	if string(magic) != "fLaC" {
+		return Metadata{}, errors.New("not a FLAC file")
+	}
+	for {
+		header := make([]byte, 4)
+		if _, err := io.ReadFull(r, header); err != nil {
+			return Metadata{}, err
+		}
+		last := header[0]&0x80 != 0
+		blockType := header[0] & 0x7F
+		length := int64(header[1])<<16 | int64(header[2])<<8 | int64(header[3])
+		if blockType == 4 {
+			if length > maxCommentSize {
+				return Metadata{}, errors.New("FLAC comment block too large")
+			}
+			block := make([]byte, length)
+			if _, err := io.ReadFull(r, block); err != nil {
+				return Metadata{}, err
+			}
+			return parseVorbisComments(block)
+		}
+		if last {
+			return Metadata{}, errors.New("FLAC comments not found")
+		}
+		if _, err := r.Seek(length, io.SeekCurrent); err != nil {
+			return Metadata{}, err
+		}
+	}
+}
+
+// extractOgg lê o cabeçalho de comentários de um Ogg Vorbis ou Opus, que
+// começa na segunda página lógica do fluxo.
+func extractOgg(r io.ReadSeeker, size int64) (Metadata, error) {
+	// O cabeçalho de comentários pode ocupar várias páginas; os pacotes são
+	// remontados até o segundo pacote completo.
+	var packets [][]byte
+	var current []byte
+	read := int64(0)
+	for len(packets) < 2 && read < maxCommentSize {
+		header := make([]byte, 27)
+		if _, err := io.ReadFull(r, header); err != nil {
+			return Metadata{}, err
+		}
+		if string(header[:4]) != "OggS" {
+			return Metadata{}, errors.New("invalid Ogg page")
+		}
+		segments := make([]byte, header[26])
+		if _, err := io.ReadFull(r, segments); err != nil {
+			return Metadata{}, err
+		}
+		for _, segLen := range segments {
+			seg := make([]byte, segLen)
+			if _, err := io.ReadFull(r, seg); err != nil {
+				return Metadata{}, err
+			}
+			read += int64(segLen)
+			current = append(current, seg...)
+			// Um segmento menor que 255 bytes termina o pacote.
+			if segLen < 255 {
+				packets = append(packets, current)
+				current = nil
+			}
+		}
+	}
+	if len(packets) < 2 {
+		return Metadata{}, errors.New("Ogg comment header not found")
+	}
+
+	comment := packets[1]
+	switch {
+	case bytes.HasPrefix(comment, []byte("\x03vorbis")):
+		comment = comment[7:]
+	case bytes.HasPrefix(comment, []byte("OpusTags")):
+		comment = comment[8:]
+	default:
+		return Metadata{}, errors.New("unsupported Ogg codec")
+	}
+	return parseVorbisComments(comment)
+}
+
+// parseVorbisComments lê a lista de comentários "CHAVE=valor" do formato Vorbis.
+func parseVorbisComments(b []byte) (Metadata, error) {
+	next := func() (string, error) {
+		if len(b) < 4 {
+			return "", fmt.Errorf("truncated vorbis comment")
+		}
+		n := int(binary.LittleEndian.Uint32(b[:4]))
+		if n < 0 || 4+n > len(b) {
+			return "", fmt.Errorf("truncated vorbis comment")
+		}
+		s := string(b[4 : 4+n])
+		b = b[4+n:]
+		return s, nil
+	}
+
+	if _, err := next(); err != nil { // vendor
+		return Metadata{}, err
+	}
+	if len(b) < 4 {
+		return Metadata{}, fmt.Errorf("truncated vorbis comment")
+	}
+	count := int(binary.LittleEndian.Uint32(b[:4]))
+	b = b[4:]
+
+	fields := map[string]string{}
+	var genres []string
+	for i := 0; i < count; i++ {
+		entry, err := next()
+		if err != nil {
+			break
+		}
+		key, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			continue
+		}
+		key = strings.ToUpper(key)
+		if key == "GENRE" {
+			genres = append(genres, value)
+			continue
+		}
+		if fields[key] == "" {
+			fields[key] = value
+		}
+	}
+
+	md := Metadata{Title: fields["TITLE"], Tags: genres}
+	var parts []string
+	for _, key := range []string{"ARTIST", "ALBUM", "DATE"} {
+		if fields[key] != "" {
+			parts = append(parts, fields[key])
+		}
+	}
+	md.Summary = strings.Join(parts, " - ")
+	// Descrição e comentário não são textos de acessibilidade, então não vão para Alt
+	if md.Summary == "" {
+		md.Summary = fields["DESCRIPTION"]
+	}
+	if md.Summary == "" {
+		md.Summary = fields["COMMENT"]
+	}
+	return md, nil
+}
@@ -0,0 +1,78 @@
+package extract
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+)
+
+func init() {
+	Register("application/zip", extractZip)
+	Register("application/x-zip-compressed", extractZip)
+}
+
+// zipListLimit é a quantidade de entradas citadas no resumo.
+const zipListLimit = 10
+
+// extractZip lista o conteúdo de um arquivo zip: o resumo cita as primeiras
+// entradas, o texto alternativo traz a contagem e o tamanho descompactado, e
+// as extensões mais comuns viram tags. O comentário do zip, se houver, vira o
+// título.
+func extractZip(r io.ReadSeeker, size int64) (Metadata, error) {
+	ra, ok := r.(io.ReaderAt)
+	if !ok {
+		return Metadata{}, fmt.Errorf("zip extractor requires io.ReaderAt")
+	}
+	zr, err := zip.NewReader(ra, size)
+	if err != nil {
+		return Metadata{}, err
+	}
+
+	var names []string
+	var total uint64
+	extCount := map[string]int{}
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		names = append(names, f.Name)
+		total += f.UncompressedSize64
+		if ext := strings.ToLower(strings.TrimPrefix(path.Ext(f.Name), ".")); ext != "" {
+			extCount[ext]++
+		}
+	}
+
+	md := Metadata{Title: strings.TrimSpace(zr.Comment)}
+	if len(names) == 0 {
+		md.Alt = "Arquivo zip vazio"
+		return md, nil
+	}
+	listed := names
+	if len(listed) > zipListLimit {
+		listed = listed[:zipListLimit]
+	}
+	md.Summary = "Conteúdo: " + strings.Join(listed, ", ")
+	if len(names) > len(listed) {
+		md.Summary += fmt.Sprintf(" e mais %d", len(names)-len(listed))
+	}
+	md.Alt = fmt.Sprintf("Arquivo zip com %d arquivos (%d bytes descompactados)", len(names), total)
+
+	exts := make([]string, 0, len(extCount))
+	for ext := range extCount {
+		exts = append(exts, ext)
+	}
+	sort.Slice(exts, func(i, j int) bool {
+		if extCount[exts[i]] != extCount[exts[j]] {
+			return extCount[exts[i]] > extCount[exts[j]]
+		}
+		return exts[i] < exts[j]
+	})
+	if len(exts) > 3 {
+		exts = exts[:3]
+	}
+	md.Tags = exts
+	return md, nil
+}
@@ -0,0 +1,143 @@
+package ffmpeg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// ErrNotFound indica que o ffmpeg ou o ffprobe não estão instalados no PATH.
+var ErrNotFound = errors.New("ffmpeg/ffprobe not found in PATH")
+
+// Available informa se o ffmpeg e o ffprobe estão disponíveis. A integração é
+// opcional: sem eles, as funções deste pacote devolvem ErrNotFound.
+func Available() bool {
+	_, errFFmpeg := exec.LookPath("ffmpeg")
+	_, errFFprobe := exec.LookPath("ffprobe")
+	return errFFmpeg == nil && errFFprobe == nil
+}
+
+// Duration usa o ffprobe para obter a duração, em segundos, de um arquivo ou URL.
+func Duration(ctx context.Context, input string) (float64, error) {
+	if !Available() {
+		return 0, ErrNotFound
+	}
+	out, err := exec.CommandContext(ctx, "ffprobe",
+		"-v", "error",
+		"-show_entries", "format=duration",
+		"-of", "default=noprint_wrappers=1:nokey=1",
+		input,
+	).Output()
+	if err != nil {
+		return 0, fmt.Errorf("ffprobe failed: %w", commandError(err))
+	}
+	duration, err := strconv.ParseFloat(strings.TrimSpace(string(out)), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration from ffprobe: %q", strings.TrimSpace(string(out)))
+	}
+	return duration, nil
+}
+
+// Frame extrai o quadro no instante informado (em segundos) de um arquivo ou URL.
+func Frame(ctx context.Context, input string, at float64) (image.Image, error) {
+	if !Available() {
+		return nil, ErrNotFound
+	}
+	// -ss antes de -i faz a busca pelo índice do contêiner, o que é rápido mesmo em URLs.
+	out, err := exec.CommandContext(ctx, "ffmpeg",
+		"-v", "error",
+		"-ss", strconv.FormatFloat(at, 'f', 3, 64),
+		"-i", input,
+		"-frames:v", "1",
+		"-f", "image2pipe",
+		"-vcodec", "png",
+		"-",
+	).Output()
+	if err != nil {
+		return nil, fmt.Errorf("ffmpeg failed: %w", commandError(err))
+	}
+	img, err := png.Decode(bytes.NewReader(out))
+	if err != nil {
+		return nil, fmt.Errorf("error decoding frame at %.3fs: %w", at, err)
+	}
+	return img, nil
+}
+
+// Frames extrai `count` quadros distribuídos uniformemente ao longo do vídeo,
+// evitando o primeiro e o último instante, que costumam ser telas pretas.
+// Se duration for zero, a duração é obtida pelo ffprobe.
+func Frames(ctx context.Context, input string, duration float64, count int) ([]image.Image, error) {
+	if duration <= 0 {
+		var err error
+		if duration, err = Duration(ctx, input); err != nil {
+			return nil, err
+		}
+	}
+
+	frames := make([]image.Image, 0, count)
+	for i := 0; i < count; i++ {
+		at := (float64(i) + 0.5) * duration / float64(count)
+		img, err := Frame(ctx, input, at)
+		if err != nil {
+			return nil, err
+		}
+		frames = append(frames, img)
+	}
+	return frames, nil
+}
+
+// commandError inclui a saída de erro do processo na mensagem, quando disponível.
+func commandError(err error) error {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	return err
+}
+
+// PCM decodifica o áudio de um arquivo ou URL em amostras mono de 16 bits
+// (little-endian) na taxa informada e as entrega a fn à medida que o ffmpeg
+// as produz, sem manter o áudio inteiro na memória.
+func PCM(ctx context.Context, input string, sampleRate int, fn func(r io.Reader) error) error {
+	if !Available() {
+		return ErrNotFound
+	}
+	cmd := exec.CommandContext(ctx, "ffmpeg",
+		"-v", "error",
+		"-i", input,
+		"-vn",
+		"-ac", "1",
+		"-ar", strconv.Itoa(sampleRate),
+		"-f", "s16le",
+		"-",
+	)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("ffmpeg failed: %w", err)
+	}
+
+	if err := fn(stdout); err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return err
+	}
+	if err := cmd.Wait(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("ffmpeg failed: %w: %s", err, msg)
+		}
+		return fmt.Errorf("ffmpeg failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"NostrFilePublisher/ffmpeg"
+	"context"
+	"fmt"
+	"image"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	// framePickerCount é a quantidade de quadros candidatos extraídos do vídeo.
+	framePickerCount = 6
+
+	// framePickerTimeout limita o tempo total da extração dos quadros.
+	framePickerTimeout = 2 * time.Minute
+)
+
+// showFramePicker extrai quadros candidatos do vídeo (arquivo local ou URL)
+// com o ffmpeg e permite que o usuário escolha um deles. onPicked é chamado
+// com o quadro escolhido.
+func showFramePicker(win fyne.Window, input string, duration float64, onPicked func(image.Image)) {
+	if !ffmpeg.Available() {
+		dialog.ShowInformation("Atenção", "ffmpeg/ffprobe não encontrados no PATH. Instale-os para extrair quadros do vídeo.", win)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), framePickerTimeout)
+	progress := dialog.NewCustom("Extraindo Quadros", "Cancelar",
+		container.NewVBox(widget.NewLabel("Extraindo quadros do vídeo com o ffmpeg..."), widget.NewProgressBarInfinite()), win)
+	progress.SetOnClosed(cancel)
+	progress.Show()
+
+	go func() {
+		frames, err := ffmpeg.Frames(ctx, input, duration, framePickerCount)
+		fyne.Do(func() {
+			if ctx.Err() == context.Canceled {
+				return
+			}
+			progress.Hide()
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("Erro ao extrair quadros: %w", err), win)
+				return
+			}
+
+			var picker dialog.Dialog
+			grid := container.NewGridWithColumns(3)
+			for i, frame := range frames {
+				preview := canvas.NewImageFromImage(frame)
+				preview.FillMode = canvas.ImageFillContain
+				preview.SetMinSize(fyne.NewSize(200, 120))
+				grid.Add(container.NewBorder(nil,
+					widget.NewButton(fmt.Sprintf("Usar quadro %d", i+1), func() {
+						picker.Hide()
+						onPicked(frame)
+					}),
+					nil, nil, preview))
+			}
+			picker = dialog.NewCustom("Escolher Quadro", "Fechar", container.NewScroll(grid), win)
+			picker.Resize(fyne.NewSize(700, 500))
+			picker.Show()
+		})
+	}()
+}
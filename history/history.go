@@ -0,0 +1,249 @@
+package history
+
+import (
+	"NostrFilePublisher/nip09"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// RelayState registra a situação de um evento em um relay específico.
+type RelayState struct {
+	// Accepted indica que o relay respondeu "OK" à publicação.
+	Accepted bool `json:"accepted"`
+
+	// Verified indica que o relay devolveu o evento na última leitura.
+	Verified bool `json:"verified"`
+
+	// CheckedAt é o momento da última verificação por leitura.
+	CheckedAt time.Time `json:"checked_at,omitempty"`
+}
+
+// Entry é um evento publicado por esta aplicação.
+type Entry struct {
+	Event       nostr.Event            `json:"event"`
+	PublishedAt time.Time              `json:"published_at"`
+	Relays      map[string]*RelayState `json:"relays"`
+}
+
+// Title devolve o título do evento, ou seu ID abreviado quando não houver título.
+func (e Entry) Title() string {
+	if tag := e.Event.Tags.Find("title"); len(tag) > 1 && tag[1] != "" {
+		return tag[1]
+	}
+	if len(e.Event.ID) > 12 {
+		return e.Event.ID[:12] + "..."
+	}
+	return e.Event.ID
+}
+
+// AcceptedRelays devolve, em ordem alfabética, os relays que aceitaram o evento.
+func (e Entry) AcceptedRelays() []string {
+	var urls []string
+	for url, st := range e.Relays {
+		if st.Accepted {
+			urls = append(urls, url)
+		}
+	}
+	sort.Strings(urls)
+	return urls
+}
+
+// VerifiedCount devolve quantos relays confirmaram o evento por leitura.
+func (e Entry) VerifiedCount() int {
+	n := 0
+	for _, st := range e.Relays {
+		if st.Verified {
+			n++
+		}
+	}
+	return n
+}
+
+// Obsolete devolve os IDs dos eventos que os relays deixam de guardar por
+// regra, e não por descarte: versões de um evento endereçável ou substituível
+// superadas por outra mais nova do mesmo endereço e eventos alvos de um pedido
+// de exclusão (kind 5) do mesmo autor registrado no histórico, pelo id ou pela
+// coordenada.
+func Obsolete(entries []Entry) map[string]bool {
+	latest := make(map[string]nostr.Event)
+	deletedIDs := make(map[string]string)             // id -> autor do pedido
+	deletedBefore := make(map[string]nostr.Timestamp) // coordenada -> data do pedido
+	for _, e := range entries {
+		evt := e.Event
+		if evt.Kind == nip09.KindDeletion {
+			for _, tag := range evt.Tags {
+				if len(tag) < 2 {
+					continue
+				}
+				switch tag[0] {
+				case "e":
+					deletedIDs[tag[1]] = evt.PubKey
+				case "a":
+					// A coordenada só vale para eventos do próprio autor do pedido
+					parts := strings.SplitN(tag[1], ":", 3)
+					if len(parts) == 3 && parts[1] == evt.PubKey && evt.CreatedAt > deletedBefore[tag[1]] {
+						deletedBefore[tag[1]] = evt.CreatedAt
+					}
+				}
+			}
+			continue
+		}
+		if coordinate := nip09.Coordinate(evt); coordinate != "" {
+			// Em empate de data, os relays mantêm o menor id (NIP-01)
+			if newest, ok := latest[coordinate]; !ok || evt.CreatedAt > newest.CreatedAt ||
+				(evt.CreatedAt == newest.CreatedAt && evt.ID < newest.ID) {
+				latest[coordinate] = evt
+			}
+		}
+	}
+
+	obsolete := make(map[string]bool)
+	for _, e := range entries {
+		evt := e.Event
+		if author, ok := deletedIDs[evt.ID]; ok && author == evt.PubKey {
+			obsolete[evt.ID] = true
+			continue
+		}
+		coordinate := nip09.Coordinate(evt)
+		if coordinate == "" || evt.Kind == nip09.KindDeletion {
+			continue
+		}
+		if latest[coordinate].ID != evt.ID {
+			obsolete[evt.ID] = true
+		}
+		if deletedAt, ok := deletedBefore[coordinate]; ok && evt.CreatedAt <= deletedAt {
+			obsolete[evt.ID] = true
+		}
+	}
+	return obsolete
+}
+
+// Store é o histórico local e persistente dos eventos publicados.
+type Store struct {
+	mu      sync.Mutex
+	path    string
+	entries []*Entry
+
+	// OnChange, se definido, é chamado depois de qualquer alteração no histórico.
+	OnChange func()
+}
+
+// Open carrega o histórico a partir do arquivo informado, criando um histórico
+// vazio caso o arquivo ainda não exista.
+func Open(path string) (*Store, error) {
+	s := &Store{path: path}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading history %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &s.entries); err != nil {
+		return nil, fmt.Errorf("error decoding history %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// NewMemory cria um histórico vazio mantido apenas na memória, usado quando o
+// arquivo do histórico não pode ser aberto. As entradas se perdem ao fechar o aplicativo.
+func NewMemory() *Store {
+	return &Store{}
+}
+
+// RecordAccepted registra que o relay aceitou a publicação do evento.
+func (s *Store) RecordAccepted(evt nostr.Event, relayURL string) error {
+	return s.update(evt, func(e *Entry) {
+		e.relay(relayURL).Accepted = true
+	})
+}
+
+// RecordVerified registra o resultado de uma verificação por leitura.
+func (s *Store) RecordVerified(evt nostr.Event, relayURL string, verified bool) error {
+	return s.update(evt, func(e *Entry) {
+		st := e.relay(relayURL)
+		st.Verified = verified
+		st.CheckedAt = time.Now()
+	})
+}
+
+// Entries devolve uma cópia das entradas, da mais recente para a mais antiga.
+func (s *Store) Entries() []Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Entry, 0, len(s.entries))
+	for i := len(s.entries) - 1; i >= 0; i-- {
+		e := *s.entries[i]
+		e.Relays = make(map[string]*RelayState, len(s.entries[i].Relays))
+		for url, st := range s.entries[i].Relays {
+			copied := *st
+			e.Relays[url] = &copied
+		}
+		out = append(out, e)
+	}
+	return out
+}
+
+// update aplica fn à entrada do evento, criando-a se necessário, e grava o histórico.
+func (s *Store) update(evt nostr.Event, fn func(e *Entry)) error {
+	s.mu.Lock()
+	var entry *Entry
+	for _, e := range s.entries {
+		if e.Event.ID == evt.ID {
+			entry = e
+			break
+		}
+	}
+	if entry == nil {
+		entry = &Entry{Event: evt, PublishedAt: time.Now(), Relays: make(map[string]*RelayState)}
+		s.entries = append(s.entries, entry)
+	}
+	fn(entry)
+	err := s.saveLocked()
+	s.mu.Unlock()
+
+	if s.OnChange != nil {
+		s.OnChange()
+	}
+	return err
+}
+
+// relay devolve o estado do relay na entrada, criando-o se necessário.
+func (e *Entry) relay(url string) *RelayState {
+	st, ok := e.Relays[url]
+	if !ok {
+		st = &RelayState{}
+		e.Relays[url] = st
+	}
+	return st
+}
+
+// saveLocked grava o histórico em um arquivo temporário e o renomeia por cima
+// do original. Históricos em memória não são gravados. O chamador deve segurar mu.
+func (s *Store) saveLocked() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(s.entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding history: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("error creating history directory: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("error writing history: %w", err)
+	}
+	return os.Rename(tmp, s.path)
+}
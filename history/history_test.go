@@ -0,0 +1,58 @@
+package history
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestObsolete(t *testing.T) {
+	const alice, bob = "alice", "bob"
+	video := func(id, d string, createdAt nostr.Timestamp) nostr.Event {
+		return nostr.Event{ID: id, PubKey: alice, Kind: 34235, CreatedAt: createdAt, Tags: nostr.Tags{{"d", d}}}
+	}
+	file := func(id string) nostr.Event {
+		return nostr.Event{ID: id, PubKey: alice, Kind: 1063, CreatedAt: 100}
+	}
+	deletion := func(pubkey string, createdAt nostr.Timestamp, tags ...nostr.Tag) nostr.Event {
+		return nostr.Event{ID: "del", PubKey: pubkey, Kind: 5, CreatedAt: createdAt, Tags: tags}
+	}
+
+	tests := []struct {
+		name   string
+		events []nostr.Event
+		want   []string
+	}{
+		{"eventos regulares", []nostr.Event{file("a"), file("b")}, nil},
+		{"versão editada", []nostr.Event{video("v1", "x", 100), video("v2", "x", 200)}, []string{"v1"}},
+		{"endereços diferentes", []nostr.Event{video("v1", "x", 100), video("v2", "y", 200)}, nil},
+		{"empate fica com o menor id", []nostr.Event{video("b", "x", 100), video("a", "x", 100)}, []string{"b"}},
+		{"excluído pelo id", []nostr.Event{file("a"), file("b"), deletion(alice, 300, nostr.Tag{"e", "a"})}, []string{"a"}},
+		{"exclusão de outro autor", []nostr.Event{file("a"), deletion(bob, 300, nostr.Tag{"e", "a"})}, nil},
+		{
+			"excluído pela coordenada",
+			[]nostr.Event{video("v1", "x", 100), video("v2", "x", 200), deletion(alice, 200, nostr.Tag{"a", "34235:alice:x"})},
+			[]string{"v1", "v2"},
+		},
+		{
+			"versão posterior à exclusão",
+			[]nostr.Event{video("v1", "x", 100), deletion(alice, 150, nostr.Tag{"a", "34235:alice:x"}), video("v2", "x", 200)},
+			[]string{"v1"},
+		},
+		{"coordenada malformada", []nostr.Event{file("a"), deletion(alice, 300, nostr.Tag{"a", "lixo"})}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries := make([]Entry, len(tt.events))
+			for i, evt := range tt.events {
+				entries[i] = Entry{Event: evt}
+			}
+			got := slices.Sorted(maps.Keys(Obsolete(entries)))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Obsolete = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
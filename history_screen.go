@@ -0,0 +1,284 @@
+package main
+
+import (
+	"NostrFilePublisher/history"
+	"NostrFilePublisher/nip35"
+	"NostrFilePublisher/nip68"
+	"NostrFilePublisher/nip71"
+	"NostrFilePublisher/nipa0"
+	"NostrFilePublisher/relay"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// historyScreen lista os eventos publicados e permite verificar novamente se
+// os relays que os aceitaram ainda os mantêm.
+func historyScreen(win fyne.Window) fyne.CanvasObject {
+	entries := History.Entries()
+	selected := -1
+
+	list := widget.NewList(
+		func() int { return len(entries) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			e := entries[i]
+			o.(*widget.Label).SetText(fmt.Sprintf("[kind %d] %s - %s - verificado em %d de %d relays",
+				e.Event.Kind, e.Title(), e.PublishedAt.Format("02/01/2006 15:04"),
+				e.VerifiedCount(), len(e.AcceptedRelays())))
+		},
+	)
+	list.OnSelected = func(id widget.ListItemID) { selected = id }
+	list.OnUnselected = func(widget.ListItemID) { selected = -1 }
+
+	History.OnChange = func() {
+		fyne.Do(func() {
+			entries = History.Entries()
+			list.UnselectAll()
+			list.Refresh()
+		})
+	}
+
+	detailsButton := widget.NewButton("Detalhes", func() {
+		if selected < 0 || selected >= len(entries) {
+			dialog.ShowInformation("Atenção", "Selecione um evento do histórico.", win)
+			return
+		}
+		e := entries[selected]
+		var b strings.Builder
+		urls := make([]string, 0, len(e.Relays))
+		for url := range e.Relays {
+			urls = append(urls, url)
+		}
+		sort.Strings(urls)
+		for _, url := range urls {
+			st := e.Relays[url]
+			status := "não verificado"
+			if st.Verified {
+				status = "verificado"
+			} else if !st.CheckedAt.IsZero() {
+				status = "não encontrado em " + st.CheckedAt.Format("02/01/2006 15:04")
+			}
+			fmt.Fprintf(&b, "%s: %s\n", url, status)
+		}
+		fmt.Fprintf(&b, "\n%s\n", e.Event.String())
+		details := widget.NewMultiLineEntry()
+		details.SetText(b.String())
+		details.Wrapping = fyne.TextWrapWord
+		d := dialog.NewCustom("Detalhes do Evento", "Fechar", details, win)
+		d.Resize(fyne.NewSize(600, 400))
+		d.Show()
+	})
+
+	deleteButton := widget.NewButton("Excluir Publicação", func() {
+		if selected < 0 || selected >= len(entries) {
+			dialog.ShowInformation("Atenção", "Selecione um evento do histórico.", win)
+			return
+		}
+		showDeleteDialog(win, entries[selected])
+	})
+
+	shareButton := widget.NewButton("Compartilhar", func() {
+		if selected < 0 || selected >= len(entries) {
+			dialog.ShowInformation("Atenção", "Selecione um evento do histórico.", win)
+			return
+		}
+		showShareDialog(win, entries[selected].Event, entries[selected].AcceptedRelays())
+	})
+
+	announceButton := widget.NewButton("Anunciar", func() {
+		if selected < 0 || selected >= len(entries) {
+			dialog.ShowInformation("Atenção", "Selecione um evento do histórico.", win)
+			return
+		}
+		showAnnounceDialog(win, entries[selected].Event, entries[selected].AcceptedRelays())
+	})
+
+	progressLabel := widget.NewLabel("")
+	recheckButton := widget.NewButton("Verificar Novamente", func() {
+		progressLabel.SetText("Verificando eventos nos relays...")
+		go func() {
+			report := recheckHistory(History.Entries())
+			fyne.Do(func() {
+				progressLabel.SetText("")
+				out := widget.NewMultiLineEntry()
+				out.SetText(report)
+				out.Wrapping = fyne.TextWrapWord
+				d := dialog.NewCustom("Resultado da Verificação", "Fechar", out, win)
+				d.Resize(fyne.NewSize(600, 400))
+				d.Show()
+			})
+		}()
+	})
+
+	return container.NewBorder(
+		widget.NewLabelWithStyle("Histórico de Publicações", fyne.TextAlignCenter, fyne.TextStyle{Bold: true}),
+		container.NewVBox(progressLabel, container.NewCenter(container.NewHBox(detailsButton, shareButton, announceButton, recheckButton, deleteButton,
+			widget.NewButton("Retransmitir", func() { showRebroadcastDialog(win) })))),
+		nil, nil,
+		container.NewScroll(list),
+	)
+}
+
+// rebroadcastKinds são os kinds de eventos de arquivo, vídeo, fotos, voz e torrent considerados na retransmissão.
+var rebroadcastKinds = []int{
+	nostr.KindFileMetadata, nip68.KindPicture, nipa0.KindVoiceMessage, nip35.KindTorrent,
+	nip71.KindVideo, nip71.KindShortVideo,
+	nip71.KindAddressableVideo, nip71.KindAddressableShortVideo,
+}
+
+const (
+	sourceReadRelays   = "Relays de leitura"
+	sourceLocalHistory = "Histórico local"
+)
+
+// showRebroadcastDialog republica, sem alterações, nossos eventos de arquivo e
+// vídeo em relays escolhidos, buscando-os nos relays de leitura ou no histórico local.
+func showRebroadcastDialog(win fyne.Window) {
+	App.Mutex.Lock()
+	pubkey := App.Npub
+	readRelays := App.ReadRelays()
+	writeRelays := App.WriteRelays()
+	App.Mutex.Unlock()
+
+	if pubkey == "" {
+		dialog.ShowInformation("Atenção", "Por favor, configure sua chave NSEC na aba de Configurações.", win)
+		return
+	}
+
+	sourceRadio := widget.NewRadioGroup([]string{sourceReadRelays, sourceLocalHistory}, nil)
+	sourceRadio.SetSelected(sourceReadRelays)
+	targetCheck := widget.NewCheckGroup(writeRelays, nil)
+
+	content := container.NewVBox(
+		widget.NewLabel("Origem dos eventos:"),
+		sourceRadio,
+		widget.NewLabel("Relays de destino:"),
+		targetCheck,
+	)
+	d := dialog.NewCustomConfirm("Retransmitir Eventos", "Retransmitir", "Cancelar", content, func(ok bool) {
+		if !ok {
+			return
+		}
+		if len(targetCheck.Selected) == 0 {
+			dialog.ShowInformation("Atenção", "Selecione ao menos um relay de destino.", win)
+			return
+		}
+		runRebroadcast(win, sourceRadio.Selected, pubkey, readRelays, append([]string(nil), targetCheck.Selected...))
+	}, win)
+	d.Resize(fyne.NewSize(450, 400))
+	d.Show()
+}
+
+// runRebroadcast carrega os eventos da origem escolhida e os republica nos
+// destinos, exibindo o progresso em um diálogo.
+func runRebroadcast(win fyne.Window, source, pubkey string, readRelays, targets []string) {
+	progress := widget.NewProgressBar()
+	logOutput := widget.NewMultiLineEntry()
+	logOutput.Wrapping = fyne.TextWrapWord
+	statusLabel := widget.NewLabel("Carregando eventos...")
+	d := dialog.NewCustom("Retransmissão", "Fechar", container.NewBorder(
+		container.NewVBox(statusLabel, progress), nil, nil, nil, logOutput), win)
+	d.Resize(fyne.NewSize(600, 400))
+	d.Show()
+
+	appendLog := func(line string) {
+		fyne.Do(func() { logOutput.SetText(logOutput.Text + line + "\n") })
+	}
+
+	go func() {
+		var events []nostr.Event
+		if source == sourceLocalHistory {
+			for _, e := range History.Entries() {
+				if slices.Contains(rebroadcastKinds, e.Event.Kind) && e.Event.PubKey == pubkey {
+					events = append(events, e.Event)
+				}
+			}
+		} else {
+			var err error
+			events, err = relay.FetchAuthorEvents(readRelays, pubkey, rebroadcastKinds)
+			if err != nil {
+				fyne.Do(func() { statusLabel.SetText(fmt.Sprintf("Erro ao buscar eventos: %v", err)) })
+				return
+			}
+		}
+		if len(events) == 0 {
+			fyne.Do(func() { statusLabel.SetText("Nenhum evento encontrado para retransmitir.") })
+			return
+		}
+
+		fyne.Do(func() {
+			statusLabel.SetText(fmt.Sprintf("Retransmitindo %d evento(s) para %d relay(s)...", len(events), len(targets)))
+		})
+		sent := relay.Rebroadcast(events, targets, func(p relay.Progress) {
+			fyne.Do(func() { progress.SetValue(float64(p.Done) / float64(p.Total)) })
+			appendLog(p.Message)
+		})
+		fyne.Do(func() {
+			progress.SetValue(1)
+			statusLabel.SetText(fmt.Sprintf("Concluído: %d evento(s) enviado(s).", sent))
+		})
+	}()
+}
+
+// recheckHistory consulta cada relay pelos eventos que ele aceitou, registra o
+// resultado no histórico e devolve um relatório dos eventos que foram descartados.
+// Versões substituídas por uma edição mais nova e eventos excluídos não são
+// consultados, pois os relays deixam de guardá-los por regra.
+func recheckHistory(entries []history.Entry) string {
+	obsolete := history.Obsolete(entries)
+	byRelay := make(map[string][]history.Entry)
+	for _, e := range entries {
+		if obsolete[e.Event.ID] {
+			continue
+		}
+		for _, url := range e.AcceptedRelays() {
+			byRelay[url] = append(byRelay[url], e)
+		}
+	}
+	urls := make([]string, 0, len(byRelay))
+	for url := range byRelay {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+
+	var b strings.Builder
+	dropped := 0
+	for _, url := range urls {
+		relayEntries := byRelay[url]
+		ids := make([]string, len(relayEntries))
+		for i, e := range relayEntries {
+			ids[i] = e.Event.ID
+		}
+		found, err := relay.FetchIDs(url, ids)
+		if err != nil {
+			// Sem resposta do relay não é possível afirmar que os eventos foram descartados.
+			fmt.Fprintf(&b, "%s: erro na verificação: %v\n", url, err)
+			continue
+		}
+		for _, e := range relayEntries {
+			if err := History.RecordVerified(e.Event, url, found[e.Event.ID]); err != nil {
+				fmt.Fprintf(&b, "%s: erro ao registrar verificação: %v\n", url, err)
+			}
+			if !found[e.Event.ID] {
+				dropped++
+				fmt.Fprintf(&b, "%s: descartou \"%s\" (%s)\n", url, e.Title(), e.Event.ID)
+			}
+		}
+	}
+
+	if len(obsolete) > 0 {
+		fmt.Fprintf(&b, "\n%d evento(s) substituído(s) por uma versão mais nova ou excluído(s) não foram verificados.\n", len(obsolete))
+	}
+	if dropped == 0 {
+		return "Todos os relays verificados ainda mantêm os eventos publicados.\n" + b.String()
+	}
+	return fmt.Sprintf("%d evento(s) não foram encontrados:\n\n%s", dropped, b.String())
+}
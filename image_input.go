@@ -0,0 +1,99 @@
+package main
+
+import (
+	"NostrFilePublisher/imagemeta"
+	"NostrFilePublisher/nip71"
+	"context"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// imageInput é um campo de URL de imagem com um botão para enviar uma imagem
+// local. Imagens enviadas pelo aplicativo guardam o hash, as URLs alternativas,
+// o BlurHash e as dimensões; URLs digitadas manualmente têm apenas a URL.
+type imageInput struct {
+	entry  *widget.Entry
+	image  nip71.Image
+	widget fyne.CanvasObject
+}
+
+// newImageInput cria o campo com o texto de exemplo informado.
+func newImageInput(win fyne.Window, placeholder string) *imageInput {
+	in := &imageInput{entry: widget.NewEntry()}
+	in.entry.SetPlaceHolder(placeholder)
+	in.entry.OnChanged = func(s string) {
+		if s != in.image.URL {
+			in.image = nip71.Image{URL: s}
+		}
+	}
+	in.widget = container.NewBorder(nil, nil, nil, imageUploadButton(win, in.Set), in.entry)
+	return in
+}
+
+// Set preenche o campo com uma imagem já enviada.
+func (in *imageInput) Set(img nip71.Image) {
+	in.image = img
+	in.entry.SetText(img.URL)
+}
+
+// Reset limpa o campo.
+func (in *imageInput) Reset() {
+	in.Set(nip71.Image{})
+}
+
+// imageUploadButton cria o botão que escolhe uma imagem local, envia-a aos
+// servidores Blossom e chama onUploaded com o resultado.
+func imageUploadButton(win fyne.Window, onUploaded func(nip71.Image)) *widget.Button {
+	return widget.NewButtonWithIcon("", theme.FolderOpenIcon(), func() {
+		fileDialog := dialog.NewFileOpen(func(file fyne.URIReadCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, win)
+				return
+			}
+			if file == nil {
+				return
+			}
+			defer file.Close()
+
+			path := file.URI().Path()
+			mimeType := file.URI().MimeType()
+			if !strings.HasPrefix(mimeType, "image/") {
+				dialog.ShowInformation("Atenção", "Por favor, selecione um arquivo de imagem.", win)
+				return
+			}
+
+			// O BlurHash e as dimensões vêm da imagem local, antes do envio
+			var img nip71.Image
+			var removed []string
+			runCancellable(win, "Enviando Imagem", "Lendo a imagem e enviando para os servidores Blossom...",
+				func(ctx context.Context) error {
+					meta, err := imagemeta.DecodeFile(ctx, path)
+					if err != nil {
+						return err
+					}
+					var upload localUpload
+					img, upload, err = uploadImage(path, mimeType, meta)
+					removed = upload.Removed
+					return err
+				},
+				func(err error) {
+					if err != nil {
+						dialog.ShowError(err, win)
+						return
+					}
+					onUploaded(img)
+					if len(removed) > 0 {
+						dialog.ShowInformation("Privacidade", removedMetadataText(removed), win)
+					}
+				})
+		}, win)
+		fileDialog.SetFilter(storage.NewExtensionFileFilter([]string{".jpg", ".jpeg", ".png", ".gif", ".webp", ".avif"}))
+		fileDialog.Show()
+	})
+}
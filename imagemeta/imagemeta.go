@@ -0,0 +1,169 @@
+package imagemeta
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"net/http"
+	"os"
+
+	"NostrFilePublisher/resize"
+	"NostrFilePublisher/sanitize"
+
+	"github.com/bbrks/go-blurhash"
+
+	// Decodificadores registrados para image.Decode.
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	// xComponents e yComponents são os componentes do BlurHash usados em todo o aplicativo.
+	xComponents = 4
+	yComponents = 3
+
+	// sampleSize é o maior lado da imagem reduzida usada no cálculo do BlurHash.
+	// O BlurHash só guarda as frequências baixas, então a redução não altera o resultado
+	// de forma perceptível e torna o cálculo instantâneo mesmo em fotos grandes.
+	sampleSize = 64
+
+	// maxFetchSize limita o download de imagens remotas.
+	maxFetchSize = 32 << 20
+)
+
+// Meta reúne os dados de uma imagem usados nas tags "dim" e "blurhash".
+type Meta struct {
+	Width, Height int
+	BlurHash      string
+}
+
+// Dim devolve as dimensões no formato "<largura>x<altura>", ou "" se desconhecidas.
+func (m Meta) Dim() string {
+	if m.Width <= 0 || m.Height <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%dx%d", m.Width, m.Height)
+}
+
+// FromImage calcula as dimensões e o BlurHash de uma imagem já decodificada.
+func FromImage(ctx context.Context, img image.Image) (Meta, error) {
+	bounds := img.Bounds()
+	meta := Meta{Width: bounds.Dx(), Height: bounds.Dy()}
+	if meta.Width <= 0 || meta.Height <= 0 {
+		return meta, fmt.Errorf("empty image")
+	}
+
+	sample, err := downscale(ctx, img)
+	if err != nil {
+		return meta, err
+	}
+	meta.BlurHash, err = blurhash.Encode(xComponents, yComponents, sample)
+	if err != nil {
+		return meta, fmt.Errorf("error encoding blurhash: %w", err)
+	}
+	return meta, nil
+}
+
+// Decode decodifica uma imagem (JPEG, PNG, GIF ou WebP) e calcula seus
+// metadados. A orientação EXIF é aplicada antes, para que as dimensões e o
+// BlurHash correspondam à imagem como ela é exibida.
+func Decode(ctx context.Context, r io.Reader) (Meta, error) {
+	if err := ctx.Err(); err != nil {
+		return Meta{}, err
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Meta{}, err
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return Meta{}, ctxErr
+		}
+		return Meta{}, fmt.Errorf("error decoding image: %w", err)
+	}
+	return FromImage(ctx, resize.Orient(img, sanitize.Orientation(data)))
+}
+
+// DecodeFile lê os metadados de uma imagem local.
+func DecodeFile(ctx context.Context, path string) (Meta, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Meta{}, err
+	}
+	defer f.Close()
+	return Decode(ctx, f)
+}
+
+// Fetch baixa uma imagem remota e calcula seus metadados. O download é
+// interrompido quando ctx é cancelado.
+func Fetch(ctx context.Context, client *http.Client, url string) (Meta, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return Meta{}, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return Meta{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Meta{}, fmt.Errorf("unexpected status fetching image: %s", resp.Status)
+	}
+	return Decode(ctx, io.LimitReader(resp.Body, maxFetchSize))
+}
+
+// downscale reduz a imagem para no máximo sampleSize pixels no maior lado,
+// usando a média de cada bloco de pixels.
+func downscale(ctx context.Context, img image.Image) (image.Image, error) {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= sampleSize && height <= sampleSize {
+		return img, nil
+	}
+
+	dstW, dstH := sampleSize, sampleSize
+	if width > height {
+		dstH = max(1, height*sampleSize/width)
+	} else {
+		dstW = max(1, width*sampleSize/height)
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < dstH; y++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		y0 := bounds.Min.Y + y*height/dstH
+		y1 := bounds.Min.Y + (y+1)*height/dstH
+		for x := 0; x < dstW; x++ {
+			x0 := bounds.Min.X + x*width/dstW
+			x1 := bounds.Min.X + (x+1)*width/dstW
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := img.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(cr), g+uint64(cg), b+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+			if a == 0 {
+				continue
+			}
+			// RGBA devolve valores pré-multiplicados; NRGBA guarda valores não pré-multiplicados.
+			dst.SetNRGBA(x, y, color.NRGBA{
+				R: uint8(r * 0xff / a),
+				G: uint8(g * 0xff / a),
+				B: uint8(b * 0xff / a),
+				A: uint8(a / n >> 8),
+			})
+		}
+	}
+	return dst, nil
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"NostrFilePublisher/blossom"
+	"NostrFilePublisher/model"
+	"NostrFilePublisher/remote"
+	"NostrFilePublisher/sanitize"
+	"NostrFilePublisher/util"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	urlX "net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// downloadCacheAge é o tempo em que um download parcial é mantido para ser
+// retomado antes de ser apagado.
+const downloadCacheAge = 7 * 24 * time.Hour
+
+// downloadPath devolve o caminho local de um arquivo importado de sourceURL. O
+// nome é derivado da URL, de modo que uma importação interrompida da mesma URL
+// seja retomada; a extensão é mantida para a detecção do tipo. O arquivo
+// completo é apagado depois do envio (ou pela fila de saída, se o envio ficar
+// pendente), e os downloads parciais abandonados, depois de downloadCacheAge.
+func downloadPath(sourceURL string) (string, error) {
+	dir := filepath.Join(myApp.Storage().RootURI().Path(), "downloads")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	if err := remote.PrunePartial(dir, downloadCacheAge); err != nil {
+		log.Println("Erro ao limpar os downloads antigos:", err)
+	}
+	sum := sha256.Sum256([]byte(sourceURL))
+	name := hex.EncodeToString(sum[:8])
+	if parsed, err := urlX.Parse(sourceURL); err == nil {
+		name += path.Ext(parsed.Path)
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// showImportDialog pede a URL de um arquivo a ser republicado, o hash esperado
+// (opcional) e se os servidores devem espelhá-lo. O arquivo é baixado com
+// retomada e onImported recebe o caminho local e os dados do download.
+func showImportDialog(win fyne.Window, onImported func(path string, info remote.Info, mirror bool)) {
+	urlEntry := widget.NewEntry()
+	urlEntry.SetPlaceHolder("https://cdn.example.com/b1674191a88ec5cdd733e4240a81803105dc412d6c6708d53ab94fc248f4f553.pdf")
+	urlEntry.Validator = validateURL
+
+	sha256Entry := widget.NewEntry()
+	sha256Entry.SetPlaceHolder("SHA-256 esperado (opcional)")
+	sha256Entry.Validator = func(s string) error {
+		if s == "" {
+			return nil
+		}
+		if _, err := hex.DecodeString(s); err != nil || len(s) != 64 {
+			return fmt.Errorf("SHA-256 inválido, deve ter 64 caracteres hexadecimais")
+		}
+		return nil
+	}
+
+	mirrorCheck := widget.NewCheck("Pedir aos servidores que espelhem a URL (BUD-04), enviando o arquivo aos que não aceitarem", nil)
+	mirrorCheck.SetChecked(true)
+
+	var d dialog.Dialog
+	importButton := widget.NewButton("Importar", func() {
+		for _, entry := range []*widget.Entry{urlEntry, sha256Entry} {
+			if err := entry.Validate(); err != nil {
+				dialog.ShowError(err, win)
+				return
+			}
+		}
+		sourceURL := strings.TrimSpace(urlEntry.Text)
+		expected := strings.ToLower(strings.TrimSpace(sha256Entry.Text))
+		dest, err := downloadPath(sourceURL)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("Erro ao preparar o download: %w", err), win)
+			return
+		}
+		mirror := mirrorCheck.Checked
+		d.Hide()
+		runDownload(win, "Importando Arquivo", sourceURL,
+			func(ctx context.Context, client *http.Client, onProgress remote.Progress) (remote.Info, error) {
+				return remote.Download(ctx, client, sourceURL, dest, expected, onProgress)
+			},
+			func(info remote.Info) {
+				onImported(dest, info, mirror)
+			})
+	})
+
+	d = dialog.NewCustom("Importar de URL", "Fechar", container.NewVBox(
+		widget.NewLabel("URL do arquivo (servidor HTTP ou Blossom):"),
+		urlEntry,
+		sha256Entry,
+		mirrorCheck,
+		widget.NewLabel("Downloads interrompidos são retomados ao importar a mesma URL novamente."),
+		importButton,
+	), win)
+	d.Resize(fyne.NewSize(600, 0))
+	d.Show()
+}
+
+// mirrorOrUpload republica nos servidores Blossom configurados um arquivo
+// baixado de sourceURL. Os servidores primeiro são convidados a espelhar a URL
+// (BUD-04); o arquivo local é enviado apenas aos que recusarem. Imagens que
+// terão os metadados removidos mudam de hash e por isso são sempre enviadas.
+func mirrorOrUpload(path, mimeType, sourceURL string) (localUpload, error) {
+	App.Mutex.Lock()
+	state := *App
+	App.Mutex.Unlock()
+	if state.StripMetadata && sanitize.Supported(mimeType) {
+		return uploadLocalFile(path, mimeType)
+	}
+	if len(state.BlossomServers) == 0 {
+		return localUpload{}, fmt.Errorf("Nenhum servidor Blossom configurado. Por favor, adicione um servidor na aba Configurações.")
+	}
+
+	result := localUpload{PreEvent: model.PreEvent{Path: path, MimeType: mimeType, PrivKey: state.Nsec}}
+	var err error
+	result.PreEvent.Sha256, result.PreEvent.Size, err = util.HashFile(path)
+	if err != nil {
+		return result, err
+	}
+	result.OriginalSha256 = result.PreEvent.Sha256
+
+	servers := make([]string, 0, len(state.BlossomServers))
+	for _, bURL := range state.BlossomServers {
+		servers = append(servers, bURL)
+	}
+	responses, errs := blossom.MirrorTo(state.HttpClient, sourceURL, result.PreEvent, state, servers)
+
+	var refused []string
+	for _, e := range errs {
+		var upErr *blossom.UploadError
+		if !errors.As(e, &upErr) {
+			return result, e
+		}
+		log.Println("Espelhamento recusado, enviando o arquivo:", e)
+		refused = append(refused, upErr.Server)
+	}
+	if len(refused) > 0 {
+		uploaded, errs := blossom.SendFileTo(state.HttpClient, result.PreEvent, state, refused)
+		responses = append(responses, uploaded...)
+		if len(errs) > 0 {
+			pending, uploadErr := queueFailedUploads(result.PreEvent, errs)
+			result.Queued = len(pending) > 0
+			responses = append(responses, pending...)
+			if len(responses) == 0 {
+				return result, uploadErr
+			}
+			log.Println(uploadErr)
+		}
+	}
+	result.Responses = responses
+	return result, nil
+}
@@ -0,0 +1,101 @@
+package ipfs
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DefaultAPI é o endereço padrão da API HTTP do Kubo.
+const DefaultAPI = "http://127.0.0.1:5001"
+
+// addResponse é uma linha da resposta de /api/v0/add.
+type addResponse struct {
+	Name string
+	Hash string
+}
+
+// apiError é o corpo das respostas de erro da API do Kubo.
+type apiError struct {
+	Message string
+}
+
+// Add envia o arquivo ao nó IPFS pela API HTTP (/api/v0/add), com os mesmos
+// parâmetros do cálculo local, e o fixa (pin). Devolve o CID informado pelo
+// nó. O arquivo é transmitido sem ser carregado inteiro na memória.
+func Add(ctx context.Context, client *http.Client, apiURL, path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	query := url.Values{
+		"cid-version": {"1"},
+		"raw-leaves":  {"true"},
+		"chunker":     {"size-" + strconv.Itoa(ChunkSize)},
+		"pin":         {"true"},
+		"quieter":     {"true"},
+	}
+	endpoint := strings.TrimRight(apiURL, "/") + "/api/v0/add?" + query.Encode()
+
+	body, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	go func() {
+		part, err := mw.CreateFormFile("file", filepath.Base(path))
+		if err == nil {
+			_, err = io.Copy(part, f)
+		}
+		if err == nil {
+			err = mw.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, body)
+	if err != nil {
+		body.Close()
+		return "", err
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	resp, err := client.Do(req)
+	if err != nil {
+		body.Close()
+		return "", fmt.Errorf("error contacting IPFS API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr apiError
+		if json.NewDecoder(resp.Body).Decode(&apiErr) == nil && apiErr.Message != "" {
+			return "", fmt.Errorf("IPFS API returned %s: %s", resp.Status, apiErr.Message)
+		}
+		return "", fmt.Errorf("IPFS API returned %s", resp.Status)
+	}
+
+	// A resposta traz uma linha JSON por objeto adicionado; a última é a raiz
+	var last addResponse
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var line addResponse
+		if err := json.Unmarshal(scanner.Bytes(), &line); err == nil && line.Hash != "" {
+			last = line
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	if last.Hash == "" {
+		return "", fmt.Errorf("IPFS API response has no CID")
+	}
+	return last.Hash, nil
+}
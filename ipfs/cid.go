@@ -0,0 +1,172 @@
+package ipfs
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+)
+
+// Parâmetros padrão do "ipfs add --cid-version=1" do Kubo: blocos de 256 KiB,
+// folhas cruas (raw) e árvore balanceada com até 174 links por nó.
+const (
+	ChunkSize = 256 << 10
+	MaxLinks  = 174
+)
+
+// Códigos multicodec e multihash usados nos CIDs.
+const (
+	codecRaw    = 0x55
+	codecDagPB  = 0x70
+	hashSha256  = 0x12
+	cidVersion1 = 1
+)
+
+// unixfsFile é o tipo File da mensagem Data do UnixFS.
+const unixfsFile = 2
+
+// base32Lower é a base "b" do multibase: base32 RFC 4648 minúscula, sem preenchimento.
+var base32Lower = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// link é um filho já codificado da árvore UnixFS.
+type link struct {
+	cid      []byte
+	fileSize uint64 // bytes do arquivo cobertos pelo filho
+	tsize    uint64 // tamanho total dos blocos do filho
+}
+
+// FileCID calcula o CIDv1 do arquivo como o Kubo faria com os parâmetros padrão.
+func FileCID(ctx context.Context, path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	return CID(ctx, f)
+}
+
+// CID lê r em blocos de ChunkSize, cada um uma folha crua, e monta a árvore
+// balanceada do UnixFS. Um arquivo de um só bloco tem como CID o da própria
+// folha.
+func CID(ctx context.Context, r io.Reader) (string, error) {
+	var tree builder
+	buf := make([]byte, ChunkSize)
+	for first := true; ; first = false {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		n, err := io.ReadFull(r, buf)
+		done := errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+		if err != nil && !done {
+			return "", err
+		}
+		// Um arquivo vazio ainda gera uma folha vazia
+		if n > 0 || first {
+			tree.add(0, rawLeaf(buf[:n]))
+		}
+		if done {
+			break
+		}
+	}
+	return encodeCID(tree.root().cid), nil
+}
+
+// builder monta a árvore balanceada do Kubo à medida que as folhas chegam.
+// Apenas os links pendentes de cada nível ficam na memória.
+type builder struct {
+	levels [][]link
+}
+
+// add acrescenta um filho ao nível informado; um nível cheio vira um nó do
+// nível acima antes de receber o novo filho.
+func (b *builder) add(level int, l link) {
+	if level == len(b.levels) {
+		b.levels = append(b.levels, nil)
+	}
+	if len(b.levels[level]) == MaxLinks {
+		b.add(level+1, fileNode(b.levels[level]))
+		b.levels[level] = nil
+	}
+	b.levels[level] = append(b.levels[level], l)
+}
+
+// root fecha os níveis pendentes, de baixo para cima, e devolve a raiz. Os
+// nós fechados passam por add, então um nível acima já cheio também sobe.
+func (b *builder) root() link {
+	if len(b.levels) == 1 && len(b.levels[0]) == 1 {
+		return b.levels[0][0]
+	}
+	for i := 0; ; i++ {
+		node := fileNode(b.levels[i])
+		if i == len(b.levels)-1 {
+			return node
+		}
+		b.add(i+1, node)
+	}
+}
+
+// rawLeaf devolve a folha crua de um bloco do arquivo.
+func rawLeaf(data []byte) link {
+	n := uint64(len(data))
+	return link{cid: cidBytes(codecRaw, sha256.Sum256(data)), fileSize: n, tsize: n}
+}
+
+// fileNode codifica um nó dag-pb do UnixFS com os filhos informados. Como no
+// Kubo, os links vêm antes dos dados e cada link tem nome vazio.
+func fileNode(children []link) link {
+	var fileSize, tsize uint64
+	data := appendVarintField(nil, 1, unixfsFile)
+	for _, c := range children {
+		fileSize += c.fileSize
+	}
+	data = appendVarintField(data, 3, fileSize)
+	for _, c := range children {
+		data = appendVarintField(data, 4, c.fileSize)
+	}
+
+	var node []byte
+	for _, c := range children {
+		pbLink := appendBytesField(nil, 1, c.cid)
+		pbLink = appendBytesField(pbLink, 2, nil)
+		pbLink = appendVarintField(pbLink, 3, c.tsize)
+		node = appendBytesField(node, 2, pbLink)
+		tsize += c.tsize
+	}
+	node = appendBytesField(node, 1, data)
+
+	return link{
+		cid:      cidBytes(codecDagPB, sha256.Sum256(node)),
+		fileSize: fileSize,
+		tsize:    tsize + uint64(len(node)),
+	}
+}
+
+// cidBytes monta a forma binária de um CIDv1 com multihash SHA-256.
+func cidBytes(codec uint64, sum [sha256.Size]byte) []byte {
+	b := binary.AppendUvarint(nil, cidVersion1)
+	b = binary.AppendUvarint(b, codec)
+	b = binary.AppendUvarint(b, hashSha256)
+	b = binary.AppendUvarint(b, sha256.Size)
+	return append(b, sum[:]...)
+}
+
+// encodeCID devolve o CID em base32 com o prefixo multibase "b".
+func encodeCID(cid []byte) string {
+	return "b" + base32Lower.EncodeToString(cid)
+}
+
+// appendVarintField grava um campo protobuf do tipo varint.
+func appendVarintField(b []byte, field int, v uint64) []byte {
+	b = binary.AppendUvarint(b, uint64(field)<<3)
+	return binary.AppendUvarint(b, v)
+}
+
+// appendBytesField grava um campo protobuf delimitado pelo tamanho.
+func appendBytesField(b []byte, field int, v []byte) []byte {
+	b = binary.AppendUvarint(b, uint64(field)<<3|2)
+	b = binary.AppendUvarint(b, uint64(len(v)))
+	return append(b, v...)
+}
@@ -0,0 +1,152 @@
+package ipfs
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"testing"
+)
+
+// leaves devolve n folhas cruas distintas, de três bytes cada.
+func leaves(n int) []link {
+	out := make([]link, n)
+	for i := range out {
+		out[i] = rawLeaf([]byte{byte(i), byte(i >> 8), byte(i >> 16)})
+	}
+	return out
+}
+
+// build passa as folhas pelo builder e devolve a raiz.
+func build(ls []link) link {
+	var tree builder
+	for _, l := range ls {
+		tree.add(0, l)
+	}
+	return tree.root()
+}
+
+func TestBuilderLayout(t *testing.T) {
+	full := func(ls []link) []link {
+		var nodes []link
+		for len(ls) > 0 {
+			n := min(MaxLinks, len(ls))
+			nodes = append(nodes, fileNode(ls[:n]))
+			ls = ls[n:]
+		}
+		return nodes
+	}
+
+	// Uma folha a mais que dois níveis cheios: a raiz tem dois filhos, o
+	// segundo uma cadeia até a folha extra, como no layout balanceado do Kubo.
+	ls := leaves(MaxLinks*MaxLinks + 1)
+	level1 := full(ls[:MaxLinks*MaxLinks])
+	extra := fileNode([]link{fileNode([]link{ls[len(ls)-1]})})
+	want := fileNode([]link{fileNode(level1), extra})
+
+	tests := []struct {
+		name string
+		in   []link
+		want link
+	}{
+		{"uma folha", leaves(1), leaves(1)[0]},
+		{"duas folhas", leaves(2), fileNode(leaves(2))},
+		{"nível cheio", leaves(MaxLinks), fileNode(leaves(MaxLinks))},
+		{"nível cheio mais uma", leaves(MaxLinks + 1), fileNode(full(leaves(MaxLinks + 1)))},
+		{"dois níveis cheios", ls[:MaxLinks*MaxLinks], fileNode(level1)},
+		{"dois níveis cheios mais uma", ls, want},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := build(tt.in)
+			if !bytes.Equal(got.cid, tt.want.cid) {
+				t.Errorf("root = %s, want %s", encodeCID(got.cid), encodeCID(tt.want.cid))
+			}
+			if got.fileSize != 3*uint64(len(tt.in)) {
+				t.Errorf("fileSize = %d, want %d", got.fileSize, 3*len(tt.in))
+			}
+		})
+	}
+}
+
+// chunks devolve n blocos de ChunkSize mais extra bytes, com conteúdo byte(i % 251).
+func chunks(n, extra int) []byte {
+	data := make([]byte, n*ChunkSize+extra)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	return data
+}
+
+// expectedCID monta a árvore do Kubo diretamente: folhas cruas de ChunkSize
+// agrupadas em nós de até MaxLinks, nível a nível.
+func expectedCID(data []byte) string {
+	var level []link
+	for i := 0; i < len(data) || i == 0; i += ChunkSize {
+		level = append(level, rawLeaf(data[i:min(i+ChunkSize, len(data))]))
+	}
+	for len(level) > 1 {
+		var next []link
+		for i := 0; i < len(level); i += MaxLinks {
+			next = append(next, fileNode(level[i:min(i+MaxLinks, len(level))]))
+		}
+		level = next
+	}
+	return encodeCID(level[0].cid)
+}
+
+func TestCID(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		// want é o CID esperado; vazio, ele é calculado por expectedCID
+		want string
+	}{
+		// CIDs conhecidos do "ipfs add --cid-version=1" do Kubo
+		{"vazio", nil, "bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku"},
+		{"hello world", []byte("hello world\n"), "bafkreifjjcie6lypi6ny7amxnfftagclbuxndqonfipmb64f2km2devei4"},
+		{"um bloco cheio", chunks(1, 0), ""},
+		{"dois blocos", chunks(1, 1), ""},
+		{"dois blocos cheios", chunks(2, 0), ""},
+		{"175 blocos", chunks(MaxLinks, 1), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := tt.want
+			if want == "" {
+				want = expectedCID(tt.data)
+			}
+			got, err := CID(context.Background(), bytes.NewReader(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != want {
+				t.Errorf("CID = %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+// TestFileNode confere a codificação dag-pb de um nó com dois filhos: os links
+// (Hash, Name vazio e Tsize) antes do Data do UnixFS (Type, filesize e blocksizes).
+func TestFileNode(t *testing.T) {
+	a := rawLeaf([]byte("a"))
+	b := rawLeaf([]byte("bc"))
+
+	pbLink := func(l link) []byte {
+		body := append([]byte{0x0A, byte(len(l.cid))}, l.cid...)
+		body = append(body, 0x12, 0x00, 0x18, byte(l.tsize))
+		return append([]byte{0x12, byte(len(body))}, body...)
+	}
+	data := []byte{0x08, 0x02, 0x18, 0x03, 0x20, 0x01, 0x20, 0x02}
+	node := append(pbLink(a), pbLink(b)...)
+	node = append(node, 0x0A, byte(len(data)))
+	node = append(node, data...)
+
+	got := fileNode([]link{a, b})
+	if want := cidBytes(codecDagPB, sha256.Sum256(node)); !bytes.Equal(got.cid, want) {
+		t.Errorf("cid = %s, want %s", encodeCID(got.cid), encodeCID(want))
+	}
+	if got.fileSize != 3 || got.tsize != 3+uint64(len(node)) {
+		t.Errorf("fileSize, tsize = %d, %d, want 3, %d", got.fileSize, got.tsize, 3+len(node))
+	}
+}
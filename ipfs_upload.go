@@ -0,0 +1,144 @@
+package main
+
+import (
+	"NostrFilePublisher/ipfs"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// ipfsOptions são as opções de IPFS de uma aba: calcular o CID do arquivo
+// enviado, publicá-lo também como fallback ipfs:// e fixá-lo no nó local.
+type ipfsOptions struct {
+	enabled  *widget.Check
+	fallback *widget.Check
+	pin      *widget.Check
+}
+
+// newIPFSOptions cria as opções desmarcadas; as duas últimas só ficam
+// disponíveis com o cálculo do CID marcado.
+func newIPFSOptions() *ipfsOptions {
+	o := &ipfsOptions{
+		fallback: widget.NewCheck("Adicionar ipfs:// como fallback", nil),
+		pin:      widget.NewCheck("Fixar no nó IPFS local", nil),
+	}
+	o.fallback.Disable()
+	o.pin.Disable()
+	o.enabled = widget.NewCheck("Calcular CID IPFS", func(b bool) {
+		for _, check := range []*widget.Check{o.fallback, o.pin} {
+			if b {
+				check.Enable()
+			} else {
+				check.Disable()
+			}
+		}
+	})
+	return o
+}
+
+// widget devolve as opções lado a lado, para um item de formulário.
+func (o *ipfsOptions) widget() fyne.CanvasObject {
+	return container.NewHBox(o.enabled, o.fallback, o.pin)
+}
+
+// pinAPI devolve o endereço da API do nó em que o arquivo deve ser fixado, ou
+// "" se a opção estiver desmarcada. Sem endereço configurado, o usuário é
+// avisado e o arquivo não é fixado.
+func (o *ipfsOptions) pinAPI(win fyne.Window) string {
+	if !o.enabled.Checked || !o.pin.Checked {
+		return ""
+	}
+	App.Mutex.Lock()
+	apiURL := App.IPFSAPI
+	App.Mutex.Unlock()
+	if apiURL == "" {
+		dialog.ShowInformation("Atenção", "Configure o endereço da API do IPFS na aba Configurações para fixar o arquivo.", win)
+	}
+	return apiURL
+}
+
+// refs devolve o identificador "ipfs:<cid>" e, se a opção estiver marcada, o
+// fallback ipfs:// do CID, ambos vazios se não houver CID.
+func (o *ipfsOptions) refs(cid string) (identifiers, fallbacks []string) {
+	if cid == "" {
+		return nil, nil
+	}
+	identifiers = []string{"ipfs:" + cid}
+	if o.fallback.Checked {
+		fallbacks = []string{"ipfs://" + cid}
+	}
+	return identifiers, fallbacks
+}
+
+// fileIPFS calcula localmente o CIDv1 do arquivo enviado, sem um nó IPFS. Com
+// apiURL, o arquivo também é enviado e fixado no nó, e o CID devolvido pelo
+// nó precisa ser igual ao calculado. Uma falha apenas ao fixar vem em pinErr
+// e mantém o CID calculado.
+func fileIPFS(ctx context.Context, path string, uploaded localUpload, apiURL string) (cid string, pinErr, err error) {
+	App.Mutex.Lock()
+	state := *App
+	App.Mutex.Unlock()
+	err = withUploadedSource(path, uploaded, func(source string) (err error) {
+		if cid, err = ipfs.FileCID(ctx, source); err != nil || apiURL == "" {
+			return err
+		}
+		// Sem o Timeout do cliente padrão: o envio de arquivos grandes ao nó
+		// pode demorar, e o cancelamento é feito pelo contexto
+		client := &http.Client{Transport: state.HttpClient.Transport}
+		pinned, err := ipfs.Add(ctx, client, apiURL, source)
+		if err != nil {
+			pinErr = err
+		} else if pinned != cid {
+			pinErr = fmt.Errorf("o nó IPFS gerou o CID %s, diferente do calculado (%s)", pinned, cid)
+		} else {
+			log.Println("Arquivo fixado no nó IPFS", apiURL)
+		}
+		return ctx.Err()
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	log.Println("CID IPFS:", cid)
+	return cid, pinErr, nil
+}
+
+// compute calcula o CID do arquivo enviado, se a opção estiver marcada,
+// com um diálogo de progresso. onDone recebe "" se a opção estiver desmarcada
+// ou em caso de erro no cálculo, que é exibido ao usuário; uma falha apenas
+// ao fixar mantém o CID calculado.
+func (o *ipfsOptions) compute(win fyne.Window, path string, uploaded localUpload, onDone func(cid string)) {
+	if !o.enabled.Checked {
+		onDone("")
+		return
+	}
+	apiURL := o.pinAPI(win)
+
+	var cid string
+	var pinErr error
+	message := "Calculando o CID IPFS do arquivo..."
+	if apiURL != "" {
+		message = "Calculando o CID IPFS e fixando o arquivo no nó " + apiURL + "..."
+	}
+	runCancellable(win, "IPFS", message,
+		func(ctx context.Context) (err error) {
+			cid, pinErr, err = fileIPFS(ctx, path, uploaded, apiURL)
+			return err
+		},
+		func(err error) {
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("Erro ao calcular o CID IPFS: %w", err), win)
+				onDone("")
+				return
+			}
+			if pinErr != nil {
+				dialog.ShowError(fmt.Errorf("Erro ao fixar o arquivo no IPFS: %w", pinErr), win)
+			}
+			onDone(cid)
+		})
+}
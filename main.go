@@ -2,21 +2,43 @@ package main
 
 import (
 	"NostrFilePublisher/blossom"
+	"NostrFilePublisher/dtag"
+	"NostrFilePublisher/extract"
+	"NostrFilePublisher/ffmpeg"
+	"NostrFilePublisher/history"
 	"NostrFilePublisher/icons"
+	"NostrFilePublisher/imagemeta"
+	"NostrFilePublisher/ipfs"
+	"NostrFilePublisher/mediainfo"
 	"NostrFilePublisher/model"
+	"NostrFilePublisher/nip71"
+	"NostrFilePublisher/outbox"
+	"NostrFilePublisher/relay"
+	"NostrFilePublisher/remote"
+	"NostrFilePublisher/resize"
+	"NostrFilePublisher/sanitize"
+	"NostrFilePublisher/sniff"
+	"NostrFilePublisher/torrent"
 	"NostrFilePublisher/util"
+	"bytes"
 	"context"
+	"encoding/hex"
+	"errors"
 	"fmt"
-	"github.com/bbrks/go-blurhash"
 	fynetooltip "github.com/dweymouth/fyne-tooltip"
 	ttwidget "github.com/dweymouth/fyne-tooltip/widget"
 	"github.com/nbd-wtf/go-nostr"
 	"github.com/nbd-wtf/go-nostr/nip19"
 	"image"
+	"image/jpeg"
 	"io"
 	"log"
 	"net/http"
 	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -35,6 +57,15 @@ import (
 var App *model.AppState
 var myApp fyne.App
 
+// Outbox guarda eventos assinados e uploads pendentes até que sejam enviados.
+var Outbox *outbox.Queue
+
+// OutboxWorker repete periodicamente os envios pendentes do Outbox.
+var OutboxWorker *outbox.Worker
+
+// History registra os eventos publicados e em quais relays eles foram confirmados.
+var History *history.Store
+
 // main é o ponto de entrada da aplicação.
 func main() {
 	// Inicializa a aplicação Fyne
@@ -51,15 +82,24 @@ func main() {
 		// Inicializa com alguns relays e servidores de exemplo
 		BlossomServers: make(map[string]string),
 		Relays:         make(map[string]*model.RelayStatus),
+		RelaySets:      make(map[string][]string),
 		Mutex:          &sync.Mutex{},
 		UniqueID:       myApp.UniqueID(),
+		StripMetadata:  true,
+		DTagStrategy:   dtag.Timestamp,
+		IPFSAPI:        ipfs.DefaultAPI,
 	}
 	// Adiciona dados de exemplo
-	App.Relays["wss://relay.damus.io"] = &model.RelayStatus{URL: "wss://relay.damus.io", Status: "Desconectado"}
-	App.Relays["wss://relay.snort.social"] = &model.RelayStatus{URL: "wss://relay.snort.social", Status: "Desconectado"}
+	App.Relays["wss://relay.damus.io"] = &model.RelayStatus{URL: "wss://relay.damus.io", Status: "Desconectado", Read: true, Write: true}
+	App.Relays["wss://relay.snort.social"] = &model.RelayStatus{URL: "wss://relay.snort.social", Status: "Desconectado", Read: true, Write: true}
 
 	App.BlossomServers["https://nostr.media"] = "https://nostr.media"
 
+	// Inicializa o histórico de publicações, a fila de saída persistente e o
+	// worker que repete os envios pendentes
+	setupHistory()
+	setupOutbox()
+
 	// Configura o ícone e o menu da bandeja do sistema
 	setupSystemTray(myApp, myWindow)
 
@@ -68,6 +108,10 @@ func main() {
 		container.NewTabItem("Principal", mainScreen()),
 		container.NewTabItem("Vídeo", videoScreen(myWindow)),
 		container.NewTabItem("Arquivos", fileScreen(myWindow)),
+		container.NewTabItem("Fotos", photoScreen(myWindow)),
+		container.NewTabItem("Áudio", audioScreen(myWindow)),
+		container.NewTabItem("Fila", outboxScreen(myWindow)),
+		container.NewTabItem("Histórico", historyScreen(myWindow)),
 		container.NewTabItem("Configurações", settingsScreen(myWindow)),
 	)
 	tabs.SetTabLocation(container.TabLocationTop)
@@ -77,6 +121,249 @@ func main() {
 	myWindow.ShowAndRun()
 }
 
+// setupHistory abre o histórico de publicações no diretório de dados da aplicação.
+func setupHistory() {
+	path := filepath.Join(myApp.Storage().RootURI().Path(), "history.json")
+	store, err := history.Open(path)
+	if err != nil {
+		// Um arquivo corrompido é preservado para inspeção e o histórico recomeça vazio.
+		log.Println("Erro ao abrir o histórico:", err)
+		if err := os.Rename(path, path+".corrupt"); err != nil {
+			log.Println("Erro ao preservar o histórico corrompido:", err)
+		}
+		if store, err = history.Open(path); err != nil {
+			// Sem um arquivo utilizável, o histórico funciona só na memória nesta sessão
+			log.Println("Erro ao recriar o histórico, usando um histórico em memória:", err)
+			store = history.NewMemory()
+		}
+	}
+	History = store
+}
+
+// setupOutbox abre a fila de saída no diretório de dados da aplicação e inicia
+// o worker que repete os envios pendentes.
+func setupOutbox() {
+	path := filepath.Join(myApp.Storage().RootURI().Path(), "outbox.json")
+	q, err := outbox.Open(path)
+	if err != nil {
+		// Um arquivo corrompido é preservado para inspeção e a fila recomeça vazia.
+		log.Println("Erro ao abrir a fila de saída:", err)
+		if err := os.Rename(path, path+".corrupt"); err != nil {
+			log.Println("Erro ao preservar a fila corrompida:", err)
+		}
+		if q, err = outbox.Open(path); err != nil {
+			// Sem um arquivo utilizável, a fila funciona só na memória nesta sessão
+			log.Println("Erro ao recriar a fila de saída, usando uma fila em memória:", err)
+			q = outbox.NewMemory()
+		}
+	}
+	Outbox = q
+
+	OutboxWorker = outbox.NewWorker(Outbox, outbox.Handlers{
+		PublishEvent: func(evt nostr.Event, relayURL string) error {
+			if err := relay.PublishOne(evt, relayURL); err != nil {
+				return err
+			}
+			recordPublished(evt, relayURL)
+			return nil
+		},
+		Upload: func(preEvt model.PreEvent, server string) (*model.BlossomResponse, error) {
+			App.Mutex.Lock()
+			state := *App
+			App.Mutex.Unlock()
+			responses, errs := blossom.SendFileTo(state.HttpClient, preEvt, state, []string{server})
+			if len(errs) > 0 {
+				return nil, errs[0]
+			}
+			return &responses[0], nil
+		},
+		Sign: func(evt *nostr.Event) error {
+			App.Mutex.Lock()
+			nsec := App.Nsec
+			App.Mutex.Unlock()
+			// Outra chave publicaria o evento em nome de outro autor
+			if pubkey, err := nostr.GetPublicKey(nsec); err != nil || pubkey != evt.PubKey {
+				return fmt.Errorf("A chave atual não é a que assinou o evento.")
+			}
+			return evt.Sign(nsec)
+		},
+	})
+	go OutboxWorker.Run(context.Background(), time.Minute)
+}
+
+// queueFailedUploads coloca na fila de saída os servidores Blossom em que o
+// upload falhou e devolve o erro a ser exibido ao usuário. Para cada servidor
+// enfileirado é devolvida a URL em que o arquivo ficará disponível (BUD-01),
+// para que o evento possa ser preparado antes do fim do envio.
+func queueFailedUploads(preEvt model.PreEvent, errs []error) ([]model.BlossomResponse, error) {
+	var msgs, failed []string
+	for _, e := range errs {
+		msgs = append(msgs, e.Error())
+		var upErr *blossom.UploadError
+		if errors.As(e, &upErr) {
+			failed = append(failed, upErr.Server)
+		}
+	}
+	var pending []model.BlossomResponse
+	if len(failed) > 0 {
+		if err := Outbox.AddUpload(preEvt, failed); err != nil {
+			log.Println("Erro ao adicionar upload à fila:", err)
+		} else {
+			for _, server := range failed {
+				pending = append(pending, model.BlossomResponse{
+					URL:    blossom.BlobURL(server, preEvt.Sha256),
+					Sha256: preEvt.Sha256,
+					Size:   preEvt.Size,
+					Type:   preEvt.MimeType,
+				})
+			}
+			msgs = append(msgs, "\nOs envios que falharam foram adicionados à fila e serão repetidos automaticamente. Eventos com este arquivo podem aguardar na fila até que eles terminem.")
+		}
+	}
+	return pending, fmt.Errorf("Erros ao enviar para Blossom:\n%s", strings.Join(msgs, "\n"))
+}
+
+// discardTemporary apaga um arquivo temporário ou, se algum envio dele ficou
+// na fila de saída, deixa que a fila o apague quando o envio terminar.
+func discardTemporary(path string) {
+	adopted, err := Outbox.AdoptFile(path)
+	if err != nil {
+		log.Println("Erro ao gravar a fila de saída:", err)
+	}
+	if !adopted {
+		os.Remove(path)
+	}
+}
+
+// localUpload é o resultado do envio de um arquivo local.
+type localUpload struct {
+	// PreEvent descreve o arquivo enviado: depois da remoção de metadados, o
+	// hash e o tamanho são os da versão limpa, e não os do arquivo original.
+	PreEvent  model.PreEvent
+	Responses []model.BlossomResponse
+
+	// OriginalSha256 é o hash do arquivo original, antes de qualquer
+	// transformação (tag "ox" da NIP-94). É igual a PreEvent.Sha256 quando o
+	// arquivo é enviado sem alterações.
+	OriginalSha256 string
+
+	// Removed lista os metadados removidos da imagem antes do envio.
+	Removed []string
+
+	// Queued indica que algum servidor falhou e o envio ficou na fila de saída.
+	// Nesse caso, Responses também traz as URLs previstas nesses servidores.
+	Queued bool
+}
+
+// uploadLocalFile calcula o hash SHA-256 e o tamanho de um arquivo local e o
+// envia aos servidores Blossom configurados. Com a remoção de metadados
+// ativada, imagens JPEG, PNG e WebP são limpas antes do cálculo do hash.
+// Servidores que falharem vão para a fila de saída; um erro só é devolvido se
+// nenhum servidor aceitar o arquivo nem puder ser enfileirado.
+func uploadLocalFile(path, mimeType string) (localUpload, error) {
+	result := localUpload{PreEvent: model.PreEvent{Path: path, MimeType: mimeType, PrivKey: App.Nsec}}
+
+	App.Mutex.Lock()
+	state := *App
+	App.Mutex.Unlock()
+	if len(state.BlossomServers) == 0 {
+		return result, fmt.Errorf("Nenhum servidor Blossom configurado. Por favor, adicione um servidor na aba Configurações.")
+	}
+
+	// A cópia limpa da imagem fica com a fila de saída se algum envio ficar
+	// pendente, pois a fila precisa do arquivo para repetir o envio.
+	var cleanPath string
+	defer func() {
+		if cleanPath != "" {
+			discardTemporary(cleanPath)
+		}
+	}()
+	if state.StripMetadata && sanitize.Supported(mimeType) {
+		clean, report, err := sanitize.File(path)
+		if err != nil {
+			return result, fmt.Errorf("Erro ao remover os metadados da imagem: %w", err)
+		}
+		if clean != path {
+			cleanPath = clean
+			result.PreEvent.Path = clean
+			result.Removed = report.Removed
+			log.Println("Metadados removidos de", path+":", strings.Join(report.Removed, ", "))
+		}
+	}
+
+	var err error
+	result.PreEvent.Sha256, result.PreEvent.Size, err = util.HashFile(result.PreEvent.Path)
+	if err != nil {
+		return result, err
+	}
+	result.OriginalSha256 = result.PreEvent.Sha256
+	if cleanPath != "" {
+		if result.OriginalSha256, _, err = util.HashFile(path); err != nil {
+			return result, err
+		}
+	}
+
+	responses, errs := blossom.SendFile(state.HttpClient, result.PreEvent, state)
+	if len(errs) > 0 {
+		pending, uploadErr := queueFailedUploads(result.PreEvent, errs)
+		result.Queued = len(pending) > 0
+		responses = append(responses, pending...)
+		if len(responses) == 0 {
+			return result, uploadErr
+		}
+		log.Println(uploadErr)
+	}
+	result.Responses = responses
+	return result, nil
+}
+
+// withUploadedSource chama fn com o caminho de um arquivo idêntico ao que foi
+// enviado aos servidores Blossom. Se os metadados da imagem foram removidos
+// antes do envio, a cópia limpa é gerada de novo, conferida pelo hash e
+// apagada ao fim.
+func withUploadedSource(path string, uploaded localUpload, fn func(source string) error) error {
+	if uploaded.OriginalSha256 == uploaded.PreEvent.Sha256 {
+		return fn(path)
+	}
+	clean, _, err := sanitize.File(path)
+	if err != nil {
+		return err
+	}
+	if clean != path {
+		defer os.Remove(clean)
+	}
+	sha, _, err := util.HashFile(clean)
+	if err != nil {
+		return err
+	}
+	if sha != uploaded.PreEvent.Sha256 {
+		return fmt.Errorf("a cópia sem metadados não corresponde ao arquivo enviado")
+	}
+	return fn(clean)
+}
+
+// runCancellable executa fn em segundo plano exibindo um diálogo de progresso
+// com o botão "Cancelar", que cancela o contexto passado a fn. onDone é chamado
+// na thread da interface com o erro de fn, exceto quando o usuário cancela.
+func runCancellable(win fyne.Window, title, message string, fn func(ctx context.Context) error, onDone func(err error)) {
+	ctx, cancel := context.WithCancel(context.Background())
+	progress := dialog.NewCustom(title, "Cancelar",
+		container.NewVBox(widget.NewLabel(message), widget.NewProgressBarInfinite()), win)
+	progress.SetOnClosed(cancel)
+	progress.Show()
+
+	go func() {
+		err := fn(ctx)
+		fyne.Do(func() {
+			if errors.Is(ctx.Err(), context.Canceled) {
+				return
+			}
+			progress.Hide()
+			onDone(err)
+		})
+	}()
+}
+
 // setupSystemTray configura o ícone e o menu da bandeja do sistema.
 func setupSystemTray(a fyne.App, w fyne.Window) {
 	if desk, ok := a.(desktop.App); ok {
@@ -172,11 +459,16 @@ func mainScreen() fyne.CanvasObject {
 
 // videoScreen constrói a UI para upload e publicação de vídeos.
 func videoScreen(win fyne.Window) fyne.CanvasObject {
-	var fileBlossom []model.BlossomResponse
 	// Estados locais para a tela de vídeo
+	// variants guarda as versões (resoluções) do vídeo; a primeira é a principal.
+	var variants []nip71.Variant
+
+	// editing é o evento endereçável carregado para edição: a republicação
+	// mantém a sua tag "d" e as tags que o formulário não gera.
+	var editing *nostr.Event
 
 	preEvent := &model.PreEvent{
-		Kind:    nostr.KindShortVideoEvent,
+		Kind:    nip71.KindAddressableVideo,
 		PrivKey: App.Nsec,
 	}
 
@@ -194,11 +486,16 @@ func videoScreen(win fyne.Window) fyne.CanvasObject {
 	dateEntry := widget.NewDateEntry()
 	dateEntry.SetPlaceHolder("Data de Publicação")
 
-	imageEntry := widget.NewEntry()
-	imageEntry.SetPlaceHolder("URL da Imagem de Capa (image)")
+	coverInput := newImageInput(win, "URL da Imagem de Capa (image)")
+	thumbInput := newImageInput(win, "URL da Miniatura (thumb)")
 
-	thumbEntry := widget.NewEntry()
-	thumbEntry.SetPlaceHolder("URL da Miniatura (thumb)")
+	// Com o ffmpeg instalado, um quadro do próprio vídeo pode ser usado como capa e miniatura.
+	// pickFrameButton é configurado depois que as variantes são definidas.
+	pickFrameButton := widget.NewButton("Escolher Quadro do Vídeo", nil)
+	if !ffmpeg.Available() {
+		pickFrameButton.SetText("Escolher Quadro do Vídeo (ffmpeg não encontrado)")
+		pickFrameButton.Disable()
+	}
 
 	tagsLabel := widget.NewLabel("Tags (opcional):")
 	tagsOpenDialogButton := widget.NewButton("Adicionar", func() {
@@ -258,89 +555,115 @@ func videoScreen(win fyne.Window) fyne.CanvasObject {
 
 	fileSizeLabel := widget.NewLabel("Tamanho do Arquivo: (selecione um arquivo)")
 	bUrlsLabel := widget.NewLabel("Blossom URLs: (após upload)")
+	ipfsOpts := newIPFSOptions()
+	// onVariantsChanged é definida junto ao seletor de tipo de vídeo, mais abaixo.
+	var onVariantsChanged func()
+	refreshVariants := func() {
+		onVariantsChanged()
+		if len(variants) == 0 {
+			bUrlsLabel.SetText("Blossom URLs: (após upload)")
+			return
+		}
+		lines := make([]string, len(variants))
+		for i, v := range variants {
+			lines[i] = fmt.Sprintf("%d. %s", i+1, v.Label())
+		}
+		bUrlsLabel.SetText("Variantes do vídeo:\n" + strings.Join(lines, "\n"))
+	}
+	editVariantsButton := widget.NewButton("Editar Variantes", func() {
+		showVariantsDialog(win, &variants, refreshVariants)
+	})
+	legacyTagsCheck := widget.NewCheck("Incluir tags url/m/x/size/fallback no nível do evento (clientes antigos)", nil)
+	legacyTagsCheck.SetChecked(true)
 	eventOutput := widget.NewMultiLineEntry()
 	eventOutput.SetPlaceHolder("O evento Nostr gerado aparecerá aqui...")
 	eventOutput.Disable()
 
-	videoTypeEntry := widget.NewSelect([]string{"Vídeo Curto (Kind 34235)", "Vídeo Longo (Kind 34236)"}, func(selected string) {
-		if strings.Contains(selected, "Curto") {
-			preEvent.Kind = nostr.KindShortVideoEvent
-		} else {
-			preEvent.Kind = nostr.KindVideoEvent
+	// O modo automático escolhe o kind pela orientação e duração da variante
+	// principal; as demais opções fixam o kind escolhido pelo usuário.
+	autoKindLabel := widget.NewLabel("")
+	addressableCheck := widget.NewCheck("Endereçável (permite editar depois)", nil)
+	addressableCheck.SetChecked(true)
+	var videoTypeEntry *widget.Select
+	resolveKind := func() int {
+		if kind, ok := videoKindOptions[videoTypeEntry.Selected]; ok {
+			return kind
 		}
+		if len(variants) == 0 {
+			return nip71.ChooseKind(nip71.Variant{}, addressableCheck.Checked)
+		}
+		return nip71.ChooseKind(variants[0], addressableCheck.Checked)
+	}
+	updateAutoKind := func() {
+		preEvent.Kind = resolveKind()
+		if videoTypeEntry.Selected != videoKindAuto {
+			autoKindLabel.SetText("")
+			addressableCheck.Disable()
+			return
+		}
+		addressableCheck.Enable()
+		if len(variants) == 0 {
+			autoKindLabel.SetText("O kind será escolhido após selecionar o vídeo.")
+			return
+		}
+		autoKindLabel.SetText(fmt.Sprintf("Kind escolhido automaticamente: %d", preEvent.Kind))
+	}
+	videoTypeEntry = widget.NewSelect(videoKindLabels, func(selected string) {
+		updateAutoKind()
 		log.Println("Tipo de vídeo selecionado (Kind):", preEvent.Kind)
 	})
+	addressableCheck.OnChanged = func(bool) { updateAutoKind() }
+	onVariantsChanged = updateAutoKind
 	videoTypeEntry.SetSelectedIndex(0)
 
-	// --- Botões e Ações ---
-	var evt nostr.Event
-	defineManualUrlButton := widget.NewButton("Definir URL Manualmente", func() {
-		sha256Entry := widget.NewEntry()
-		sizeEntry := widget.NewEntry()
-		sha256Entry.SetPlaceHolder("b1674191a88ec5cdd733e4240a81803105dc412d6c6708d53ab94fc248f4f553")
-		sizeEntry.SetPlaceHolder("184292")
-		sha256Entry.Validator = func(s string) error {
-			if len(s) != 64 {
-				return fmt.Errorf("SHA-256 inválido, deve ter 64 caracteres")
+	pickFrameButton.OnTapped = func() {
+		// O ffmpeg lê tanto o arquivo local quanto a URL definida manualmente
+		input := preEvent.Path
+		var duration float64
+		if len(variants) > 0 {
+			duration = variants[0].Duration
+			if input == "" {
+				input = variants[0].URL
 			}
-			_, err := fmt.Sscanf(s, "%x", new([32]byte))
-			if err != nil {
-				return fmt.Errorf("SHA-256 inválido: %v", err)
-			}
-			return nil
 		}
-		sizeEntry.Validator = func(s string) error {
-			var size int64
-			_, err := fmt.Sscanf(s, "%d", &size)
-			if err != nil || size < 0 {
-				return fmt.Errorf("tamanho inválido, deve ser um número positivo")
-			}
-			preEvent.Size = size
-			return nil
-		}
-		urlEntry := widget.NewEntry()
-		urlEntry.SetPlaceHolder("https://example.com/meuvideo.mp4")
-		urlEntry.SetText(preEvent.Path) // Preenche com o caminho atual se existir
-		urlEntry.Validator = func(s string) error {
-			if s == "" {
-				return fmt.Errorf("a URL não pode estar vazia")
-			}
-			parsed, err := urlX.ParseRequestURI(s)
-			if err != nil || parsed.Scheme == "" || parsed.Host == "" {
-				return fmt.Errorf("URL inválida")
-			}
-			return nil
+		if input == "" {
+			dialog.ShowInformation("Atenção", "Por favor, selecione um arquivo de vídeo primeiro.", win)
+			return
 		}
-		urlSaveButton := widget.NewButton("Salvar", func() {
-			err := urlEntry.Validate()
-			if err != nil {
-				dialog.ShowError(err, win)
-				return
-			}
-			fileBlossom = append(fileBlossom, model.BlossomResponse{
-				URL: urlEntry.Text,
-			})
-
-			urlEntry.SetText("") // Limpa o campo após salvar
-			bUrlsLabel.SetText("Blossom Link definido manualmente:\n" + fileBlossom[0].URL)
+		showFramePicker(win, input, duration, func(frame image.Image) {
+			var img nip71.Image
+			runCancellable(win, "Enviando Quadro", "Enviando o quadro escolhido para os servidores Blossom...",
+				func(ctx context.Context) (err error) {
+					img, err = uploadFrame(ctx, frame)
+					return err
+				},
+				func(err error) {
+					if err != nil {
+						dialog.ShowError(err, win)
+						return
+					}
+					coverInput.Set(img)
+					thumbInput.Set(img)
+					log.Println("Quadro enviado:", img.URL, "BlurHash:", img.BlurHash)
+				})
+		})
+	}
 
-			mime, err := util.GetMimeFromUrl(App.HttpClient, fileBlossom[0].URL)
-			if err != nil {
-				log.Println("Erro ao detectar MIME da URL:", err)
-				mime = "application/octet-stream"
-			}
-			preEvent.MimeType = mime
+	// --- Botões e Ações ---
+	var evt nostr.Event
+	defineManualUrlButton := widget.NewButton("Definir URL Manualmente", func() {
+		showRemoteSourceDialog(win, "https://example.com/meuvideo.mp4", func(info remote.Info, fallbacks []string) {
+			preEvent.MimeType = info.MimeType
+			variants = append(variants, nip71.Variant{
+				URL:       info.URL,
+				MimeType:  info.MimeType,
+				Sha256:    info.Sha256,
+				Size:      info.Size,
+				Fallbacks: fallbacks,
+			})
+			refreshVariants()
 			dialog.ShowInformation("Sucesso", "URL definida com sucesso!", win)
 		})
-		dialog.NewCustom("Definir URL Manualmente", "Fechar", container.NewVBox(
-			container.NewVBox(widget.NewLabel("Digite a URL do vídeo:"), urlEntry),
-			widget.NewSeparator(),
-			container.NewVBox(widget.NewLabel("Digite o SHA-256 do arquivo:"), sha256Entry),
-			container.NewVBox(widget.NewLabel("Digite o tamanho do arquivo (em bytes):"), sizeEntry),
-
-			urlSaveButton,
-		), win).Show()
-		log.Println("Botão de definir URL manual clicado")
 	})
 	selectFileButton := widget.NewButton("Selecionar Arquivo de Vídeo", func() {
 		dialog.ShowFileOpen(func(file fyne.URIReadCloser, err error) {
@@ -373,16 +696,12 @@ func videoScreen(win fyne.Window) fyne.CanvasObject {
 			preEvent.Sha256 = fmt.Sprintf("%x", h.Sum(nil))
 			log.Println("Hash SHA-256 do arquivo:", preEvent.Sha256)
 
-			// Detecta o tipo MIME
-			f.Seek(0, 0) // Volta ao início do arquivo
-			buffer := make([]byte, 512)
-			_, err = f.Read(buffer)
-			if err != nil && err != io.EOF {
+			// Detecta o tipo MIME pelo conteúdo, com a extensão como alternativa
+			preEvent.MimeType, err = sniff.File(preEvent.Path)
+			if err != nil {
 				dialog.ShowError(err, win)
 				return
 			}
-
-			preEvent.MimeType = http.DetectContentType(buffer)
 			log.Println("Tipo MIME do arquivo:", preEvent.MimeType)
 
 			// Obtém o tamanho do arquivo
@@ -393,101 +712,134 @@ func videoScreen(win fyne.Window) fyne.CanvasObject {
 			}
 			preEvent.Size = stat.Size()
 
+			// Lê duração, dimensões e codecs diretamente do contêiner (MP4/WebM)
+			mediaInfo, err := mediainfo.ProbeReader(f, preEvent.Size)
+			if err != nil {
+				log.Println("Não foi possível ler os metadados do vídeo:", err)
+			} else {
+				log.Println("Metadados do vídeo:", mediaInfo)
+			}
+
 			if len(App.BlossomServers) >= 1 {
 				fBlossom, errs := blossom.SendFile(App.HttpClient, *preEvent, *App)
 				if len(errs) > 0 {
-					var errMsgs []string
-					for _, e := range errs {
-						errMsgs = append(errMsgs, e.Error())
+					pending, err := queueFailedUploads(*preEvent, errs)
+					dialog.ShowError(err, win)
+					fBlossom = append(fBlossom, pending...)
+					if len(fBlossom) == 0 {
+						return
 					}
-					dialog.ShowError(fmt.Errorf("Erros ao enviar para Blossom:\n%s", strings.Join(errMsgs, "\n")), win)
-					return
 				}
-				fileBlossom = fBlossom
+				// Cada arquivo enviado vira uma variante; as URLs extras são fallbacks.
+				variant := nip71.Variant{
+					URL:      fBlossom[0].URL,
+					MimeType: preEvent.MimeType,
+					Sha256:   preEvent.Sha256,
+					Size:     preEvent.Size,
+				}
+				if mediaInfo != nil {
+					variant.Dim = mediaInfo.Dim()
+					variant.Duration = mediaInfo.Duration
+				}
+				for _, f := range fBlossom[1:] {
+					variant.Fallbacks = append(variant.Fallbacks, f.URL)
+				}
+				variants = append(variants, variant)
+				log.Println("Variante adicionada:", variant.Label())
+				refreshVariants()
 			} else {
 				dialog.ShowInformation("Atenção", "Nenhum servidor Blossom configurado. Por favor, adicione um servidor na aba Configurações.", win)
 				return
 			}
-			//fBlossom
-			if len(fileBlossom) > 0 {
-				var fileURLs string
-				for _, f := range fileBlossom {
-					fileURLs += fmt.Sprintf("URL: %s\n", f.URL)
-				}
-
-				log.Println("URLs geradas pelo Blossom:", fileURLs)
-				bUrlsLabel.SetText("Blossom Link gerado:\n" + fileURLs)
+			sizeText := fmt.Sprintf("Tamanho: %d bytes | MIME: %s", preEvent.Size, preEvent.MimeType)
+			if mediaInfo != nil {
+				sizeText += "\n" + mediaInfo.String()
 			}
-			fileSizeLabel.SetText(fmt.Sprintf("Tamanho: %d bytes | MIME: %s", preEvent.Size, preEvent.MimeType))
+			fileSizeLabel.SetText(sizeText)
+
+			// O CID IPFS entra na variante do arquivo, que pode já ter sido removida
+			sha := preEvent.Sha256
+			ipfsOpts.compute(win, preEvent.Path, localUpload{PreEvent: *preEvent, OriginalSha256: sha}, func(cid string) {
+				if cid == "" {
+					return
+				}
+				ids, fbs := ipfsOpts.refs(cid)
+				for i := range variants {
+					if variants[i].Sha256 == sha && !slices.Contains(variants[i].Identifiers, ids[0]) {
+						variants[i].Identifiers = append(variants[i].Identifiers, ids...)
+						variants[i].Fallbacks = append(variants[i].Fallbacks, fbs...)
+					}
+				}
+				fileSizeLabel.SetText(sizeText + "\nCID IPFS: " + cid)
+				refreshVariants()
+			})
 		}, win)
 	})
 
-	generateEventButton := widget.NewButton("Gerar Evento", func() {
-		// Verifica se o arquivo foi selecionado ou se a URL foi definida manualmente
-		if len(fileBlossom) == 0 && preEvent.Path == "" {
-			dialog.ShowInformation("Atenção", "Por favor, selecione um arquivo primeiro.", win)
-			return
-		}
-		if App.Nsec == "" {
-			dialog.ShowInformation("Atenção", "Por favor, configure sua chave NSEC na aba de Configurações.", win)
-			return
+	// generateEvent monta e assina o evento. O BlurHash da pré-visualização já
+	// deve ter sido calculado, para que a tag faça parte do evento assinado.
+	generateEvent := func() {
+		// A miniatura tem prioridade; sem ela, a capa é usada como pré-visualização
+		cover, thumb := coverInput.image, thumbInput.image
+		preview := thumb
+		if preview.URL == "" {
+			preview = cover
 		}
+		preEvent.BlurHash = preview.BlurHash
 
 		// Monta as tags do evento Nostr
-		t := nostr.Tags{
-			nostr.Tag{"d", fmt.Sprintf("%s.%d", App.UniqueID, time.Now().Unix())},
-			nostr.Tag{"m", preEvent.MimeType},
-			nostr.Tag{"url", fileBlossom[0].URL},
+		preEvent.Kind = resolveKind()
+		var t nostr.Tags
+		if nip71.IsAddressable(preEvent.Kind) {
+			// Um evento em edição mantém a tag "d", substituindo a versão anterior
+			var d string
+			if editing != nil {
+				d = editing.Tags.GetD()
+			} else {
+				App.Mutex.Lock()
+				strategy := App.DTagStrategy
+				App.Mutex.Unlock()
+				var err error
+				d, err = dtag.Generate(strategy, dtag.Input{
+					Prefix: App.UniqueID,
+					Title:  strings.TrimSpace(titleEntry.Text),
+					Sha256: variants[0].Sha256,
+					Now:    time.Now(),
+				})
+				if err != nil {
+					dialog.ShowError(fmt.Errorf("Erro ao gerar a tag d: %w", err), win)
+					return
+				}
+			}
+			t = append(t, nostr.Tag{"d", d})
+		}
+		// Uma tag imeta por variante (NIP-71), com a imagem de capa como pré-visualização
+		tagVariants := make([]nip71.Variant, len(variants))
+		for i, v := range variants {
+			if cover.URL != "" {
+				v.Images = cover.URLs()
+			}
+			v.BlurHash = preview.BlurHash
+			tagVariants[i] = v
 		}
+		t = append(t, nip71.Tags(tagVariants, legacyTagsCheck.Checked)...)
 		for _, r := range App.Relays {
 			t = append(t, nostr.Tag{"r", r.URL})
 		}
-		if preEvent.Sha256 != "" {
-			t = append(t, nostr.Tag{"x", preEvent.Sha256})
-		}
-		if preEvent.Size > 0 {
-			t = append(t, nostr.Tag{"size", fmt.Sprintf("%d", preEvent.Size)})
-		}
-
-		if len(fileBlossom) > 1 {
-			for _, f := range fileBlossom[1:] {
-				t = append(t, nostr.Tag{"fallback", f.URL})
-			}
-		}
 		if titleEntry.Text != "" {
 			t = append(t, nostr.Tag{"title", titleEntry.Text})
 		}
 		if summaryEntry.Text != "" {
 			t = append(t, nostr.Tag{"summary", summaryEntry.Text})
 		}
-		if imageEntry.Text != "" {
-			t = append(t, nostr.Tag{"image", imageEntry.Text})
+		if cover.URL != "" {
+			t = append(t, cover.Tag("image"))
 		}
-		if thumbEntry.Text != "" {
-			t = append(t, nostr.Tag{"thumb", thumbEntry.Text})
-			// FUNCIONALIDADE IMPLEMENTADA: Geração de BlurHash a partir da URL da thumbnail
-			go func() {
-				resp, err := App.HttpClient.Get(thumbEntry.Text)
-				if err != nil {
-					log.Println("Erro ao buscar thumbnail:", err)
-					return
-				}
-				defer resp.Body.Close()
-				img, _, err := image.Decode(resp.Body)
-				if err != nil {
-					log.Println("Erro ao decodificar imagem da thumbnail:", err)
-					return
-				}
-				hash, err := blurhash.Encode(4, 3, img)
-				if err != nil {
-					log.Println("Erro ao gerar BlurHash:", err)
-					return
-				}
-
-				preEvent.BlurHash = hash
-				log.Println("BlurHash gerado:", preEvent.BlurHash)
-				// A tag será adicionada antes de publicar
-			}()
+		if thumb.URL != "" {
+			t = append(t, thumb.Tag("thumb"))
+		}
+		if preEvent.BlurHash != "" {
+			t = append(t, nostr.Tag{"blurhash", preEvent.BlurHash})
 		}
 		if dateEntry.Text != "" {
 			// Adiciona a data de publicação no formato ISO 8601
@@ -497,7 +849,18 @@ func videoScreen(win fyne.Window) fyne.CanvasObject {
 				dialog.ShowError(err, win)
 				return
 			}
-			t = append(t, nostr.Tag{"published_at", fmt.Sprintf("%d", publishedAt.Unix())})
+			value := fmt.Sprintf("%d", publishedAt.Unix())
+			// Na edição, o published_at original é mantido se o dia não mudou,
+			// preservando o horário da primeira publicação
+			if editing != nil && dateEntry.Date != nil {
+				if orig := editing.Tags.Find("published_at"); orig != nil {
+					if sec, err := strconv.ParseInt(orig[1], 10, 64); err == nil &&
+						time.Unix(sec, 0).Format(time.DateOnly) == dateEntry.Date.Format(time.DateOnly) {
+						value = orig[1]
+					}
+				}
+			}
+			t = append(t, nostr.Tag{"published_at", value})
 		}
 
 		for _, tag := range preEvent.Tags {
@@ -515,6 +878,13 @@ func videoScreen(win fyne.Window) fyne.CanvasObject {
 		if preEvent.Nsfw {
 			t = append(t, nostr.Tag{"content-warning"})
 		}
+		if editing != nil {
+			for _, tag := range editing.Tags {
+				if len(tag) > 0 && !slices.Contains(videoFormTags, tag[0]) {
+					t = append(t, tag)
+				}
+			}
+		}
 
 		// Cria o evento Nostr
 		evt = nostr.Event{
@@ -534,120 +904,485 @@ func videoScreen(win fyne.Window) fyne.CanvasObject {
 		eventOutput.SetText(evt.String())
 		eventOutput.Enable()
 		dialog.ShowInformation("Sucesso", "Evento gerado com sucesso!", win)
-	})
+	}
 
-	publishEventButton := widget.NewButton("Publicar Evento", func() {
-		if evt.ID == "" {
-			dialog.ShowInformation("Atenção", "Por favor, gere o evento primeiro.", win)
+	generateEventButton := widget.NewButton("Gerar Evento", func() {
+		// Verifica se o arquivo foi selecionado ou se a URL foi definida manualmente
+		if len(variants) == 0 {
+			dialog.ShowInformation("Atenção", "Por favor, selecione um arquivo primeiro.", win)
 			return
 		}
-		// Adiciona a tag blurhash se foi gerada
-		if preEvent.BlurHash != "" {
-			evt.Tags = append(evt.Tags, nostr.Tag{"blurhash", preEvent.BlurHash})
+		if App.Nsec == "" {
+			dialog.ShowInformation("Atenção", "Por favor, configure sua chave NSEC na aba de Configurações.", win)
+			return
 		}
 
-		// FUNCIONALIDADE IMPLEMENTADA: Diálogo de status da publicação.
-		// Mostra uma lista de relays e o resultado do envio para cada um.
-		statusMap := make(map[string]string)
-		var wg sync.WaitGroup
+		// Imagens informadas por URL ainda não têm BlurHash: a imagem é baixada
+		// antes de gerar o evento, com a opção de cancelar.
+		target := &thumbInput.image
+		if target.URL == "" {
+			target = &coverInput.image
+		}
+		if target.URL == "" || target.BlurHash != "" {
+			generateEvent()
+			return
+		}
+		url := target.URL
 		App.Mutex.Lock()
-		for url := range App.Relays {
-			wg.Add(1)
-			go func(relayURL string) {
-				defer wg.Done()
-				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-				defer cancel()
-				relay, err := nostr.RelayConnect(ctx, relayURL)
-				if err != nil {
-					statusMap[relayURL] = "Falha ao conectar"
-					return
-				}
-				err = relay.Publish(ctx, evt)
+		client := App.HttpClient
+		App.Mutex.Unlock()
+		var meta imagemeta.Meta
+		runCancellable(win, "Calculando BlurHash", "Baixando a imagem de pré-visualização...",
+			func(ctx context.Context) (err error) {
+				meta, err = imagemeta.Fetch(ctx, client, url)
+				return err
+			},
+			func(err error) {
 				if err != nil {
-					statusMap[relayURL] = fmt.Sprintf("Falha: %v", err)
-				} else {
-					statusMap[relayURL] = "Sucesso"
+					// Sem BlurHash o evento continua válido; a falha é apenas registrada
+					log.Println("Erro ao gerar BlurHash:", err)
+				} else if target.URL == url {
+					target.BlurHash, target.Dim = meta.BlurHash, meta.Dim()
 				}
-				relay.Close()
-			}(url)
+				generateEvent()
+			})
+	})
+
+	publishEventButton := widget.NewButton("Publicar Evento", func() {
+		if evt.ID == "" {
+			dialog.ShowInformation("Atenção", "Por favor, gere o evento primeiro.", win)
+			return
+		}
+		showPublishDialog(win, evt)
+	})
+	queueEventButton := widget.NewButton("Adicionar à Fila", func() {
+		if evt.ID == "" {
+			dialog.ShowInformation("Atenção", "Por favor, gere o evento primeiro.", win)
+			return
 		}
+		App.Mutex.Lock()
+		writeRelays := App.WriteRelays()
 		App.Mutex.Unlock()
-		wg.Wait()
-
-		// Cria a UI para o diálogo de resultados
-		var resultsData []string
-		for k, v := range statusMap {
-			resultsData = append(resultsData, fmt.Sprintf("%s: %s", k, v))
-		}
-		resultsList := widget.NewList(
-			func() int { return len(resultsData) },
-			func() fyne.CanvasObject { return widget.NewLabel("") },
-			func(i widget.ListItemID, o fyne.CanvasObject) {
-				o.(*widget.Label).SetText(resultsData[i])
-			},
-		)
-		resultDialog := dialog.NewCustom("Resultado da Publicação", "Fechar", container.NewScroll(resultsList), win)
-		resultDialog.Resize(fyne.NewSize(400, 300))
-		resultDialog.Show()
+		// O evento espera pelos envios do vídeo e das imagens que ainda estão na fila
+		uploads := Outbox.PendingUploads(evt)
+		if err := Outbox.AddEventAfter(evt, writeRelays, uploads); err != nil {
+			dialog.ShowError(fmt.Errorf("Erro ao adicionar o evento à fila: %w", err), win)
+			return
+		}
+		msg := "Evento adicionado à fila. Ele será publicado quando os relays estiverem acessíveis."
+		if len(uploads) > 0 {
+			msg = fmt.Sprintf("Evento adicionado à fila. Ele será publicado depois que %d envio(s) pendente(s) terminarem.", len(uploads))
+		}
+		dialog.ShowInformation("Sucesso", msg, win)
 	})
-	resetFormButton := widget.NewButton("Limpar Formulário", func() {
-		fileBlossom = nil
+	editLabel := widget.NewLabel("")
+	editLabel.Wrapping = fyne.TextWrapWord
+	editLabel.Hide()
+	resetForm := func() {
+		variants = nil
+		editing = nil
+		editLabel.Hide()
 		titleEntry.SetText("")
 		summaryEntry.SetText("")
 		descriptionEntry.SetText("")
 		tagsLabel.SetText("Tags (opcional):")
 		preEvent = &model.PreEvent{
-			Kind:    nostr.KindShortVideoEvent,
+			Kind:    nip71.KindAddressableVideo,
 			PrivKey: App.Nsec,
 		}
+		addressableCheck.SetChecked(true)
 		videoTypeEntry.SetSelectedIndex(0)
-		imageEntry.SetText("")
-		thumbEntry.SetText("")
+		coverInput.Reset()
+		thumbInput.Reset()
 
 		dateEntry.SetDate(nil)
 		dateEntry.SetValidationError(nil)
 		//dateEntry é opcional, deve ser limpo, mas não obrigatório
 		nsfwCheck.SetChecked(false)
 		fileSizeLabel.SetText("Tamanho do Arquivo: (selecione um arquivo)")
-		bUrlsLabel.SetText("Blossom URLs: (após upload)")
+		refreshVariants()
+		legacyTagsCheck.SetChecked(true)
 		eventOutput.SetText("")
 		eventOutput.Disable()
+		indexersLabel.SetText("Indexadores (opcional):")
+		evt = nostr.Event{}
 		titleEntry.FocusGained()
+	}
+	resetFormButton := widget.NewButton("Limpar Formulário", resetForm)
+
+	// loadEvent preenche o formulário com um vídeo endereçável já publicado,
+	// para que ele seja alterado e republicado com a mesma tag "d".
+	loadEvent := func(loaded nostr.Event) {
+		resetForm()
+		editing = &loaded
+		tags := loaded.Tags
+
+		for label, kind := range videoKindOptions {
+			if kind == loaded.Kind {
+				videoTypeEntry.SetSelected(label)
+			}
+		}
+		if tag := tags.Find("title"); tag != nil {
+			titleEntry.SetText(tag[1])
+		}
+		if tag := tags.Find("summary"); tag != nil {
+			summaryEntry.SetText(tag[1])
+		}
+		descriptionEntry.SetText(loaded.Content)
+		for _, tag := range tags {
+			if len(tag) < 2 {
+				continue
+			}
+			switch tag[0] {
+			case "t":
+				preEvent.Tags = append(preEvent.Tags, tag[1])
+			case "i":
+				preEvent.Indexers = append(preEvent.Indexers, tag[1])
+			}
+		}
+		if len(preEvent.Tags) > 0 {
+			tagsLabel.SetText("Tags: " + strings.Join(preEvent.Tags, ", "))
+		}
+		if len(preEvent.Indexers) > 0 {
+			indexersLabel.SetText("Indexadores: " + strings.Join(preEvent.Indexers, ", "))
+		}
+		nsfwCheck.SetChecked(slices.ContainsFunc(tags, func(tag nostr.Tag) bool {
+			return len(tag) > 0 && tag[0] == "content-warning"
+		}))
+		if tag := tags.Find("published_at"); tag != nil {
+			if sec, err := strconv.ParseInt(tag[1], 10, 64); err == nil {
+				date := time.Unix(sec, 0)
+				dateEntry.SetDate(&date)
+			}
+		}
+		legacyTagsCheck.SetChecked(tags.Find("url") != nil)
+
+		variants = nip71.ParseVariants(tags)
+		var primary nip71.Variant
+		if len(variants) > 0 {
+			primary = variants[0]
+			preEvent.MimeType = primary.MimeType
+		}
+		// As imagens da imeta são regeneradas a partir da capa ao gerar o evento
+		for i := range variants {
+			variants[i].Images, variants[i].BlurHash = nil, ""
+		}
+		cover := nip71.ParseImage(tags.Find("image"), primary)
+		thumb := nip71.ParseImage(tags.Find("thumb"), primary)
+		blurHash := primary.BlurHash
+		if tag := tags.Find("blurhash"); tag != nil {
+			blurHash = tag[1]
+		}
+		if thumb.URL != "" {
+			thumb.BlurHash = blurHash
+		} else {
+			cover.BlurHash = blurHash
+		}
+		coverInput.Set(cover)
+		thumbInput.Set(thumb)
+		refreshVariants()
+
+		fileSizeLabel.SetText(fmt.Sprintf("Evento carregado para edição (%d variante(s)).", len(variants)))
+		editLabel.SetText(fmt.Sprintf("Editando o vídeo endereçável kind %d com d=%q. Ao publicar, a versão anterior será substituída.",
+			loaded.Kind, loaded.Tags.GetD()))
+		editLabel.Show()
+		log.Println("Evento carregado para edição:", loaded.ID)
+	}
+	editPublishedButton := widget.NewButton("Editar Publicado", func() {
+		showLoadVideoDialog(win, loadEvent)
 	})
 
 	// --- Layout da Tela ---
 	form := &widget.Form{
 		Items: []*widget.FormItem{
-			{Text: "Tipo de Vídeo", Widget: videoTypeEntry},
+			{Text: "Tipo de Vídeo", Widget: container.NewVBox(videoTypeEntry, addressableCheck, autoKindLabel)},
 			{Text: "Título", Widget: titleEntry},
 			{Text: "Resumo", Widget: summaryEntry},
 			{Text: "Tags", Widget: container.NewHBox(tagsLabel, tagsOpenDialogButton)},
 			{Text: "NSFW", Widget: nsfwCheck},
-			{Text: "URL Imagem", Widget: imageEntry},
-			{Text: "URL Thumbnail", Widget: thumbEntry},
+			{Text: "URL Imagem", Widget: coverInput.widget},
+			{Text: "URL Thumbnail", Widget: thumbInput.widget},
+			{Text: "Quadro do Vídeo", Widget: pickFrameButton},
 			{Text: "Data de Publicação", Widget: dateEntry},
 			{Text: "Indexadores", Widget: container.NewHBox(fynetooltip.AddWindowToolTipLayer(indexersLabel, win.Canvas()), indexerButton)},
+			{Text: "IPFS", Widget: ipfsOpts.widget()},
+			{Text: "Compatibilidade", Widget: legacyTagsCheck},
 		},
 	}
 
-	inputContainer := container.NewVBox(
-		container.NewCenter(container.NewHBox(selectFileButton, defineManualUrlButton)),
-		fileSizeLabel,
-		bUrlsLabel,
-		widget.NewSeparator(),
-		form,
-		widget.NewLabel("Descrição"),
-		descriptionEntry,
-	)
+	inputContainer := container.NewVBox(
+		container.NewCenter(container.NewHBox(selectFileButton, defineManualUrlButton, editPublishedButton)),
+		editLabel,
+		fileSizeLabel,
+		container.NewBorder(nil, nil, nil, editVariantsButton, bUrlsLabel),
+		widget.NewSeparator(),
+		form,
+		widget.NewLabel("Descrição"),
+		descriptionEntry,
+	)
+
+	actionsContainer := container.NewVBox(
+		container.NewCenter(container.NewHBox(generateEventButton, resetFormButton, publishEventButton, queueEventButton)),
+		widget.NewSeparator(),
+		widget.NewLabelWithStyle("Evento Gerado", fyne.TextAlignCenter, fyne.TextStyle{Bold: true}),
+		eventOutput,
+	)
+
+	return container.NewBorder(nil, actionsContainer, nil, nil, container.NewScroll(inputContainer))
+}
+
+// uploadFrame calcula o BlurHash e as dimensões do quadro, codifica-o em JPEG
+// e o envia aos servidores Blossom.
+func uploadFrame(ctx context.Context, frame image.Image) (nip71.Image, error) {
+	meta, err := imagemeta.FromImage(ctx, frame)
+	if err != nil {
+		return nip71.Image{}, err
+	}
+
+	tmp, err := os.CreateTemp("", "frame-*.jpg")
+	if err != nil {
+		return nip71.Image{}, err
+	}
+	if err := jpeg.Encode(tmp, frame, &jpeg.Options{Quality: 90}); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return nip71.Image{}, fmt.Errorf("Erro ao codificar o quadro: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return nip71.Image{}, err
+	}
+	// O quadro é codificado aqui, sem metadados a remover
+	img, _, err := uploadImage(tmp.Name(), "image/jpeg", meta)
+	discardTemporary(tmp.Name())
+	return img, err
+}
+
+// uploadImage envia uma imagem local aos servidores Blossom. A primeira URL
+// devolvida é a principal; as demais são registradas como alternativas. O
+// envio é devolvido junto para que o chamador saiba o que foi removido e se
+// algum servidor ficou na fila de saída.
+func uploadImage(path, mimeType string, meta imagemeta.Meta) (nip71.Image, localUpload, error) {
+	upload, err := uploadLocalFile(path, mimeType)
+	if err != nil {
+		return nip71.Image{}, upload, err
+	}
+	responses := upload.Responses
+	img := nip71.Image{
+		URL:      responses[0].URL,
+		Sha256:   upload.PreEvent.Sha256,
+		BlurHash: meta.BlurHash,
+		Dim:      meta.Dim(),
+	}
+	for _, resp := range responses[1:] {
+		if resp.URL != "" && resp.URL != img.URL {
+			img.Fallbacks = append(img.Fallbacks, resp.URL)
+		}
+	}
+	return img, upload, nil
+}
+
+// uploadRenditions gera as variantes responsivas e a miniatura de uma imagem
+// local e as envia aos servidores Blossom. As variantes são devolvidas da
+// menor para a maior, prontas para as tags imeta.
+func uploadRenditions(ctx context.Context, path string) ([]nip71.Variant, nip71.Image, error) {
+	var thumb nip71.Image
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, thumb, err
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, thumb, fmt.Errorf("Erro ao decodificar a imagem: %w", err)
+	}
+	// As variantes não têm EXIF, então a orientação é aplicada aos pixels
+	img = resize.Orient(img, sanitize.Orientation(data))
+
+	renditions, err := resize.Renditions(ctx, img, resize.DefaultWidths)
+	if err != nil {
+		return nil, thumb, err
+	}
+
+	var variants []nip71.Variant
+	for _, r := range renditions {
+		if err := ctx.Err(); err != nil {
+			return nil, thumb, err
+		}
+		upload, err := uploadRendition(r)
+		if err != nil {
+			return nil, thumb, fmt.Errorf("Erro ao enviar a variante %s: %w", r.Dim(), err)
+		}
+		urls := make([]string, 0, len(upload.Responses))
+		for _, resp := range upload.Responses {
+			urls = append(urls, resp.URL)
+		}
+		if r.Thumb {
+			thumb = nip71.Image{URL: urls[0], Sha256: upload.PreEvent.Sha256, Fallbacks: urls[1:], Dim: r.Dim()}
+			continue
+		}
+		variants = append(variants, nip71.Variant{
+			URL:       urls[0],
+			MimeType:  r.MimeType,
+			Sha256:    upload.PreEvent.Sha256,
+			Size:      upload.PreEvent.Size,
+			Dim:       r.Dim(),
+			Fallbacks: urls[1:],
+		})
+	}
+	return variants, thumb, nil
+}
+
+// uploadRendition grava a variante em um arquivo temporário e o envia. O
+// arquivo fica com a fila de saída se o envio ficar pendente.
+func uploadRendition(r resize.Rendition) (localUpload, error) {
+	ext := ".jpg"
+	if r.MimeType == "image/png" {
+		ext = ".png"
+	}
+	tmp, err := os.CreateTemp("", "rendition-*"+ext)
+	if err != nil {
+		return localUpload{}, err
+	}
+	if _, err := tmp.Write(r.Data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return localUpload{}, err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return localUpload{}, err
+	}
+
+	upload, err := uploadLocalFile(tmp.Name(), r.MimeType)
+	discardTemporary(tmp.Name())
+	return upload, err
+}
+
+// removedMetadataText descreve os metadados removidos de uma imagem antes do envio.
+func removedMetadataText(removed []string) string {
+	if len(removed) == 0 {
+		return ""
+	}
+	return "Metadados removidos antes do envio: " + strings.Join(removed, ", ") + " (orientação preservada)"
+}
+
+// videoKindAuto é a opção do seletor de tipo que escolhe o kind automaticamente.
+const videoKindAuto = "Automático (pela orientação e duração)"
 
-	actionsContainer := container.NewVBox(
-		container.NewCenter(container.NewHBox(generateEventButton, resetFormButton, publishEventButton)),
-		widget.NewSeparator(),
-		widget.NewLabelWithStyle("Evento Gerado", fyne.TextAlignCenter, fyne.TextStyle{Bold: true}),
-		eventOutput,
+// videoKindLabels são as opções do seletor de tipo de vídeo, na ordem exibida.
+var videoKindLabels = []string{
+	videoKindAuto,
+	"Vídeo (Kind 21)",
+	"Vídeo Curto (Kind 22)",
+	"Vídeo Endereçável (Kind 34235)",
+	"Vídeo Curto Endereçável (Kind 34236)",
+}
+
+// videoKindOptions associa as opções fixas do seletor de tipo ao kind correspondente.
+var videoKindOptions = map[string]int{
+	videoKindLabels[1]: nip71.KindVideo,
+	videoKindLabels[2]: nip71.KindShortVideo,
+	videoKindLabels[3]: nip71.KindAddressableVideo,
+	videoKindLabels[4]: nip71.KindAddressableShortVideo,
+}
+
+// showVariantsDialog permite editar as dimensões e a duração de cada variante
+// do vídeo, ou removê-la. onChange é chamado após qualquer alteração.
+func showVariantsDialog(win fyne.Window, variants *[]nip71.Variant, onChange func()) {
+	if len(*variants) == 0 {
+		dialog.ShowInformation("Atenção", "Nenhuma variante adicionada. Selecione um arquivo de vídeo primeiro.", win)
+		return
+	}
+
+	selected := -1
+	dimEntry := widget.NewEntry()
+	dimEntry.SetPlaceHolder("1920x1080")
+	durationEntry := widget.NewEntry()
+	durationEntry.SetPlaceHolder("Duração em segundos (ex: 29.5)")
+	mimeEntry := widget.NewSelectEntry(sniff.Common)
+	mimeEntry.SetPlaceHolder("video/mp4")
+
+	list := widget.NewList(
+		func() int { return len(*variants) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			o.(*widget.Label).SetText(fmt.Sprintf("%d. %s", i+1, (*variants)[i].Label()))
+		},
 	)
+	list.OnSelected = func(id widget.ListItemID) {
+		selected = id
+		v := (*variants)[id]
+		dimEntry.SetText(v.Dim)
+		mimeEntry.SetText(v.MimeType)
+		durationEntry.SetText("")
+		if v.Duration > 0 {
+			durationEntry.SetText(nip71.FormatDuration(v.Duration))
+		}
+	}
 
-	return container.NewBorder(nil, actionsContainer, nil, nil, container.NewScroll(inputContainer))
+	saveButton := widget.NewButton("Salvar", func() {
+		if selected < 0 {
+			dialog.ShowInformation("Atenção", "Selecione uma variante.", win)
+			return
+		}
+		v := &(*variants)[selected]
+		if err := validateMime(mimeEntry.Text); err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		v.MimeType = strings.TrimSpace(mimeEntry.Text)
+		if dim := strings.TrimSpace(dimEntry.Text); dim != "" {
+			w, h, err := nip71.ParseDim(dim)
+			if err != nil {
+				dialog.ShowError(err, win)
+				return
+			}
+			v.Dim = fmt.Sprintf("%dx%d", w, h)
+		} else {
+			v.Dim = ""
+		}
+		v.Duration = 0
+		if d := strings.TrimSpace(durationEntry.Text); d != "" {
+			duration, err := strconv.ParseFloat(d, 64)
+			if err != nil || duration < 0 {
+				dialog.ShowError(fmt.Errorf("duração inválida: %s", d), win)
+				return
+			}
+			v.Duration = duration
+		}
+		list.Refresh()
+		onChange()
+	})
+	removeButton := widget.NewButton("Remover", func() {
+		if selected < 0 {
+			dialog.ShowInformation("Atenção", "Selecione uma variante.", win)
+			return
+		}
+		*variants = append((*variants)[:selected], (*variants)[selected+1:]...)
+		selected = -1
+		list.UnselectAll()
+		list.Refresh()
+		dimEntry.SetText("")
+		mimeEntry.SetText("")
+		durationEntry.SetText("")
+		onChange()
+	})
+
+	d := dialog.NewCustom("Variantes do Vídeo", "Fechar", container.NewBorder(
+		nil,
+		container.NewVBox(
+			widget.NewForm(
+				widget.NewFormItem("Tipo MIME", mimeEntry),
+				widget.NewFormItem("Dimensões", dimEntry),
+				widget.NewFormItem("Duração (s)", durationEntry),
+			),
+			container.NewHBox(saveButton, removeButton),
+		),
+		nil, nil,
+		list,
+	), win)
+	d.Resize(fyne.NewSize(600, 400))
+	d.Show()
 }
 
 func fileScreen(win fyne.Window) fyne.CanvasObject {
@@ -657,6 +1392,17 @@ func fileScreen(win fyne.Window) fyne.CanvasObject {
 	}
 	var evt nostr.Event
 	var fileBlossom []model.BlossomResponse
+	// imageVariants são as versões redimensionadas de imagens enviadas
+	var imageVariants []nip71.Variant
+	// originalSha256 é o hash do arquivo antes da remoção de metadados (tag "ox")
+	var originalSha256 string
+	// autoThumbURL é a miniatura gerada para o arquivo atual, substituída ao trocar de arquivo
+	var autoThumbURL string
+	// fileTorrent é o torrent gerado para o arquivo atual, cujo link magnet e
+	// infohash preenchem os campos correspondentes
+	var fileTorrent *torrent.Torrent
+	// ipfsCID é o CIDv1 do arquivo atual, publicado como "i" e, opcionalmente, como fallback ipfs://
+	var ipfsCID string
 
 	// --- Widgets da UI ---
 	titleEntry := widget.NewEntry()
@@ -671,6 +1417,49 @@ func fileScreen(win fyne.Window) fyne.CanvasObject {
 	descriptionEntry := widget.NewMultiLineEntry()
 	descriptionEntry.SetPlaceHolder("Descrição detalhada...")
 
+	altEntry := widget.NewEntry()
+	altEntry.SetPlaceHolder("Descrição acessível do arquivo (alt)...")
+
+	// Imagem de pré-visualização e miniatura (NIP-94 "image" e "thumb"). A
+	// miniatura é preenchida automaticamente quando as variantes de imagem são geradas.
+	coverInput := newImageInput(win, "URL da Imagem de Pré-visualização (image)")
+	thumbInput := newImageInput(win, "URL da Miniatura (thumb)")
+
+	magnetEntry := widget.NewEntry()
+	magnetEntry.SetPlaceHolder("magnet:?xt=urn:btih:...")
+	magnetEntry.Validator = func(s string) error {
+		if s != "" && !strings.HasPrefix(s, "magnet:?") {
+			return fmt.Errorf("link magnet inválido, deve começar com magnet:?")
+		}
+		return nil
+	}
+
+	infohashEntry := widget.NewEntry()
+	infohashEntry.SetPlaceHolder("Infohash do torrent (40 ou 64 caracteres hexadecimais)")
+	infohashEntry.Validator = func(s string) error {
+		if s == "" {
+			return nil
+		}
+		if _, err := hex.DecodeString(s); err != nil || (len(s) != 40 && len(s) != 64) {
+			return fmt.Errorf("infohash inválido, use 40 (v1) ou 64 (v2) caracteres hexadecimais")
+		}
+		return nil
+	}
+
+	// serviceEntry é preenchido com "blossom" quando o arquivo é enviado pelo aplicativo
+	serviceEntry := widget.NewSelectEntry([]string{"blossom", "nip96"})
+	serviceEntry.SetPlaceHolder("Serviço de armazenamento (ex: blossom, nip96)")
+
+	// O tipo detectado pode ser corrigido pelo usuário antes de publicar
+	mimeEntry := widget.NewSelectEntry(sniff.Common)
+	mimeEntry.SetPlaceHolder("Tipo MIME (detectado ao selecionar o arquivo)")
+	mimeEntry.Validator = validateMime
+	mimeEntry.OnChanged = func(s string) {
+		if validateMime(s) == nil {
+			preEvent.MimeType = strings.TrimSpace(s)
+		}
+	}
+
 	tagsLabel := widget.NewLabel("Tags (opcional):")
 	tagsOpenDialogButton := widget.NewButton("Adicionar", func() {
 		newTag := widget.NewEntry()
@@ -729,10 +1518,207 @@ func fileScreen(win fyne.Window) fyne.CanvasObject {
 
 	fileSizeLabel := widget.NewLabel("Tamanho do Arquivo: (selecione um arquivo)")
 
+	responsiveCheck := widget.NewCheck(fmt.Sprintf("Gerar variantes de imagem (%s px de largura e miniatura)", joinInts(resize.DefaultWidths)), nil)
+	responsiveCheck.SetChecked(true)
+
+	torrentCheck := widget.NewCheck("Gerar torrent (v1 e v2) com os servidores Blossom como web seeds", nil)
+	torrentButton := widget.NewButton("Torrent...", func() {
+		if fileTorrent == nil {
+			dialog.ShowInformation("Atenção", "Marque a opção de torrent e selecione um arquivo local.", win)
+			return
+		}
+		showTorrentDialog(win, fileTorrent, titleEntry.Text, descriptionEntry.Text, preEvent.Tags, func() {
+			magnetEntry.SetText(fileTorrent.Magnet())
+		})
+	})
+
+	// clearTorrent descarta o torrent do arquivo anterior e os campos preenchidos a partir dele
+	clearTorrent := func() {
+		if fileTorrent != nil {
+			magnetEntry.SetText("")
+			infohashEntry.SetText("")
+			fileTorrent = nil
+		}
+	}
+
+	// withTorrent gera o torrent do arquivo enviado, se a opção estiver
+	// marcada, e depois chama done
+	withTorrent := func(path string, uploaded localUpload, done func()) {
+		if !torrentCheck.Checked {
+			done()
+			return
+		}
+		buildTorrent(win, path, uploaded, func(t *torrent.Torrent) {
+			defer done()
+			if t == nil || preEvent.Path != path {
+				return
+			}
+			fileTorrent = t
+			magnetEntry.SetText(t.Magnet())
+			infohashEntry.SetText(t.V1())
+			fileSizeLabel.SetText(fileSizeLabel.Text + fmt.Sprintf("\nTorrent: %s (v1) | %s (v2)", t.V1(), t.V2()))
+		})
+	}
+
+	ipfsOpts := newIPFSOptions()
+
+	// withIPFS calcula o CID do arquivo enviado, se a opção estiver marcada, e
+	// depois chama done
+	withIPFS := func(path string, uploaded localUpload, done func()) {
+		ipfsOpts.compute(win, path, uploaded, func(cid string) {
+			defer done()
+			if cid == "" || preEvent.Path != path {
+				return
+			}
+			ipfsCID = cid
+			fileSizeLabel.SetText(fileSizeLabel.Text + "\nCID IPFS: " + cid)
+		})
+	}
+
+	// afterUpload gera o torrent e o CID IPFS do arquivo enviado, nessa ordem
+	afterUpload := func(path string, uploaded localUpload, done func()) {
+		withTorrent(path, uploaded, func() { withIPFS(path, uploaded, done) })
+	}
+
 	eventOutput := widget.NewMultiLineEntry()
 	eventOutput.SetPlaceHolder("O evento Nostr gerado aparecerá aqui...")
 	eventOutput.Disable()
 
+	// extractedTags guarda as tags vindas dos metadados do arquivo atual, para
+	// que sejam descartadas quando outro arquivo for selecionado
+	var extractedTags []string
+
+	// loadFile processa um arquivo local: detecta o tipo, extrai os metadados e
+	// o envia com upload. Arquivos temporários (importados de uma URL) são
+	// apagados ao fim do processamento, a menos que um envio tenha ficado na fila.
+	loadFile := func(path string, upload func(path, mimeType string) (localUpload, error), temporary bool) {
+		preEvent.Path = path
+		clearTorrent()
+		ipfsCID = ""
+		var err error
+		preEvent.MimeType, err = sniff.File(preEvent.Path)
+		if err != nil {
+			if temporary {
+				os.Remove(path)
+			}
+			dialog.ShowError(err, win)
+			return
+		}
+		mimeEntry.SetText(preEvent.MimeType)
+
+		// As tags extraídas do arquivo anterior não valem para o novo; as
+		// adicionadas pelo usuário são mantidas
+		preEvent.Tags = slices.DeleteFunc(preEvent.Tags, func(tag string) bool {
+			return slices.Contains(extractedTags, tag)
+		})
+		extractedTags = nil
+
+		// Os metadados do próprio arquivo pré-preenchem os campos ainda vazios
+		if md, err := extract.Extract(preEvent.Path, preEvent.MimeType); err != nil {
+			log.Println("Erro ao extrair metadados:", err)
+		} else {
+			fillIfEmpty(titleEntry, md.Title)
+			fillIfEmpty(summaryEntry, md.Summary)
+			fillIfEmpty(altEntry, md.Alt)
+			for _, tag := range md.Tags {
+				if !slices.Contains(preEvent.Tags, tag) {
+					preEvent.Tags = append(preEvent.Tags, tag)
+					extractedTags = append(extractedTags, tag)
+				}
+			}
+		}
+		if len(preEvent.Tags) > 0 {
+			tagsLabel.SetText("Tags: " + strings.Join(preEvent.Tags, ", "))
+		} else {
+			tagsLabel.SetText("Tags (opcional):")
+		}
+
+		// Envio ao servidor Blossom. O hash e o tamanho são os do arquivo
+		// enviado, que pode ter tido os metadados removidos.
+		uploaded, err := upload(preEvent.Path, preEvent.MimeType)
+		discard := func() {
+			if temporary {
+				discardTemporary(path)
+			}
+		}
+		if err != nil {
+			discard()
+			dialog.ShowError(err, win)
+			return
+		}
+		preEvent.Sha256 = uploaded.PreEvent.Sha256
+		preEvent.Size = uploaded.PreEvent.Size
+		originalSha256 = uploaded.OriginalSha256
+		fileBlossom = uploaded.Responses
+		serviceEntry.SetText("blossom")
+
+		var fileURLs string
+		for _, f := range fileBlossom {
+			fileURLs += fmt.Sprintf("URL: %s\n", f.URL)
+		}
+		log.Println("URLs geradas pelo Blossom:", fileURLs)
+		info := fmt.Sprintf("Tamanho: %d bytes | MIME: %s\nBlossom Link gerado:\n%s", preEvent.Size, preEvent.MimeType, fileURLs)
+		if uploaded.Queued {
+			info += "\nAlguns envios estão na fila de saída; o evento pode aguardar na fila até que terminem."
+		}
+		if len(uploaded.Removed) > 0 {
+			info += removedMetadataText(uploaded.Removed)
+		}
+		fileSizeLabel.SetText(info)
+
+		// Para imagens, o BlurHash e as dimensões são calculados a partir do
+		// arquivo local, e as variantes redimensionadas são geradas e enviadas
+		preEvent.BlurHash, preEvent.Dim = "", ""
+		imageVariants = nil
+		if autoThumbURL != "" && thumbInput.image.URL == autoThumbURL {
+			thumbInput.Reset()
+		}
+		if !strings.HasPrefix(preEvent.MimeType, "image/") {
+			afterUpload(path, uploaded, discard)
+			return
+		}
+		withVariants := responsiveCheck.Checked
+		var meta imagemeta.Meta
+		var variants []nip71.Variant
+		var thumb nip71.Image
+		runCancellable(win, "Processando Imagem", "Calculando o BlurHash e gerando as variantes da imagem...",
+			func(ctx context.Context) (err error) {
+				if meta, err = imagemeta.DecodeFile(ctx, path); err != nil {
+					return err
+				}
+				if withVariants {
+					variants, thumb, err = uploadRenditions(ctx, path)
+				}
+				return err
+			},
+			func(err error) {
+				defer afterUpload(path, uploaded, discard)
+				if err != nil {
+					log.Println("Erro ao processar a imagem:", err)
+					dialog.ShowError(err, win)
+				}
+				if preEvent.Path != path {
+					return
+				}
+				preEvent.BlurHash, preEvent.Dim = meta.BlurHash, meta.Dim()
+				imageVariants = variants
+				if thumb.URL != "" && thumbInput.entry.Text == "" {
+					thumbInput.Set(thumb)
+					autoThumbURL = thumb.URL
+				}
+				if preEvent.Dim != "" {
+					fileSizeLabel.SetText(fileSizeLabel.Text + "\nDimensões: " + preEvent.Dim + " | BlurHash: " + preEvent.BlurHash)
+				}
+				if len(variants) > 0 {
+					labels := make([]string, len(variants))
+					for i, v := range variants {
+						labels[i] = v.Dim
+					}
+					fileSizeLabel.SetText(fileSizeLabel.Text + "\nVariantes enviadas: " + strings.Join(labels, ", "))
+				}
+			})
+	}
+
 	selectFileButton := widget.NewButton("Selecionar Arquivo", func() {
 		dialog.ShowFileOpen(func(file fyne.URIReadCloser, err error) {
 			if err != nil {
@@ -742,68 +1728,68 @@ func fileScreen(win fyne.Window) fyne.CanvasObject {
 			if file == nil {
 				return
 			}
-			preEvent.Path = file.URI().Path()
-			preEvent.MimeType = file.URI().MimeType()
-			f, err := os.Open(file.URI().Path())
-			if err != nil {
-				dialog.ShowError(err, win)
-				return
-			}
-			defer f.Close()
+			loadFile(file.URI().Path(), uploadLocalFile, false)
+		}, win)
+	})
 
-			h := sha256.New()
-			if _, err := io.Copy(h, f); err != nil {
-				dialog.ShowError(err, win)
-				return
+	// Importação: o arquivo é baixado e republicado nos nossos servidores,
+	// seguindo depois o mesmo fluxo de um arquivo local
+	importButton := widget.NewButton("Importar de URL", func() {
+		showImportDialog(win, func(path string, info remote.Info, mirror bool) {
+			upload := uploadLocalFile
+			if mirror {
+				upload = func(path, mimeType string) (localUpload, error) {
+					return mirrorOrUpload(path, mimeType, info.URL)
+				}
 			}
-			preEvent.Sha256 = fmt.Sprintf("%x", h.Sum(nil))
-
-			f.Seek(0, 0)
-			buffer := make([]byte, 512)
-			f.Read(buffer)
-			preEvent.MimeType = http.DetectContentType(buffer)
-
-			stat, _ := f.Stat()
-			preEvent.Size = stat.Size()
-			fileSizeLabel.SetText(fmt.Sprintf("Tamanho: %d bytes | MIME: %s", preEvent.Size, preEvent.MimeType))
+			loadFile(path, upload, true)
+		})
+	})
 
-			// Envio ao servidor Blossom
-			if len(App.BlossomServers) >= 1 {
-				fBlossom, errs := blossom.SendFile(App.HttpClient, *preEvent, *App)
-				if len(errs) > 0 {
-					var errMsgs []string
-					for _, e := range errs {
-						errMsgs = append(errMsgs, e.Error())
-					}
-					dialog.ShowError(fmt.Errorf("Erros ao enviar para Blossom:\n%s", strings.Join(errMsgs, "\n")), win)
-					return
-				}
-				fileBlossom = fBlossom
-			} else {
-				dialog.ShowInformation("Atenção", "Nenhum servidor Blossom configurado. Por favor, adicione um servidor na aba Configurações.", win)
-				return
+	// Modo remoto: o arquivo já está hospedado e é baixado uma vez para
+	// calcular x, size e m; as URLs alternativas viram tags "fallback"
+	remoteSourceButton := widget.NewButton("Definir URL Manualmente", func() {
+		showRemoteSourceDialog(win, "https://example.com/arquivo.pdf", func(info remote.Info, fallbacks []string) {
+			preEvent.Path = ""
+			clearTorrent()
+			ipfsCID = ""
+			preEvent.Sha256 = info.Sha256
+			preEvent.Size = info.Size
+			preEvent.MimeType = info.MimeType
+			preEvent.BlurHash, preEvent.Dim = "", ""
+			mimeEntry.SetText(info.MimeType)
+			originalSha256 = info.Sha256
+			imageVariants = nil
+			if autoThumbURL != "" && thumbInput.image.URL == autoThumbURL {
+				thumbInput.Reset()
 			}
-			if len(fileBlossom) > 0 {
-				var fileURLs string
-				for _, f := range fileBlossom {
-					fileURLs += fmt.Sprintf("URL: %s\n", f.URL)
-				}
+			serviceEntry.SetText("")
 
-				log.Println("URLs geradas pelo Blossom:", fileURLs)
-				fileSizeLabel.SetText("Blossom Link gerado:\n" + fileURLs)
+			fileBlossom = []model.BlossomResponse{{URL: info.URL, Sha256: info.Sha256, Size: info.Size, Type: info.MimeType}}
+			fileURLs := fmt.Sprintf("URL: %s\n", info.URL)
+			for _, f := range fallbacks {
+				fileBlossom = append(fileBlossom, model.BlossomResponse{URL: f, Sha256: info.Sha256, Size: info.Size, Type: info.MimeType})
+				fileURLs += fmt.Sprintf("Alternativa: %s\n", f)
 			}
-		}, win)
+			fileSizeLabel.SetText(fmt.Sprintf("Tamanho: %d bytes | MIME: %s | SHA-256: %s\n%s", info.Size, info.MimeType, info.Sha256, fileURLs))
+		})
 	})
 
 	publishButton := widget.NewButton("Gerar e Publicar", func() {
-		if preEvent.Path == "" {
-			dialog.ShowInformation("Atenção", "Por favor, selecione um arquivo primeiro.", win)
+		if len(fileBlossom) == 0 {
+			dialog.ShowInformation("Atenção", "Por favor, selecione um arquivo ou defina uma URL primeiro.", win)
 			return
 		}
 		if App.Nsec == "" {
 			dialog.ShowInformation("Atenção", "Por favor, configure sua chave NSEC.", win)
 			return
 		}
+		for _, entry := range []fyne.Validatable{mimeEntry, magnetEntry, infohashEntry} {
+			if err := entry.Validate(); err != nil {
+				dialog.ShowError(err, win)
+				return
+			}
+		}
 
 		// Monta as tags
 		t := nostr.Tags{
@@ -813,6 +1799,51 @@ func fileScreen(win fyne.Window) fyne.CanvasObject {
 			nostr.Tag{"size", fmt.Sprintf("%d", preEvent.Size)},
 			nostr.Tag{"url", fileBlossom[0].URL},
 		}
+		if originalSha256 != "" {
+			t = append(t, nostr.Tag{"ox", originalSha256})
+		}
+		if preEvent.Dim != "" {
+			t = append(t, nostr.Tag{"dim", preEvent.Dim})
+		}
+		if preEvent.BlurHash != "" {
+			t = append(t, nostr.Tag{"blurhash", preEvent.BlurHash})
+		}
+		if len(imageVariants) > 0 {
+			// Uma tag imeta para o original e uma para cada variante redimensionada
+			original := nip71.Variant{
+				URL:      fileBlossom[0].URL,
+				MimeType: preEvent.MimeType,
+				Sha256:   preEvent.Sha256,
+				Size:     preEvent.Size,
+				Dim:      preEvent.Dim,
+				BlurHash: preEvent.BlurHash,
+			}
+			for _, f := range fileBlossom[1:] {
+				original.Fallbacks = append(original.Fallbacks, f.URL)
+			}
+			ids, fbs := ipfsOpts.refs(ipfsCID)
+			original.Identifiers = append(original.Identifiers, ids...)
+			original.Fallbacks = append(original.Fallbacks, fbs...)
+			t = append(t, nip71.Tags(append([]nip71.Variant{original}, imageVariants...), false)...)
+		}
+		if thumb := thumbInput.image; thumb.URL != "" {
+			t = append(t, thumb.Tag("thumb"))
+		}
+		if image := coverInput.image; image.URL != "" {
+			t = append(t, image.Tag("image"))
+		}
+		if magnetEntry.Text != "" {
+			t = append(t, nostr.Tag{"magnet", magnetEntry.Text})
+		}
+		if infohashEntry.Text != "" {
+			t = append(t, nostr.Tag{"i", strings.ToLower(infohashEntry.Text)})
+		}
+		if service := strings.TrimSpace(serviceEntry.Text); service != "" {
+			t = append(t, nostr.Tag{"service", service})
+		}
+		if altEntry.Text != "" {
+			t = append(t, nostr.Tag{"alt", altEntry.Text})
+		}
 		if summaryEntry.Text != "" {
 			t = append(t, nostr.Tag{"summary", summaryEntry.Text})
 		}
@@ -833,6 +1864,13 @@ func fileScreen(win fyne.Window) fyne.CanvasObject {
 				t = append(t, nostr.Tag{"fallback", f.URL})
 			}
 		}
+		ids, fbs := ipfsOpts.refs(ipfsCID)
+		for _, id := range ids {
+			t = append(t, nostr.Tag{"i", id})
+		}
+		for _, fb := range fbs {
+			t = append(t, nostr.Tag{"fallback", fb})
+		}
 		for _, i := range preEvent.Indexers {
 			trimmedI := strings.TrimSpace(i)
 			if trimmedI != "" {
@@ -861,13 +1899,14 @@ func fileScreen(win fyne.Window) fyne.CanvasObject {
 			PubKey:    App.Npub,
 			Kind:      preEvent.Kind,
 		}
-		evt.Sign(App.Nsec)
+		if err := evt.Sign(App.Nsec); err != nil {
+			dialog.ShowError(fmt.Errorf("Erro ao assinar o evento: %w", err), win)
+			return
+		}
 		eventOutput.SetText(fmt.Sprintf("ID: %s\nKind: %d", evt.ID, evt.Kind))
 		eventOutput.Enable()
 
-		// Publica o evento (lógica similar à tela de vídeo)
-		// ... (a lógica de publicação pode ser extraída para uma função helper para evitar repetição)
-		dialog.ShowInformation("Publicado", "Evento de arquivo enviado com sucesso (simulação)!", win)
+		showPublishDialog(win, evt)
 	})
 
 	resetFormButton := widget.NewButton("Limpar Formulário", func() {
@@ -875,6 +1914,7 @@ func fileScreen(win fyne.Window) fyne.CanvasObject {
 		summaryEntry.SetText("")
 		descriptionEntry.SetText("")
 		tagsLabel.SetText("Tags (opcional):")
+		extractedTags = nil
 		preEvent = &model.PreEvent{
 			Kind:    nostr.KindFileMetadata,
 			PrivKey: App.Nsec,
@@ -883,6 +1923,17 @@ func fileScreen(win fyne.Window) fyne.CanvasObject {
 		eventOutput.SetText("")
 		eventOutput.Disable()
 		fileBlossom = nil // Limpa os links do Blossom
+		imageVariants = nil
+		originalSha256 = ""
+		fileTorrent = nil
+		ipfsCID = ""
+		coverInput.Reset()
+		thumbInput.Reset()
+		magnetEntry.SetText("")
+		infohashEntry.SetText("")
+		serviceEntry.SetText("")
+		altEntry.SetText("")
+		mimeEntry.SetText("")
 		dateEntry.SetDate(nil)
 		dateEntry.SetValidationError(nil)
 	})
@@ -891,14 +1942,24 @@ func fileScreen(win fyne.Window) fyne.CanvasObject {
 		Items: []*widget.FormItem{
 			{Text: "Título", Widget: titleEntry},
 			{Text: "Resumo", Widget: summaryEntry},
+			{Text: "Texto Alternativo", Widget: altEntry},
+			{Text: "Tipo MIME", Widget: mimeEntry},
+			{Text: "URL Imagem", Widget: coverInput.widget},
+			{Text: "URL Thumbnail", Widget: thumbInput.widget},
+			{Text: "Magnet", Widget: magnetEntry},
+			{Text: "Infohash", Widget: infohashEntry},
+			{Text: "Serviço", Widget: serviceEntry},
 			{Text: "Tags", Widget: container.NewHBox(tagsLabel, tagsOpenDialogButton)},
 			{Text: "NSFW", Widget: nsfwCheck},
 			{Text: "Indexadores", Widget: container.NewHBox(fynetooltip.AddWindowToolTipLayer(indexersLabel, win.Canvas()), indexerButton)},
 			{Text: "Data de Publicação", Widget: dateEntry},
+			{Text: "Imagens", Widget: responsiveCheck},
+			{Text: "Torrent", Widget: container.NewHBox(torrentCheck, torrentButton)},
+			{Text: "IPFS", Widget: ipfsOpts.widget()},
 		},
 	}
 	inputContainer := container.NewVBox(
-		selectFileButton,
+		container.NewHBox(selectFileButton, importButton, remoteSourceButton),
 		fileSizeLabel,
 		form,
 		widget.NewLabel("Descrição"),
@@ -913,6 +1974,31 @@ func fileScreen(win fyne.Window) fyne.CanvasObject {
 	return container.NewBorder(nil, actionsContainer, nil, nil, container.NewScroll(inputContainer))
 }
 
+// validateMime valida um tipo MIME digitado pelo usuário.
+func validateMime(s string) error {
+	if !sniff.Valid(strings.TrimSpace(s)) {
+		return fmt.Errorf("tipo MIME inválido: %q (ex: video/mp4)", s)
+	}
+	return nil
+}
+
+// fillIfEmpty preenche o campo com o valor sugerido, sem sobrescrever o que o
+// usuário já digitou.
+func fillIfEmpty(entry *widget.Entry, value string) {
+	if entry.Text == "" && value != "" {
+		entry.SetText(value)
+	}
+}
+
+// joinInts formata uma lista de números separada por vírgulas.
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = strconv.Itoa(v)
+	}
+	return strings.Join(parts, ", ")
+}
+
 // settingsScreen permite a configuração de relays, servidores e da chave privada.
 func settingsScreen(win fyne.Window) fyne.CanvasObject {
 	blossomServerEntry := widget.NewEntry()
@@ -1022,8 +2108,12 @@ func settingsScreen(win fyne.Window) fyne.CanvasObject {
 	// --- Gerenciamento de Relays ---
 	relayEntry := widget.NewEntry()
 	relayEntry.SetPlaceHolder("wss://...")
+	relayModeSelect := widget.NewSelect(relayModes, nil)
+	relayModeSelect.SetSelectedIndex(0)
 	var relayListWidget *widget.List
 	var selectedRelayID widget.ListItemID = -1
+	// refreshRelaySets é definida junto ao gerenciamento de conjuntos, mais abaixo.
+	var refreshRelaySets func()
 
 	relayListWidget = widget.NewList(
 		func() int {
@@ -1035,27 +2125,24 @@ func settingsScreen(win fyne.Window) fyne.CanvasObject {
 		func(i widget.ListItemID, o fyne.CanvasObject) {
 			App.Mutex.Lock()
 			defer App.Mutex.Unlock()
-			keys := make([]string, 0, len(App.Relays))
-			for k := range App.Relays {
-				keys = append(keys, k)
-			}
-			o.(*widget.Label).SetText(keys[i])
+			keys := App.RelayURLs()
+			o.(*widget.Label).SetText(fmt.Sprintf("%s (%s)", keys[i], App.Relays[keys[i]].Mode()))
 		},
 	)
 	relayListWidget.OnSelected = func(id widget.ListItemID) {
 		selectedRelayID = id
 		App.Mutex.Lock()
-		defer App.Mutex.Unlock()
-		keys := make([]string, 0, len(App.Relays))
-		for k := range App.Relays {
-			keys = append(keys, k)
-		}
+		keys := App.RelayURLs()
+		mode := App.Relays[keys[id]].Mode()
+		App.Mutex.Unlock()
 		relayEntry.SetText(keys[id])
+		relayModeSelect.SetSelected(mode)
 	}
 	relayListWidget.OnUnselected = func(id widget.ListItemID) {
 		if selectedRelayID == id {
 			selectedRelayID = -1
 			relayEntry.SetText("")
+			relayModeSelect.SetSelectedIndex(0)
 		}
 	}
 
@@ -1071,12 +2158,14 @@ func settingsScreen(win fyne.Window) fyne.CanvasObject {
 			return
 		}
 
+		read, write := relayModeFlags(relayModeSelect.Selected)
 		App.Mutex.Lock()
-		App.Relays[url] = &model.RelayStatus{URL: url, Status: "Desconectado"}
+		App.Relays[url] = &model.RelayStatus{URL: url, Status: "Desconectado", Read: read, Write: write}
 		App.Mutex.Unlock()
 		relayListWidget.Refresh()
 		relayListWidget.UnselectAll()
 		relayEntry.SetText("")
+		refreshRelaySets()
 	})
 	updateRelayButton := widget.NewButton("Atualizar", func() {
 		if selectedRelayID == -1 {
@@ -1087,18 +2176,26 @@ func settingsScreen(win fyne.Window) fyne.CanvasObject {
 		if newURL == "" {
 			return
 		}
+		read, write := relayModeFlags(relayModeSelect.Selected)
 		App.Mutex.Lock()
-		keys := make([]string, 0, len(App.Relays))
-		for k := range App.Relays {
-			keys = append(keys, k)
-		}
+		keys := App.RelayURLs()
 		oldURL := keys[selectedRelayID]
 		if oldURL != newURL {
 			delete(App.Relays, oldURL)
 			App.Relays[newURL] = &model.RelayStatus{URL: newURL, Status: "Desconectado"}
+			for name, urls := range App.RelaySets {
+				for i, u := range urls {
+					if u == oldURL {
+						App.RelaySets[name][i] = newURL
+					}
+				}
+			}
 		}
+		App.Relays[newURL].Read = read
+		App.Relays[newURL].Write = write
 		App.Mutex.Unlock()
 		relayListWidget.Refresh()
+		refreshRelaySets()
 	})
 	deleteRelayButton := widget.NewButton("Deletar", func() {
 		if selectedRelayID == -1 {
@@ -1106,25 +2203,102 @@ func settingsScreen(win fyne.Window) fyne.CanvasObject {
 			return
 		}
 		App.Mutex.Lock()
-		keys := make([]string, 0, len(App.Relays))
-		for k := range App.Relays {
-			keys = append(keys, k)
-		}
+		keys := App.RelayURLs()
 		urlToDelete := keys[selectedRelayID]
 		delete(App.Relays, urlToDelete)
+		for name, urls := range App.RelaySets {
+			var kept []string
+			for _, u := range urls {
+				if u != urlToDelete {
+					kept = append(kept, u)
+				}
+			}
+			App.RelaySets[name] = kept
+		}
 		App.Mutex.Unlock()
 		relayListWidget.Refresh()
 		relayListWidget.UnselectAll()
 		relayEntry.SetText("")
+		refreshRelaySets()
 	})
 
 	relayBox := container.NewBorder(
 		widget.NewLabelWithStyle("Relays", fyne.TextAlignCenter, fyne.TextStyle{Bold: true}),
-		container.NewVBox(relayEntry, container.NewHBox(addRelayButton, updateRelayButton, deleteRelayButton)),
+		container.NewVBox(
+			container.NewBorder(nil, nil, nil, relayModeSelect, relayEntry),
+			container.NewHBox(addRelayButton, updateRelayButton, deleteRelayButton),
+		),
 		nil, nil,
 		container.NewScroll(relayListWidget),
 	)
 
+	// --- Conjuntos de Relays ---
+	// Conjuntos nomeados permitem escolher rapidamente um grupo de relays na publicação.
+	setNameEntry := widget.NewEntry()
+	setNameEntry.SetPlaceHolder("Nome do conjunto (ex: principais)")
+	setRelaysCheck := widget.NewCheckGroup(nil, nil)
+	var setNames []string
+	var setListWidget *widget.List
+	setListWidget = widget.NewList(
+		func() int { return len(setNames) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			App.Mutex.Lock()
+			defer App.Mutex.Unlock()
+			o.(*widget.Label).SetText(fmt.Sprintf("%s (%d relays)", setNames[i], len(App.RelaySets[setNames[i]])))
+		},
+	)
+	refreshRelaySets = func() {
+		App.Mutex.Lock()
+		setRelaysCheck.Options = App.RelayURLs()
+		setNames = setNames[:0]
+		for name := range App.RelaySets {
+			setNames = append(setNames, name)
+		}
+		App.Mutex.Unlock()
+		sort.Strings(setNames)
+		setRelaysCheck.Refresh()
+		setListWidget.Refresh()
+	}
+	refreshRelaySets()
+	setListWidget.OnSelected = func(id widget.ListItemID) {
+		App.Mutex.Lock()
+		urls := append([]string(nil), App.RelaySets[setNames[id]]...)
+		App.Mutex.Unlock()
+		setNameEntry.SetText(setNames[id])
+		setRelaysCheck.SetSelected(urls)
+	}
+	saveSetButton := widget.NewButton("Salvar Conjunto", func() {
+		name := strings.TrimSpace(setNameEntry.Text)
+		if name == "" || len(setRelaysCheck.Selected) == 0 {
+			dialog.ShowInformation("Atenção", "Informe um nome e selecione ao menos um relay.", win)
+			return
+		}
+		App.Mutex.Lock()
+		App.RelaySets[name] = append([]string(nil), setRelaysCheck.Selected...)
+		App.Mutex.Unlock()
+		refreshRelaySets()
+		setListWidget.UnselectAll()
+		setNameEntry.SetText("")
+		setRelaysCheck.SetSelected(nil)
+	})
+	deleteSetButton := widget.NewButton("Deletar Conjunto", func() {
+		name := strings.TrimSpace(setNameEntry.Text)
+		App.Mutex.Lock()
+		delete(App.RelaySets, name)
+		App.Mutex.Unlock()
+		refreshRelaySets()
+		setListWidget.UnselectAll()
+		setNameEntry.SetText("")
+		setRelaysCheck.SetSelected(nil)
+	})
+	relaySetsBox := container.NewBorder(
+		widget.NewLabelWithStyle("Conjuntos de Relays", fyne.TextAlignCenter, fyne.TextStyle{Bold: true}),
+		container.NewVBox(setNameEntry, setRelaysCheck, container.NewHBox(saveSetButton, deleteSetButton)),
+		nil, nil,
+		container.NewScroll(setListWidget),
+	)
+
 	// --- NSEC ---
 	// FUNCIONALIDADE IMPLEMENTADA: Salva a NSEC no estado global da aplicação.
 	nsecEntry := widget.NewPasswordEntry()
@@ -1159,5 +2333,92 @@ func settingsScreen(win fyne.Window) fyne.CanvasObject {
 		saveNsecButton,
 	)
 
-	return container.NewVBox(relayBox, widget.NewSeparator(), blossomBox, widget.NewSeparator(), nsecBox)
+	// --- Privacidade ---
+	stripMetadataCheck := widget.NewCheck("Remover EXIF, GPS e XMP das imagens antes do envio (JPEG, PNG e WebP)", func(b bool) {
+		App.Mutex.Lock()
+		App.StripMetadata = b
+		App.Mutex.Unlock()
+	})
+	// SetChecked chama OnChanged, que trava o Mutex: o valor é lido antes
+	App.Mutex.Lock()
+	stripMetadata := App.StripMetadata
+	App.Mutex.Unlock()
+	stripMetadataCheck.SetChecked(stripMetadata)
+	privacyBox := container.NewVBox(
+		widget.NewLabelWithStyle("Privacidade", fyne.TextAlignCenter, fyne.TextStyle{Bold: true}),
+		stripMetadataCheck,
+	)
+
+	// --- IPFS ---
+	ipfsAPIEntry := widget.NewEntry()
+	ipfsAPIEntry.SetPlaceHolder(ipfs.DefaultAPI)
+	App.Mutex.Lock()
+	ipfsAPIEntry.SetText(App.IPFSAPI)
+	App.Mutex.Unlock()
+	ipfsAPIEntry.Validator = func(s string) error {
+		if s == "" {
+			return nil
+		}
+		return validateURL(s)
+	}
+	ipfsAPIEntry.OnChanged = func(s string) {
+		App.Mutex.Lock()
+		App.IPFSAPI = strings.TrimSpace(s)
+		App.Mutex.Unlock()
+	}
+	ipfsBox := container.NewVBox(
+		widget.NewLabelWithStyle("IPFS", fyne.TextAlignCenter, fyne.TextStyle{Bold: true}),
+		widget.NewLabel("Endereço da API HTTP do nó IPFS local, usado para fixar (pin) os arquivos:"),
+		ipfsAPIEntry,
+	)
+
+	// --- Eventos Endereçáveis ---
+	dTagLabels := make([]string, len(dtag.Strategies))
+	for i, strategy := range dtag.Strategies {
+		dTagLabels[i] = dTagStrategyLabels[strategy]
+	}
+	dTagSelect := widget.NewSelect(dTagLabels, func(selected string) {
+		for strategy, label := range dTagStrategyLabels {
+			if label == selected {
+				App.Mutex.Lock()
+				App.DTagStrategy = strategy
+				App.Mutex.Unlock()
+			}
+		}
+	})
+	App.Mutex.Lock()
+	dTagStrategy := App.DTagStrategy
+	App.Mutex.Unlock()
+	dTagSelect.SetSelected(dTagStrategyLabels[dTagStrategy])
+	addressableBox := container.NewVBox(
+		widget.NewLabelWithStyle("Eventos Endereçáveis", fyne.TextAlignCenter, fyne.TextStyle{Bold: true}),
+		widget.NewLabel("Identificador \"d\" de novos vídeos endereçáveis (eventos editados mantêm o original):"),
+		dTagSelect,
+	)
+
+	return container.NewVBox(relayBox, widget.NewSeparator(), relaySetsBox, widget.NewSeparator(), blossomBox, widget.NewSeparator(), privacyBox, widget.NewSeparator(), ipfsBox, widget.NewSeparator(), addressableBox, widget.NewSeparator(), nsecBox)
+}
+
+// dTagStrategyLabels descreve as estratégias de geração da tag "d" nas Configurações.
+var dTagStrategyLabels = map[string]string{
+	dtag.Timestamp: "Identificador da instalação e horário (um endereço novo a cada publicação)",
+	dtag.Slug:      "Slug do título (ex: meu-video)",
+	dtag.Hash:      "Hash SHA-256 do arquivo principal",
+	dtag.UUID:      "UUID aleatório",
+}
+
+// relayModes são as opções de uso de um relay exibidas nas Configurações,
+// na mesma ordem e com os mesmos textos de model.RelayStatus.Mode.
+var relayModes = []string{"Leitura e Escrita", "Somente Leitura", "Somente Escrita"}
+
+// relayModeFlags converte uma opção de relayModes nas flags de leitura e escrita.
+func relayModeFlags(mode string) (read, write bool) {
+	switch mode {
+	case "Somente Leitura":
+		return true, false
+	case "Somente Escrita":
+		return false, true
+	default:
+		return true, true
+	}
 }
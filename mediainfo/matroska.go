@@ -0,0 +1,288 @@
+package mediainfo
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// IDs dos elementos EBML/Matroska usados na leitura.
+const (
+	idEBML          = 0x1A45DFA3
+	idDocType       = 0x4282
+	idSegment       = 0x18538067
+	idInfo          = 0x1549A966
+	idTimecodeScale = 0x2AD7B1
+	idDuration      = 0x4489
+	idTracks        = 0x1654AE6B
+	idTrackEntry    = 0xAE
+	idTrackType     = 0x83
+	idCodecID       = 0x86
+	idVideo         = 0xE0
+	idPixelWidth    = 0xB0
+	idPixelHeight   = 0xBA
+	idDisplayWidth  = 0x54B0
+	idDisplayHeight = 0x54BA
+	idCluster       = 0x1F43B675
+)
+
+// maxMasterSize limita o tamanho dos elementos Info e Tracks carregados em memória.
+const maxMasterSize = 16 << 20
+
+// unknownSize representa um elemento com tamanho desconhecido (transmissões ao vivo).
+const unknownSize = -1
+
+// probeMatroska lê o cabeçalho EBML, o elemento Info (duração) e o elemento
+// Tracks (codecs e dimensões) de um arquivo Matroska ou WebM. A leitura para no
+// primeiro Cluster, onde começam os dados de mídia.
+func probeMatroska(r io.ReadSeeker, size int64) (*Info, error) {
+	info := &Info{Container: "matroska"}
+	var (
+		offset        int64
+		timecodeScale uint64 = 1000000
+		rawDuration   float64
+		haveInfo      bool
+		haveTracks    bool
+	)
+
+loop:
+	for size <= 0 || offset < size {
+		id, dataSize, headerLen, err := readElementHeader(r)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		offset += headerLen
+
+		switch id {
+		case idSegment:
+			// O Segment é percorrido por dentro, sem carregá-lo em memória.
+			continue
+		case idCluster:
+			break loop
+		case idEBML, idInfo, idTracks:
+			if dataSize == unknownSize || dataSize > maxMasterSize {
+				return nil, fmt.Errorf("element 0x%X too large", id)
+			}
+			data := make([]byte, dataSize)
+			if _, err := io.ReadFull(r, data); err != nil {
+				return nil, fmt.Errorf("error reading element 0x%X: %w", id, err)
+			}
+			switch id {
+			case idEBML:
+				err = forEachElement(data, func(id uint64, body []byte) error {
+					if id == idDocType && string(body) == "webm" {
+						info.Container = "webm"
+					}
+					return nil
+				})
+			case idInfo:
+				haveInfo = true
+				err = forEachElement(data, func(id uint64, body []byte) error {
+					switch id {
+					case idTimecodeScale:
+						timecodeScale = readUint(body)
+					case idDuration:
+						rawDuration = readFloat(body)
+					}
+					return nil
+				})
+			case idTracks:
+				haveTracks = true
+				err = forEachElement(data, func(id uint64, body []byte) error {
+					if id == idTrackEntry {
+						return parseTrackEntry(body, info)
+					}
+					return nil
+				})
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if haveInfo && haveTracks {
+			break loop
+		}
+		if dataSize == unknownSize {
+			return nil, fmt.Errorf("element 0x%X with unknown size", id)
+		}
+		offset += dataSize
+		if _, err := r.Seek(offset, io.SeekStart); err != nil {
+			return nil, err
+		}
+	}
+
+	if !haveInfo && !haveTracks {
+		return nil, fmt.Errorf("matroska segment info not found")
+	}
+	info.Duration = rawDuration * float64(timecodeScale) / 1e9
+	return info, nil
+}
+
+// parseTrackEntry lê o tipo, o codec e as dimensões de uma faixa.
+func parseTrackEntry(data []byte, info *Info) error {
+	var (
+		trackType                   uint64
+		codec                       string
+		width, height               int
+		displayWidth, displayHeight int
+	)
+	err := forEachElement(data, func(id uint64, body []byte) error {
+		switch id {
+		case idTrackType:
+			trackType = readUint(body)
+		case idCodecID:
+			codec = string(body)
+		case idVideo:
+			return forEachElement(body, func(id uint64, body []byte) error {
+				switch id {
+				case idPixelWidth:
+					width = int(readUint(body))
+				case idPixelHeight:
+					height = int(readUint(body))
+				case idDisplayWidth:
+					displayWidth = int(readUint(body))
+				case idDisplayHeight:
+					displayHeight = int(readUint(body))
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	switch trackType {
+	case 1: // vídeo
+		if info.VideoCodec == "" {
+			info.VideoCodec = codec
+			info.Width, info.Height = width, height
+			if displayWidth > 0 && displayHeight > 0 {
+				info.Width, info.Height = displayWidth, displayHeight
+			}
+		}
+	case 2: // áudio
+		if info.AudioCodec == "" {
+			info.AudioCodec = codec
+		}
+	}
+	return nil
+}
+
+// readElementHeader lê o ID e o tamanho de um elemento EBML a partir do leitor.
+func readElementHeader(r io.Reader) (id uint64, dataSize int64, headerLen int64, err error) {
+	var first [1]byte
+	if _, err = io.ReadFull(r, first[:]); err != nil {
+		return
+	}
+	idLen := vintLength(first[0])
+	if idLen == 0 || idLen > 4 {
+		return 0, 0, 0, fmt.Errorf("invalid EBML element ID")
+	}
+	idBytes := make([]byte, idLen)
+	idBytes[0] = first[0]
+	if _, err = io.ReadFull(r, idBytes[1:]); err != nil {
+		return
+	}
+	for _, b := range idBytes {
+		id = id<<8 | uint64(b)
+	}
+
+	if _, err = io.ReadFull(r, first[:]); err != nil {
+		return
+	}
+	sizeLen := vintLength(first[0])
+	if sizeLen == 0 {
+		return 0, 0, 0, fmt.Errorf("invalid EBML element size")
+	}
+	sizeBytes := make([]byte, sizeLen)
+	sizeBytes[0] = first[0]
+	if _, err = io.ReadFull(r, sizeBytes[1:]); err != nil {
+		return
+	}
+	dataSize = decodeVintSize(sizeBytes)
+	return id, dataSize, int64(idLen + sizeLen), nil
+}
+
+// forEachElement percorre os elementos filhos contidos em data.
+func forEachElement(data []byte, fn func(id uint64, body []byte) error) error {
+	for len(data) > 0 {
+		idLen := vintLength(data[0])
+		if idLen == 0 || idLen > 4 || idLen >= len(data) {
+			return fmt.Errorf("invalid EBML element ID")
+		}
+		var id uint64
+		for _, b := range data[:idLen] {
+			id = id<<8 | uint64(b)
+		}
+		data = data[idLen:]
+
+		sizeLen := vintLength(data[0])
+		if sizeLen == 0 || sizeLen > len(data) {
+			return fmt.Errorf("invalid EBML element size")
+		}
+		dataSize := decodeVintSize(data[:sizeLen])
+		data = data[sizeLen:]
+		if dataSize == unknownSize || dataSize > int64(len(data)) {
+			dataSize = int64(len(data))
+		}
+		if err := fn(id, data[:dataSize]); err != nil {
+			return err
+		}
+		data = data[dataSize:]
+	}
+	return nil
+}
+
+// vintLength devolve o comprimento de um inteiro de tamanho variável a partir
+// do primeiro byte, ou 0 se o byte for inválido.
+func vintLength(b byte) int {
+	for i := 0; i < 8; i++ {
+		if b&(0x80>>i) != 0 {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// decodeVintSize decodifica o tamanho de um elemento, removendo o marcador de
+// comprimento. Um valor com todos os bits em 1 significa tamanho desconhecido.
+func decodeVintSize(b []byte) int64 {
+	value := uint64(b[0] & (0xFF >> len(b)))
+	allOnes := value == uint64(0xFF>>len(b))
+	for _, c := range b[1:] {
+		value = value<<8 | uint64(c)
+		allOnes = allOnes && c == 0xFF
+	}
+	if allOnes {
+		return unknownSize
+	}
+	return int64(value)
+}
+
+// readUint decodifica um inteiro sem sinal big-endian de até 8 bytes.
+func readUint(b []byte) uint64 {
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v
+}
+
+// readFloat decodifica um float EBML de 4 ou 8 bytes.
+func readFloat(b []byte) float64 {
+	switch len(b) {
+	case 4:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b)))
+	case 8:
+		return math.Float64frombits(binary.BigEndian.Uint64(b))
+	}
+	return 0
+}
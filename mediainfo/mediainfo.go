@@ -0,0 +1,119 @@
+package mediainfo
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// ErrUnsupported indica que o contêiner do arquivo não é reconhecido.
+var ErrUnsupported = errors.New("unsupported container")
+
+// Info reúne os metadados técnicos lidos do contêiner de um vídeo ou áudio.
+type Info struct {
+	// Container é o formato do arquivo: "mp4", "mov", "webm" ou "matroska".
+	Container string
+
+	// Duration é a duração em segundos.
+	Duration float64
+
+	// Width e Height são as dimensões de exibição da faixa de vídeo, já
+	// considerando a rotação indicada no contêiner.
+	Width, Height int
+
+	VideoCodec string
+	AudioCodec string
+
+	// Bitrate é a taxa média em bits por segundo, calculada a partir do tamanho do arquivo.
+	Bitrate int64
+}
+
+// Dim devolve as dimensões no formato "<largura>x<altura>", ou "" se desconhecidas.
+func (i Info) Dim() string {
+	if i.Width <= 0 || i.Height <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%dx%d", i.Width, i.Height)
+}
+
+// IsVertical informa se o vídeo é mais alto do que largo.
+func (i Info) IsVertical() bool {
+	return i.Height > i.Width
+}
+
+// String devolve um resumo legível dos metadados.
+func (i Info) String() string {
+	var parts []string
+	if i.Duration > 0 {
+		parts = append(parts, "Duração: "+(time.Duration(i.Duration*float64(time.Second))).Round(time.Second).String())
+	}
+	if dim := i.Dim(); dim != "" {
+		parts = append(parts, dim)
+	}
+	var codecs []string
+	for _, c := range []string{i.VideoCodec, i.AudioCodec} {
+		if c != "" {
+			codecs = append(codecs, c)
+		}
+	}
+	if len(codecs) > 0 {
+		parts = append(parts, "Codecs: "+strings.Join(codecs, ", "))
+	}
+	if i.Bitrate > 0 {
+		parts = append(parts, fmt.Sprintf("%d kbps", i.Bitrate/1000))
+	}
+	return strings.Join(parts, " | ")
+}
+
+// Probe abre o arquivo e lê seus metadados.
+func Probe(path string) (*Info, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return ProbeReader(f, stat.Size())
+}
+
+// ProbeReader identifica o contêiner pelo cabeçalho e lê seus metadados.
+// Apenas as estruturas de cabeçalho são lidas; os dados de mídia são ignorados.
+func ProbeReader(r io.ReadSeeker, size int64) (*Info, error) {
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	header := make([]byte, 12)
+	n, err := io.ReadFull(r, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+	header = header[:n]
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	var info *Info
+	switch {
+	case len(header) >= 8 && string(header[4:8]) == "ftyp":
+		info, err = probeMP4(r, size)
+	case len(header) >= 4 && string(header[:4]) == "\x1a\x45\xdf\xa3":
+		info, err = probeMatroska(r, size)
+	default:
+		return nil, ErrUnsupported
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if info.Duration > 0 && size > 0 {
+		info.Bitrate = int64(float64(size*8) / info.Duration)
+	}
+	return info, nil
+}
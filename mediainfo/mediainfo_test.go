@@ -0,0 +1,164 @@
+package mediainfo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+)
+
+// box monta uma caixa MP4 com o tipo e o conteúdo informados.
+func box(typ string, body ...[]byte) []byte {
+	b := bytes.Join(body, nil)
+	out := binary.BigEndian.AppendUint32(nil, uint32(8+len(b)))
+	out = append(out, typ...)
+	return append(out, b...)
+}
+
+func u32(v uint32) []byte {
+	return binary.BigEndian.AppendUint32(nil, v)
+}
+
+// tkhd monta o corpo de uma caixa tkhd versão 0 com a matriz e as dimensões.
+func tkhd(a, b, c, d int32, width, height uint32) []byte {
+	body := make([]byte, 40)
+	matrix := make([]byte, 36)
+	binary.BigEndian.PutUint32(matrix[0:], uint32(a))
+	binary.BigEndian.PutUint32(matrix[4:], uint32(b))
+	binary.BigEndian.PutUint32(matrix[12:], uint32(c))
+	binary.BigEndian.PutUint32(matrix[16:], uint32(d))
+	binary.BigEndian.PutUint32(matrix[32:], 0x40000000)
+	body = append(body, matrix...)
+	body = append(body, u32(width<<16)...)
+	return append(body, u32(height<<16)...)
+}
+
+// trak monta uma faixa com o tipo (hdlr) e o codec (stsd) informados.
+func trak(header []byte, handler, codec string) []byte {
+	hdlr := box("hdlr", make([]byte, 8), []byte(handler), make([]byte, 12))
+	stsd := box("stsd", make([]byte, 4), u32(1), box(codec, make([]byte, 20)))
+	return box("trak", box("tkhd", header), box("mdia", hdlr, box("minf", box("stbl", stsd))))
+}
+
+// mp4File monta um MP4 com a moov no fim: 30,5 s, vídeo 1920x1080 girado 90° e áudio.
+func mp4File() []byte {
+	mvhd := box("mvhd", make([]byte, 4), u32(0), u32(0), u32(1000), u32(30500), make([]byte, 80))
+	video := trak(tkhd(0, 0x10000, -0x10000, 0, 1920, 1080), "vide", "avc1")
+	sound := trak(tkhd(0x10000, 0, 0, 0x10000, 0, 0), "soun", "mp4a")
+	f := box("ftyp", []byte("isom"), u32(0))
+	f = append(f, box("mdat", make([]byte, 1000))...)
+	return append(f, box("moov", mvhd, video, sound)...)
+}
+
+// element monta um elemento EBML com o tamanho em 8 bytes.
+func element(id []byte, body ...[]byte) []byte {
+	b := bytes.Join(body, nil)
+	out := append([]byte{}, id...)
+	out = binary.BigEndian.AppendUint64(out, uint64(len(b))|1<<56)
+	return append(out, b...)
+}
+
+// mkvFile monta um WebM de 95 s com vídeo VP9 720x1280 e áudio Opus. O
+// Segment tem tamanho desconhecido, como em gravações ao vivo. Também devolve
+// o fim do Info, usado nos cortes.
+func mkvFile() (data []byte, infoEnd int) {
+	duration := binary.BigEndian.AppendUint64(nil, math.Float64bits(95000))
+	header := element([]byte{0x1A, 0x45, 0xDF, 0xA3}, element([]byte{0x42, 0x82}, []byte("webm")))
+	info := element([]byte{0x15, 0x49, 0xA9, 0x66},
+		element([]byte{0x2A, 0xD7, 0xB1}, []byte{0x0F, 0x42, 0x40}),
+		element([]byte{0x44, 0x89}, duration))
+	video := element([]byte{0xAE},
+		element([]byte{0x83}, []byte{1}),
+		element([]byte{0x86}, []byte("V_VP9")),
+		element([]byte{0xE0}, element([]byte{0xB0}, []byte{0x02, 0xD0}), element([]byte{0xBA}, []byte{0x05, 0x00})))
+	audio := element([]byte{0xAE}, element([]byte{0x83}, []byte{2}), element([]byte{0x86}, []byte("A_OPUS")))
+	tracks := element([]byte{0x16, 0x54, 0xAE, 0x6B}, video, audio)
+	cluster := element([]byte{0x1F, 0x43, 0xB6, 0x75}, make([]byte, 500))
+
+	data = append(header, 0x18, 0x53, 0x80, 0x67, 0x01, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF)
+	data = append(data, info...)
+	infoEnd = len(data)
+	data = append(data, tracks...)
+	return append(data, cluster...), infoEnd
+}
+
+func TestProbeReader(t *testing.T) {
+	mp4 := mp4File()
+	mkv, _ := mkvFile()
+	tests := []struct {
+		name string
+		data []byte
+		want Info
+	}{
+		{"mp4", mp4, Info{Container: "mp4", Duration: 30.5, Width: 1080, Height: 1920, VideoCodec: "avc1", AudioCodec: "mp4a",
+			Bitrate: int64(float64(len(mp4)*8) / 30.5)}},
+		{"webm", mkv, Info{Container: "webm", Duration: 95, Width: 720, Height: 1280, VideoCodec: "V_VP9", AudioCodec: "A_OPUS",
+			Bitrate: int64(float64(len(mkv)*8) / 95)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ProbeReader(bytes.NewReader(tt.data), int64(len(tt.data)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *got != tt.want {
+				t.Errorf("ProbeReader = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProbeReaderTruncated(t *testing.T) {
+	mp4 := mp4File()
+	mkv, infoEnd := mkvFile()
+	moov := bytes.Index(mp4, []byte("moov")) - 4
+
+	// Uma caixa interna que declara mais bytes do que a moov contém
+	oversized := append([]byte{}, mp4...)
+	tkhdAt := bytes.Index(oversized, []byte("tkhd")) - 4
+	binary.BigEndian.PutUint32(oversized[tkhdAt:], 1<<20)
+
+	// Um tkhd curto demais para a matriz e as dimensões
+	shortTkhd := append(box("ftyp", []byte("isom"), u32(0)),
+		box("moov", trak(make([]byte, 20), "vide", "avc1"))...)
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"mp4 sem moov", mp4[:moov]},
+		{"mp4 no cabeçalho da moov", mp4[:moov+6]},
+		{"mp4 no meio da moov", mp4[:moov+100]},
+		{"mp4 um byte a menos", mp4[:len(mp4)-1]},
+		{"mp4 com caixa interna grande demais", oversized},
+		{"mp4 com tkhd truncado", shortTkhd},
+		{"mkv no cabeçalho EBML", mkv[:10]},
+		{"mkv no meio do Info", mkv[:infoEnd-4]},
+		{"mkv no meio do Tracks", mkv[:infoEnd+20]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if info, err := ProbeReader(bytes.NewReader(tt.data), int64(len(tt.data))); err == nil {
+				t.Errorf("ProbeReader returned %+v and no error", *info)
+			}
+		})
+	}
+
+	// Cortado logo após o Info, o arquivo ainda tem a duração
+	info, err := ProbeReader(bytes.NewReader(mkv[:infoEnd]), int64(infoEnd))
+	if err != nil || info.Duration != 95 || info.VideoCodec != "" {
+		t.Errorf("mkv cut after Info = %+v, %v", info, err)
+	}
+
+	// Nenhum prefixo pode causar pânico
+	for _, data := range [][]byte{mp4, mkv} {
+		for n := range len(data) {
+			ProbeReader(bytes.NewReader(data[:n]), int64(n))
+		}
+	}
+
+	if _, err := ProbeReader(bytes.NewReader([]byte("not a video")), 11); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("ProbeReader(text) = %v, want ErrUnsupported", err)
+	}
+}
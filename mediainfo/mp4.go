@@ -0,0 +1,216 @@
+package mediainfo
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// maxMoovSize limita o tamanho da caixa moov carregada em memória.
+const maxMoovSize = 64 << 20
+
+// mp4Track reúne os dados de uma caixa trak relevantes para Info.
+type mp4Track struct {
+	handler       string
+	codec         string
+	width, height int
+}
+
+// probeMP4 percorre as caixas de nível superior até encontrar a moov e extrai
+// dela a duração (mvhd), as dimensões (tkhd), o tipo (hdlr) e o codec (stsd) das faixas.
+func probeMP4(r io.ReadSeeker, size int64) (*Info, error) {
+	info := &Info{Container: "mp4"}
+	var offset int64
+	for size <= 0 || offset < size {
+		boxType, boxSize, headerLen, err := readBoxHeader(r, size-offset)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+
+		switch boxType {
+		case "ftyp":
+			brand := make([]byte, 4)
+			if _, err := io.ReadFull(r, brand); err != nil {
+				return nil, err
+			}
+			if string(brand) == "qt  " {
+				info.Container = "mov"
+			}
+		case "moov":
+			if boxSize-headerLen > maxMoovSize {
+				return nil, fmt.Errorf("moov box too large: %d bytes", boxSize)
+			}
+			moov := make([]byte, boxSize-headerLen)
+			if _, err := io.ReadFull(r, moov); err != nil {
+				return nil, fmt.Errorf("error reading moov box: %w", err)
+			}
+			if err := parseMoov(moov, info); err != nil {
+				return nil, err
+			}
+			return info, nil
+		}
+
+		offset += boxSize
+		if _, err := r.Seek(offset, io.SeekStart); err != nil {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("moov box not found")
+}
+
+// readBoxHeader lê o cabeçalho de uma caixa, tratando tamanhos de 64 bits e o
+// tamanho zero ("até o fim do arquivo").
+func readBoxHeader(r io.Reader, remaining int64) (boxType string, boxSize, headerLen int64, err error) {
+	var hdr [8]byte
+	if _, err = io.ReadFull(r, hdr[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			err = io.EOF
+		}
+		return
+	}
+	boxSize = int64(binary.BigEndian.Uint32(hdr[:4]))
+	boxType = string(hdr[4:8])
+	headerLen = 8
+	switch boxSize {
+	case 1:
+		var large [8]byte
+		if _, err = io.ReadFull(r, large[:]); err != nil {
+			return
+		}
+		boxSize = int64(binary.BigEndian.Uint64(large[:]))
+		headerLen = 16
+	case 0:
+		boxSize = remaining
+	}
+	if boxSize < headerLen {
+		err = fmt.Errorf("invalid size %d for box %q", boxSize, boxType)
+	}
+	return
+}
+
+// forEachBox percorre as caixas contidas em data, chamando fn com o tipo e o conteúdo de cada uma.
+func forEachBox(data []byte, fn func(boxType string, body []byte) error) error {
+	for len(data) >= 8 {
+		boxSize := uint64(binary.BigEndian.Uint32(data[:4]))
+		boxType := string(data[4:8])
+		headerLen := uint64(8)
+		switch boxSize {
+		case 1:
+			if len(data) < 16 {
+				return fmt.Errorf("truncated box %q", boxType)
+			}
+			boxSize = binary.BigEndian.Uint64(data[8:16])
+			headerLen = 16
+		case 0:
+			boxSize = uint64(len(data))
+		}
+		if boxSize < headerLen || boxSize > uint64(len(data)) {
+			return fmt.Errorf("invalid size %d for box %q", boxSize, boxType)
+		}
+		if err := fn(boxType, data[headerLen:boxSize]); err != nil {
+			return err
+		}
+		data = data[boxSize:]
+	}
+	return nil
+}
+
+// parseMoov extrai a duração global e os dados das faixas de vídeo e áudio.
+func parseMoov(moov []byte, info *Info) error {
+	return forEachBox(moov, func(boxType string, body []byte) error {
+		switch boxType {
+		case "mvhd":
+			timescale, duration, err := parseMvhd(body)
+			if err != nil {
+				return err
+			}
+			if timescale > 0 {
+				info.Duration = float64(duration) / float64(timescale)
+			}
+		case "trak":
+			var t mp4Track
+			if err := parseTrak(body, &t); err != nil {
+				return err
+			}
+			switch t.handler {
+			case "vide":
+				if info.VideoCodec == "" {
+					info.VideoCodec = t.codec
+					info.Width, info.Height = t.width, t.height
+				}
+			case "soun":
+				if info.AudioCodec == "" {
+					info.AudioCodec = t.codec
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// parseMvhd lê a escala de tempo e a duração do cabeçalho do filme.
+func parseMvhd(body []byte) (timescale uint32, duration uint64, err error) {
+	if len(body) < 4 {
+		return 0, 0, fmt.Errorf("truncated mvhd box")
+	}
+	if body[0] == 1 {
+		if len(body) < 32 {
+			return 0, 0, fmt.Errorf("truncated mvhd box")
+		}
+		return binary.BigEndian.Uint32(body[20:24]), binary.BigEndian.Uint64(body[24:32]), nil
+	}
+	if len(body) < 20 {
+		return 0, 0, fmt.Errorf("truncated mvhd box")
+	}
+	return binary.BigEndian.Uint32(body[12:16]), uint64(binary.BigEndian.Uint32(body[16:20])), nil
+}
+
+// parseTrak percorre trak → tkhd/mdia → hdlr/minf → stbl → stsd.
+func parseTrak(trak []byte, t *mp4Track) error {
+	return forEachBox(trak, func(boxType string, body []byte) error {
+		switch boxType {
+		case "tkhd":
+			return parseTkhd(body, t)
+		case "mdia", "minf", "stbl":
+			return parseTrak(body, t)
+		case "hdlr":
+			if len(body) >= 12 {
+				t.handler = string(body[8:12])
+			}
+		case "stsd":
+			// versão/flags (4), número de entradas (4) e a primeira entrada: tamanho (4) e formato (4).
+			if len(body) >= 16 {
+				t.codec = string(body[12:16])
+			}
+		}
+		return nil
+	})
+}
+
+// parseTkhd lê as dimensões de apresentação da faixa e aplica a rotação da
+// matriz de transformação, trocando largura e altura em rotações de 90°/270°.
+func parseTkhd(body []byte, t *mp4Track) error {
+	// Deslocamento da matriz depois dos campos que dependem da versão.
+	matrixOffset := 40
+	if len(body) > 0 && body[0] == 1 {
+		matrixOffset = 52
+	}
+	if len(body) < matrixOffset+36+8 {
+		return fmt.Errorf("truncated tkhd box")
+	}
+	matrix := body[matrixOffset : matrixOffset+36]
+	a := int32(binary.BigEndian.Uint32(matrix[0:4]))
+	d := int32(binary.BigEndian.Uint32(matrix[16:20]))
+
+	dims := body[matrixOffset+36:]
+	t.width = int(binary.BigEndian.Uint32(dims[0:4]) >> 16)
+	t.height = int(binary.BigEndian.Uint32(dims[4:8]) >> 16)
+	if a == 0 && d == 0 {
+		t.width, t.height = t.height, t.width
+	}
+	return nil
+}
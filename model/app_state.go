@@ -2,6 +2,7 @@ package model
 
 import (
 	"net/http"
+	"sort"
 	"sync"
 )
 
@@ -18,6 +19,10 @@ type AppState struct {
 	// A chave do mapa é a URL do relay (ex: "wss://relay.damus.io").
 	Relays map[string]*RelayStatus
 
+	// RelaySets armazena conjuntos nomeados de relays que podem ser escolhidos
+	// no momento da publicação. A chave é o nome do conjunto e o valor a lista de URLs.
+	RelaySets map[string][]string
+
 	// BlossomServers armazena a lista de servidores Blossom configurados.
 	// A chave e o valor são a URL do servidor.
 	BlossomServers MapString
@@ -29,6 +34,18 @@ type AppState struct {
 	Npub     string
 	UniqueID string
 
+	// StripMetadata indica se EXIF, GPS e XMP devem ser removidos das imagens
+	// antes do envio aos servidores Blossom. Ativado por padrão.
+	StripMetadata bool
+
+	// DTagStrategy é a estratégia de geração da tag "d" dos eventos
+	// endereçáveis (ver o pacote dtag).
+	DTagStrategy string
+
+	// IPFSAPI é o endereço da API HTTP de um nó IPFS (Kubo) usado para fixar
+	// (pin) os arquivos enviados, quando pedido.
+	IPFSAPI string
+
 	// Mutex é usado para prevenir "race conditions" ao acessar os dados
 	// do AppState de diferentes goroutines (por exemplo, UI e threads de rede).
 	// Qualquer modificação ou leitura nos mapas (Relays, BlossomServers) ou na Nsec
@@ -43,14 +60,69 @@ type RelayStatus struct {
 
 	// Status descreve o estado atual da conexão (ex: "Conectado", "Desconectado", "Erro").
 	Status string
+
+	// Read indica se o relay é usado para consultas (leitura de eventos).
+	Read bool
+
+	// Write indica se o relay recebe os eventos publicados.
+	Write bool
+}
+
+// Mode devolve uma descrição legível do modo de uso do relay.
+func (r RelayStatus) Mode() string {
+	switch {
+	case r.Read && !r.Write:
+		return "Somente Leitura"
+	case r.Write && !r.Read:
+		return "Somente Escrita"
+	default:
+		return "Leitura e Escrita"
+	}
+}
+
+// RelayURLs devolve, em ordem alfabética, as URLs de todos os relays configurados.
+// O chamador deve segurar o Mutex.
+func (a *AppState) RelayURLs() []string {
+	urls := make([]string, 0, len(a.Relays))
+	for url := range a.Relays {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+	return urls
+}
+
+// WriteRelays devolve, em ordem alfabética, as URLs dos relays marcados para escrita.
+// O chamador deve segurar o Mutex.
+func (a *AppState) WriteRelays() []string {
+	var urls []string
+	for url, r := range a.Relays {
+		if r.Write {
+			urls = append(urls, url)
+		}
+	}
+	sort.Strings(urls)
+	return urls
+}
+
+// ReadRelays devolve, em ordem alfabética, as URLs dos relays marcados para leitura.
+// O chamador deve segurar o Mutex.
+func (a *AppState) ReadRelays() []string {
+	var urls []string
+	for url, r := range a.Relays {
+		if r.Read {
+			urls = append(urls, url)
+		}
+	}
+	sort.Strings(urls)
+	return urls
 }
 
 type PreEvent struct {
-	Sha256, MimeType, BlurHash, Path, PubKey, PrivKey string
-	Size                                              int64
-	Tags, Indexers                                    []string
-	Nsfw                                              bool
-	Kind                                              int
+	Sha256, MimeType, BlurHash, Dim, Path, PubKey, PrivKey string
+	Size                                                   int64
+	Tags, Indexers                                         []string
+	Nsfw                                                   bool
+	Kind                                                   int
 }
 
 //	{
@@ -0,0 +1,94 @@
+package mpegaudio
+
+// Taxas de bits, em kbps, por índice do cabeçalho do quadro.
+var (
+	bitratesV1 = [3][16]int{
+		{0, 32, 64, 96, 128, 160, 192, 224, 256, 288, 320, 352, 384, 416, 448},
+		{0, 32, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 384},
+		{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320},
+	}
+	bitratesV2 = [3][16]int{
+		{0, 32, 48, 56, 64, 80, 96, 112, 128, 144, 160, 176, 192, 224, 256},
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+	}
+	sampleRates = [3]int{44100, 48000, 32000}
+)
+
+// Frame é o cabeçalho de um quadro MPEG de áudio (MP1, MP2 ou MP3).
+type Frame struct {
+	MPEG1      bool
+	Layer      int // 1, 2 ou 3
+	Bitrate    int // bits por segundo
+	SampleRate int
+	Channels   int
+	Samples    int // amostras por quadro
+	Length     int // bytes do quadro, com o preenchimento
+}
+
+// ParseFrame interpreta o cabeçalho de quatro bytes no início de h. Versão,
+// camada, taxa de bits e taxa de amostragem reservadas são rejeitadas, assim
+// como a taxa de bits livre, cujo tamanho de quadro não pode ser calculado.
+func ParseFrame(h []byte) (Frame, bool) {
+	var f Frame
+	if len(h) < 4 || h[0] != 0xFF || h[1]&0xE0 != 0xE0 {
+		return f, false
+	}
+	version := h[1] >> 3 & 0x03 // 0: MPEG 2.5, 2: MPEG 2, 3: MPEG 1
+	layerBits := h[1] >> 1 & 0x03
+	bitrateIndex := h[2] >> 4
+	rateIndex := h[2] >> 2 & 0x03
+	if version == 1 || layerBits == 0 || bitrateIndex == 0 || bitrateIndex == 15 || rateIndex == 3 {
+		return f, false
+	}
+
+	f.MPEG1 = version == 3
+	f.Layer = 4 - int(layerBits)
+	if f.MPEG1 {
+		f.Bitrate = bitratesV1[f.Layer-1][bitrateIndex] * 1000
+	} else {
+		f.Bitrate = bitratesV2[f.Layer-1][bitrateIndex] * 1000
+	}
+	f.SampleRate = sampleRates[rateIndex]
+	switch version {
+	case 2:
+		f.SampleRate /= 2
+	case 0:
+		f.SampleRate /= 4
+	}
+	f.Channels = 2
+	if h[3]>>6 == 3 {
+		f.Channels = 1
+	}
+	switch {
+	case f.Layer == 1:
+		f.Samples = 384
+	case f.Layer == 3 && !f.MPEG1:
+		f.Samples = 576
+	default:
+		f.Samples = 1152
+	}
+	padding := int(h[2] >> 1 & 0x01)
+	if f.Layer == 1 {
+		f.Length = (f.Samples/32*f.Bitrate/f.SampleRate + padding) * 4
+	} else {
+		f.Length = f.Samples/8*f.Bitrate/f.SampleRate + padding
+	}
+	return f, true
+}
+
+// IsStream informa se h, o início de um arquivo de size bytes, começa com um
+// quadro MPEG válido que cabe no arquivo; size <= 0 indica tamanho
+// desconhecido. Se h alcança o quadro seguinte, ele também precisa ser
+// válido; assim, textos que começam com 0xFF, como os com BOM UTF-16, não são
+// confundidos com áudio.
+func IsStream(h []byte, size int64) bool {
+	f, ok := ParseFrame(h)
+	if !ok || (size > 0 && int64(f.Length) > size) {
+		return false
+	}
+	if next := h[min(f.Length, len(h)):]; len(next) >= 4 {
+		_, ok = ParseFrame(next)
+	}
+	return ok
+}
@@ -0,0 +1,28 @@
+package mpegaudio
+
+import "testing"
+
+func TestParseFrame(t *testing.T) {
+	tests := []struct {
+		name   string
+		header []byte
+		want   int
+	}{
+		{"MPEG-1 Layer III 128 kbps 44,1 kHz", []byte{0xFF, 0xFB, 0x90, 0x64}, 417},
+		{"com preenchimento", []byte{0xFF, 0xFB, 0x92, 0x64}, 418},
+		{"MPEG-1 Layer III 320 kbps 48 kHz", []byte{0xFF, 0xFB, 0xE4, 0x64}, 960},
+		{"MPEG-2 Layer III 64 kbps 22,05 kHz", []byte{0xFF, 0xF3, 0x80, 0x64}, 208},
+		{"MPEG-1 Layer II 192 kbps 48 kHz", []byte{0xFF, 0xFD, 0xA4, 0x04}, 576},
+		{"MPEG-1 Layer I 32 kbps 32 kHz", []byte{0xFF, 0xFF, 0x18, 0x04}, 48},
+		{"camada reservada", []byte{0xFF, 0xF9, 0x90, 0x64}, 0},
+		{"sem sincronismo", []byte{0xFF, 0x1B, 0x90, 0x64}, 0},
+		{"curto", []byte{0xFF, 0xFB}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if f, _ := ParseFrame(tt.header); f.Length != tt.want {
+				t.Errorf("ParseFrame(%x).Length = %d, want %d", tt.header, f.Length, tt.want)
+			}
+		})
+	}
+}
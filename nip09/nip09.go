@@ -0,0 +1,42 @@
+package nip09
+
+import (
+	"fmt"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// KindDeletion é o kind do pedido de exclusão da NIP-09.
+const KindDeletion = nostr.KindDeletion
+
+// Request monta, sem assinar, o pedido de exclusão (kind 5) do evento. O
+// evento é referenciado pelo id na tag "e" e, se for endereçável ou
+// substituível, também pela coordenada na tag "a", que apaga as versões
+// anteriores à data do pedido. reason, se não for vazio, vai no conteúdo.
+func Request(evt nostr.Event, reason string) nostr.Event {
+	tags := nostr.Tags{{"e", evt.ID}}
+	if coordinate := Coordinate(evt); coordinate != "" {
+		tags = append(tags, nostr.Tag{"a", coordinate})
+	}
+	tags = append(tags, nostr.Tag{"k", fmt.Sprintf("%d", evt.Kind)})
+	return nostr.Event{
+		Kind:      KindDeletion,
+		Content:   reason,
+		Tags:      tags,
+		CreatedAt: nostr.Now(),
+		PubKey:    evt.PubKey,
+	}
+}
+
+// Coordinate devolve a coordenada "<kind>:<pubkey>:<d>" de um evento
+// endereçável ou substituível, ou "" para eventos regulares.
+func Coordinate(evt nostr.Event) string {
+	switch {
+	case nostr.IsAddressableKind(evt.Kind):
+		return fmt.Sprintf("%d:%s:%s", evt.Kind, evt.PubKey, evt.Tags.GetD())
+	case nostr.IsReplaceableKind(evt.Kind):
+		return fmt.Sprintf("%d:%s:", evt.Kind, evt.PubKey)
+	default:
+		return ""
+	}
+}
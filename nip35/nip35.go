@@ -0,0 +1,41 @@
+package nip35
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// KindTorrent é o kind dos eventos de torrent da NIP-35.
+const KindTorrent = 2003
+
+// File é um arquivo listado no torrent.
+type File struct {
+	Name string
+	Size int64
+}
+
+// Event monta o evento de torrent, ainda sem assinatura. infoHash é o
+// infohash v1 em hexadecimal, usado na tag "x".
+func Event(title, description, infoHash string, files []File, trackers, hashtags []string) nostr.Event {
+	var tags nostr.Tags
+	if title != "" {
+		tags = append(tags, nostr.Tag{"title", title})
+	}
+	tags = append(tags, nostr.Tag{"x", strings.ToLower(infoHash)})
+	for _, f := range files {
+		tags = append(tags, nostr.Tag{"file", f.Name, strconv.FormatInt(f.Size, 10)})
+	}
+	for _, tr := range trackers {
+		tags = append(tags, nostr.Tag{"tracker", tr})
+	}
+	for _, h := range hashtags {
+		tags = append(tags, nostr.Tag{"t", strings.ToLower(h)})
+	}
+	return nostr.Event{
+		Kind:    KindTorrent,
+		Content: description,
+		Tags:    tags,
+	}
+}
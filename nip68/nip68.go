@@ -0,0 +1,88 @@
+package nip68
+
+import (
+	"slices"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// KindPicture é o kind dos posts de fotos da NIP-68.
+const KindPicture = 20
+
+// MimeTypes são os formatos de imagem aceitos em posts de fotos.
+var MimeTypes = []string{"image/apng", "image/avif", "image/gif", "image/jpeg", "image/png", "image/webp"}
+
+// Supported informa se o tipo MIME pode ser publicado em um post de fotos.
+func Supported(mimeType string) bool {
+	return slices.Contains(MimeTypes, mimeType)
+}
+
+// Picture descreve uma imagem de um post de fotos.
+type Picture struct {
+	URL      string
+	MimeType string
+	Sha256   string
+
+	// Dim são as dimensões no formato "<largura>x<altura>".
+	Dim      string
+	BlurHash string
+
+	// Alt é a descrição da imagem para acessibilidade.
+	Alt string
+
+	// Fallbacks são URLs alternativas para o mesmo arquivo.
+	Fallbacks []string
+
+	// Identifiers são identificadores externos do arquivo (campo "i"), como
+	// "ipfs:<cid>".
+	Identifiers []string
+}
+
+// IMeta monta a tag imeta da imagem.
+func (p Picture) IMeta() nostr.Tag {
+	tag := nostr.Tag{"imeta", "url " + p.URL}
+	if p.MimeType != "" {
+		tag = append(tag, "m "+p.MimeType)
+	}
+	if p.BlurHash != "" {
+		tag = append(tag, "blurhash "+p.BlurHash)
+	}
+	if p.Dim != "" {
+		tag = append(tag, "dim "+p.Dim)
+	}
+	if p.Alt != "" {
+		tag = append(tag, "alt "+p.Alt)
+	}
+	if p.Sha256 != "" {
+		tag = append(tag, "x "+p.Sha256)
+	}
+	for _, fb := range p.Fallbacks {
+		tag = append(tag, "fallback "+fb)
+	}
+	for _, id := range p.Identifiers {
+		tag = append(tag, "i "+id)
+	}
+	return tag
+}
+
+// Tags devolve uma tag imeta por imagem, seguidas das tags "m" e "x" que
+// permitem filtrar os posts por formato e por hash.
+func Tags(pictures []Picture) nostr.Tags {
+	var tags nostr.Tags
+	for _, p := range pictures {
+		tags = append(tags, p.IMeta())
+	}
+	var mimeTypes []string
+	for _, p := range pictures {
+		if p.MimeType != "" && !slices.Contains(mimeTypes, p.MimeType) {
+			mimeTypes = append(mimeTypes, p.MimeType)
+			tags = append(tags, nostr.Tag{"m", p.MimeType})
+		}
+	}
+	for _, p := range pictures {
+		if p.Sha256 != "" {
+			tags = append(tags, nostr.Tag{"x", p.Sha256})
+		}
+	}
+	return tags
+}
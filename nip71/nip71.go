@@ -0,0 +1,207 @@
+package nip71
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// Kinds de vídeo da NIP-71. Os kinds 21 e 22 são eventos regulares; 34235 e
+// 34236 são as versões endereçáveis, que podem ser atualizadas pela tag "d".
+const (
+	KindVideo                 = 21
+	KindShortVideo            = 22
+	KindAddressableVideo      = nostr.KindVideoEvent
+	KindAddressableShortVideo = nostr.KindShortVideoEvent
+)
+
+// ShortFormMaxDuration é a duração máxima, em segundos, de um vídeo curto.
+const ShortFormMaxDuration = 60
+
+// IsAddressable informa se o kind é de um vídeo endereçável.
+func IsAddressable(kind int) bool {
+	return kind == KindAddressableVideo || kind == KindAddressableShortVideo
+}
+
+// IsShortForm decide se o vídeo é de formato curto: vídeos com duração de até
+// ShortFormMaxDuration segundos ou, se a duração for desconhecida, vídeos
+// verticais. Um vídeo vertical mais longo que isso não é curto.
+func IsShortForm(width, height int, duration float64) bool {
+	if duration > 0 {
+		return duration <= ShortFormMaxDuration
+	}
+	return height > width
+}
+
+// ChooseKind escolhe o kind NIP-71 a partir da orientação e da duração da
+// variante principal. Sem dimensões nem duração conhecidas, o vídeo é tratado
+// como normal (não curto).
+func ChooseKind(primary Variant, addressable bool) int {
+	width, height, _ := ParseDim(primary.Dim)
+	short := IsShortForm(width, height, primary.Duration)
+	switch {
+	case short && addressable:
+		return KindAddressableShortVideo
+	case addressable:
+		return KindAddressableVideo
+	case short:
+		return KindShortVideo
+	default:
+		return KindVideo
+	}
+}
+
+// Variant descreve uma versão (resolução) de um vídeo publicada em um evento
+// NIP-71. Cada variante gera uma tag imeta própria.
+type Variant struct {
+	URL      string
+	MimeType string
+	Sha256   string
+	Size     int64
+
+	// Dim são as dimensões no formato "<largura>x<altura>", ex: "1920x1080".
+	Dim string
+
+	// Duration é a duração em segundos.
+	Duration float64
+
+	// Images são URLs de imagens de pré-visualização do vídeo.
+	Images []string
+
+	// BlurHash é o BlurHash da imagem de pré-visualização.
+	BlurHash string
+
+	// Fallbacks são URLs alternativas para o mesmo arquivo.
+	Fallbacks []string
+
+	// Identifiers são identificadores externos do arquivo (campo "i"), como
+	// "ipfs:<cid>".
+	Identifiers []string
+}
+
+// Image descreve uma imagem de capa ou miniatura do vídeo, com o hash do
+// próprio arquivo e URLs alternativas em outros servidores.
+type Image struct {
+	URL       string
+	Sha256    string
+	Fallbacks []string
+
+	// BlurHash e Dim são calculados a partir dos dados da imagem.
+	BlurHash string
+	Dim      string
+}
+
+// URLs devolve a URL principal seguida das alternativas, no formato usado nos
+// campos "image" da tag imeta.
+func (i Image) URLs() []string {
+	if i.URL == "" {
+		return nil
+	}
+	return append([]string{i.URL}, i.Fallbacks...)
+}
+
+// Tag monta a tag de nível superior (ex: "image" ou "thumb") com a URL e,
+// quando conhecido, o hash SHA-256 da imagem.
+func (i Image) Tag(name string) nostr.Tag {
+	tag := nostr.Tag{name, i.URL}
+	if i.Sha256 != "" {
+		tag = append(tag, i.Sha256)
+	}
+	return tag
+}
+
+// Label devolve uma descrição curta da variante para exibição na UI.
+func (v Variant) Label() string {
+	dim := v.Dim
+	if dim == "" {
+		dim = "dimensões desconhecidas"
+	}
+	return fmt.Sprintf("%s | %s | %s", dim, v.MimeType, v.URL)
+}
+
+// IMeta monta a tag imeta da variante conforme a NIP-71 e a NIP-92.
+func (v Variant) IMeta() nostr.Tag {
+	tag := nostr.Tag{"imeta", "url " + v.URL}
+	if v.MimeType != "" {
+		tag = append(tag, "m "+v.MimeType)
+	}
+	if v.Sha256 != "" {
+		tag = append(tag, "x "+v.Sha256)
+	}
+	if v.Size > 0 {
+		tag = append(tag, fmt.Sprintf("size %d", v.Size))
+	}
+	if v.Dim != "" {
+		tag = append(tag, "dim "+v.Dim)
+	}
+	if v.Duration > 0 {
+		tag = append(tag, "duration "+FormatDuration(v.Duration))
+	}
+	for _, img := range v.Images {
+		tag = append(tag, "image "+img)
+	}
+	if v.BlurHash != "" {
+		tag = append(tag, "blurhash "+v.BlurHash)
+	}
+	for _, fb := range v.Fallbacks {
+		tag = append(tag, "fallback "+fb)
+	}
+	for _, id := range v.Identifiers {
+		tag = append(tag, "i "+id)
+	}
+	return tag
+}
+
+// Tags devolve uma tag imeta por variante. Se legacy for verdadeiro, as tags
+// url, m, x, size e fallback da primeira variante também são emitidas no nível
+// superior do evento, para clientes que ainda não leem imeta.
+func Tags(variants []Variant, legacy bool) nostr.Tags {
+	var tags nostr.Tags
+	for _, v := range variants {
+		tags = append(tags, v.IMeta())
+	}
+	if !legacy || len(variants) == 0 {
+		return tags
+	}
+
+	primary := variants[0]
+	tags = append(tags, nostr.Tag{"url", primary.URL})
+	if primary.MimeType != "" {
+		tags = append(tags, nostr.Tag{"m", primary.MimeType})
+	}
+	if primary.Sha256 != "" {
+		tags = append(tags, nostr.Tag{"x", primary.Sha256})
+	}
+	if primary.Size > 0 {
+		tags = append(tags, nostr.Tag{"size", fmt.Sprintf("%d", primary.Size)})
+	}
+	for _, fb := range primary.Fallbacks {
+		tags = append(tags, nostr.Tag{"fallback", fb})
+	}
+	return tags
+}
+
+// FormatDuration formata uma duração em segundos com até três casas decimais.
+func FormatDuration(seconds float64) string {
+	return strconv.FormatFloat(math.Round(seconds*1000)/1000, 'f', -1, 64)
+}
+
+// ParseDim valida e normaliza dimensões no formato "<largura>x<altura>".
+func ParseDim(s string) (width, height int, err error) {
+	parts := strings.Split(strings.ToLower(strings.TrimSpace(s)), "x")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("dimensões inválidas %q, use o formato 1920x1080", s)
+	}
+	width, err = strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil || width <= 0 {
+		return 0, 0, fmt.Errorf("largura inválida em %q", s)
+	}
+	height, err = strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err != nil || height <= 0 {
+		return 0, 0, fmt.Errorf("altura inválida em %q", s)
+	}
+	return width, height, nil
+}
@@ -0,0 +1,30 @@
+package nip71
+
+import "testing"
+
+func TestChooseKind(t *testing.T) {
+	tests := []struct {
+		name        string
+		dim         string
+		duration    float64
+		addressable bool
+		want        int
+	}{
+		{"horizontal longo", "1920x1080", 600, false, KindVideo},
+		{"horizontal curto", "1920x1080", 30, false, KindShortVideo},
+		{"vertical curto", "1080x1920", 45, false, KindShortVideo},
+		{"vertical longo", "1080x1920", 40 * 60, false, KindVideo},
+		{"vertical sem duração", "1080x1920", 0, false, KindShortVideo},
+		{"no limite", "1920x1080", ShortFormMaxDuration, false, KindShortVideo},
+		{"sem dimensões nem duração", "", 0, false, KindVideo},
+		{"endereçável curto", "1080x1920", 15, true, KindAddressableShortVideo},
+		{"endereçável longo", "1080x1920", 3600, true, KindAddressableVideo},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ChooseKind(Variant{Dim: tt.dim, Duration: tt.duration}, tt.addressable); got != tt.want {
+				t.Errorf("ChooseKind = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package nip71
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// ParseIMeta interpreta uma tag imeta, o inverso de Variant.IMeta. Devolve
+// false se a tag não for imeta ou não tiver URL.
+func ParseIMeta(tag nostr.Tag) (Variant, bool) {
+	var v Variant
+	if len(tag) < 2 || tag[0] != "imeta" {
+		return v, false
+	}
+	for _, field := range tag[1:] {
+		key, value, ok := strings.Cut(field, " ")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch key {
+		case "url":
+			v.URL = value
+		case "m":
+			v.MimeType = value
+		case "x":
+			v.Sha256 = value
+		case "size":
+			v.Size, _ = strconv.ParseInt(value, 10, 64)
+		case "dim":
+			v.Dim = value
+		case "duration":
+			v.Duration, _ = strconv.ParseFloat(value, 64)
+		case "image":
+			v.Images = append(v.Images, value)
+		case "blurhash":
+			v.BlurHash = value
+		case "fallback":
+			v.Fallbacks = append(v.Fallbacks, value)
+		case "i":
+			v.Identifiers = append(v.Identifiers, value)
+		}
+	}
+	return v, v.URL != ""
+}
+
+// ParseVariants lê as variantes de um evento de vídeo: uma por tag imeta ou,
+// em eventos antigos sem imeta, a partir das tags url, m, x, size e fallback.
+func ParseVariants(tags nostr.Tags) []Variant {
+	var variants []Variant
+	for _, tag := range tags {
+		if v, ok := ParseIMeta(tag); ok {
+			variants = append(variants, v)
+		}
+	}
+	if len(variants) > 0 {
+		return variants
+	}
+
+	var v Variant
+	for _, tag := range tags {
+		if len(tag) < 2 {
+			continue
+		}
+		switch tag[0] {
+		case "url":
+			v.URL = tag[1]
+		case "m":
+			v.MimeType = tag[1]
+		case "x":
+			v.Sha256 = tag[1]
+		case "size":
+			v.Size, _ = strconv.ParseInt(tag[1], 10, 64)
+		case "fallback":
+			v.Fallbacks = append(v.Fallbacks, tag[1])
+		}
+	}
+	if v.URL == "" {
+		return nil
+	}
+	return []Variant{v}
+}
+
+// ParseImage lê uma tag "image" ou "thumb" no formato de Image.Tag. As URLs
+// alternativas são recuperadas das imagens da variante, quando ela começa pela
+// mesma URL.
+func ParseImage(tag nostr.Tag, variant Variant) Image {
+	var img Image
+	if len(tag) < 2 {
+		return img
+	}
+	img.URL = tag[1]
+	if len(tag) > 2 {
+		img.Sha256 = tag[2]
+	}
+	if len(variant.Images) > 1 && variant.Images[0] == img.URL {
+		img.Fallbacks = append([]string(nil), variant.Images[1:]...)
+	}
+	return img
+}
@@ -0,0 +1,86 @@
+package nipa0
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// KindVoiceMessage é o kind das mensagens de voz da NIP-A0.
+const KindVoiceMessage = 1222
+
+// MaxDuration é a duração máxima, em segundos, recomendada para uma mensagem de voz.
+const MaxDuration = 60
+
+// WaveformPoints é o número de amplitudes da forma de onda. A NIP-A0 considera
+// menos de 100 valores suficientes para desenhá-la.
+const WaveformPoints = 64
+
+// VoiceMessage descreve o áudio de uma mensagem de voz.
+type VoiceMessage struct {
+	URL      string
+	MimeType string
+	Sha256   string
+	Size     int64
+
+	// Duration é a duração em segundos.
+	Duration float64
+
+	// Waveform são as amplitudes, entre 0 e 100, ao longo do áudio.
+	Waveform []int
+
+	// Fallbacks são URLs alternativas para o mesmo arquivo.
+	Fallbacks []string
+
+	// Identifiers são identificadores externos do arquivo (campo "i"), como
+	// "ipfs:<cid>".
+	Identifiers []string
+}
+
+// IMeta monta a tag imeta do áudio, com a forma de onda e a duração em
+// segundos inteiros.
+func (v VoiceMessage) IMeta() nostr.Tag {
+	tag := nostr.Tag{"imeta", "url " + v.URL}
+	if v.MimeType != "" {
+		tag = append(tag, "m "+v.MimeType)
+	}
+	if v.Sha256 != "" {
+		tag = append(tag, "x "+v.Sha256)
+	}
+	if v.Size > 0 {
+		tag = append(tag, "size "+strconv.FormatInt(v.Size, 10))
+	}
+	if len(v.Waveform) > 0 {
+		values := make([]string, len(v.Waveform))
+		for i, w := range v.Waveform {
+			values[i] = strconv.Itoa(w)
+		}
+		tag = append(tag, "waveform "+strings.Join(values, " "))
+	}
+	if v.Duration > 0 {
+		tag = append(tag, "duration "+strconv.Itoa(max(int(math.Round(v.Duration)), 1)))
+	}
+	for _, fb := range v.Fallbacks {
+		tag = append(tag, "fallback "+fb)
+	}
+	for _, id := range v.Identifiers {
+		tag = append(tag, "i "+id)
+	}
+	return tag
+}
+
+// Event monta a mensagem de voz, ainda sem assinatura. O conteúdo é apenas a
+// URL do áudio, como exige a NIP-A0.
+func Event(v VoiceMessage, hashtags []string) nostr.Event {
+	tags := nostr.Tags{v.IMeta()}
+	for _, h := range hashtags {
+		tags = append(tags, nostr.Tag{"t", strings.ToLower(h)})
+	}
+	return nostr.Event{
+		Kind:    KindVoiceMessage,
+		Content: v.URL,
+		Tags:    tags,
+	}
+}
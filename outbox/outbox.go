@@ -0,0 +1,354 @@
+package outbox
+
+import (
+	"NostrFilePublisher/model"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// ItemType distingue os tipos de trabalho guardados na fila.
+type ItemType string
+
+const (
+	// TypeEvent é um evento já assinado aguardando publicação em relays.
+	TypeEvent ItemType = "event"
+
+	// TypeUpload é um arquivo local aguardando envio para servidores Blossom.
+	TypeUpload ItemType = "upload"
+)
+
+// Item é uma entrada da fila de saída. Os campos preenchidos dependem de Type.
+type Item struct {
+	ID        string    `json:"id"`
+	Type      ItemType  `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Event e PendingRelays são usados por itens do tipo TypeEvent. Um evento
+	// com WaitUploads só é publicado depois que esses uploads terminam.
+	Event         *nostr.Event `json:"event,omitempty"`
+	PendingRelays []string     `json:"pending_relays,omitempty"`
+	DoneRelays    []string     `json:"done_relays,omitempty"`
+	WaitUploads   []string     `json:"wait_uploads,omitempty"`
+
+	// PreEvent, PendingServers e Responses são usados por itens do tipo
+	// TypeUpload. URLs associa a URL prevista em cada servidor (usada nos
+	// eventos preparados antes do envio) à URL devolvida por ele. Arquivos
+	// temporários são apagados quando o envio termina ou é descartado.
+	PreEvent       *model.PreEvent         `json:"pre_event,omitempty"`
+	PendingServers []string                `json:"pending_servers,omitempty"`
+	Responses      []model.BlossomResponse `json:"responses,omitempty"`
+	URLs           map[string]string       `json:"urls,omitempty"`
+	Temporary      bool                    `json:"temporary,omitempty"`
+
+	Attempts    int       `json:"attempts"`
+	LastAttempt time.Time `json:"last_attempt"`
+	LastError   string    `json:"last_error,omitempty"`
+}
+
+// Done informa se não há mais relays ou servidores pendentes para o item.
+func (it Item) Done() bool {
+	return len(it.PendingRelays) == 0 && len(it.PendingServers) == 0
+}
+
+// Description devolve um resumo legível do item para exibição na UI.
+func (it Item) Description() string {
+	switch it.Type {
+	case TypeEvent:
+		id := it.Event.ID
+		if len(id) > 12 {
+			id = id[:12]
+		}
+		if len(it.WaitUploads) > 0 {
+			return fmt.Sprintf("Evento kind %d (%s...) - aguardando %d upload(s)", it.Event.Kind, id, len(it.WaitUploads))
+		}
+		return fmt.Sprintf("Evento kind %d (%s...) - %d relay(s) pendente(s)", it.Event.Kind, id, len(it.PendingRelays))
+	case TypeUpload:
+		return fmt.Sprintf("Upload %s - %d servidor(es) pendente(s)", filepath.Base(it.PreEvent.Path), len(it.PendingServers))
+	}
+	return string(it.Type)
+}
+
+// Queue é a fila de saída persistente. Todas as alterações são gravadas
+// imediatamente em disco, de modo que a fila sobrevive a reinícios.
+type Queue struct {
+	mu    sync.Mutex
+	path  string
+	items []*Item
+
+	// OnChange, se definido, é chamado depois de qualquer alteração na fila.
+	OnChange func()
+}
+
+// Open carrega a fila a partir do arquivo informado, criando uma fila vazia
+// caso o arquivo ainda não exista.
+func Open(path string) (*Queue, error) {
+	q := &Queue{path: path}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return q, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading outbox %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &q.items); err != nil {
+		return nil, fmt.Errorf("error decoding outbox %s: %w", path, err)
+	}
+	return q, nil
+}
+
+// NewMemory cria uma fila vazia mantida apenas na memória, usada quando o
+// arquivo da fila não pode ser aberto. Os itens se perdem ao fechar o aplicativo.
+func NewMemory() *Queue {
+	return &Queue{}
+}
+
+// AddEvent enfileira um evento assinado para publicação nos relays informados.
+func (q *Queue) AddEvent(evt nostr.Event, relays []string) error {
+	return q.add(&Item{
+		ID:            "evt-" + evt.ID,
+		Type:          TypeEvent,
+		Event:         &evt,
+		PendingRelays: relays,
+	})
+}
+
+// AddEventAfter enfileira um evento que só deve ser publicado depois que os
+// uploads informados terminarem. Se algum servidor devolver uma URL diferente
+// da prevista, o evento é assinado de novo com as URLs definitivas.
+func (q *Queue) AddEventAfter(evt nostr.Event, relays, uploads []string) error {
+	return q.add(&Item{
+		ID:            "evt-" + evt.ID,
+		Type:          TypeEvent,
+		Event:         &evt,
+		PendingRelays: relays,
+		WaitUploads:   uploads,
+	})
+}
+
+// PendingUploads devolve os IDs dos uploads pendentes de arquivos referenciados
+// pelo evento, reconhecidos pelo hash SHA-256 nas tags ou no conteúdo.
+func (q *Queue) PendingUploads(evt nostr.Event) []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var ids []string
+	for _, it := range q.items {
+		if it.Type != TypeUpload || it.Done() || it.PreEvent.Sha256 == "" {
+			continue
+		}
+		if references(evt, it.PreEvent.Sha256) {
+			ids = append(ids, it.ID)
+		}
+	}
+	return ids
+}
+
+// references informa se o conteúdo ou alguma tag do evento contém s.
+func references(evt nostr.Event, s string) bool {
+	if strings.Contains(evt.Content, s) {
+		return true
+	}
+	for _, tag := range evt.Tags {
+		for _, v := range tag {
+			if strings.Contains(v, s) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// AdoptFile passa à fila a responsabilidade por um arquivo temporário: os
+// uploads pendentes desse arquivo o apagam quando terminarem. Devolve false se
+// nenhum upload pendente usa o arquivo, que então pode ser apagado pelo chamador.
+func (q *Queue) AdoptFile(path string) (bool, error) {
+	q.mu.Lock()
+	adopted := false
+	for _, it := range q.items {
+		if it.Type == TypeUpload && !it.Done() && it.PreEvent.Path == path {
+			it.Temporary = true
+			adopted = true
+		}
+	}
+	var err error
+	if adopted {
+		err = q.saveLocked()
+	}
+	q.mu.Unlock()
+	return adopted, err
+}
+
+// AddUpload enfileira o envio de um arquivo local para os servidores informados.
+func (q *Queue) AddUpload(preEvt model.PreEvent, servers []string) error {
+	// A chave privada nunca é gravada em disco; a assinatura usa o estado atual.
+	preEvt.PrivKey = ""
+	return q.add(&Item{
+		ID:             fmt.Sprintf("upload-%s-%d", preEvt.Sha256, time.Now().UnixNano()),
+		Type:           TypeUpload,
+		PreEvent:       &preEvt,
+		PendingServers: servers,
+	})
+}
+
+// add insere o item na fila. Um evento já enfileirado tem seus relays pendentes mesclados.
+func (q *Queue) add(item *Item) error {
+	q.mu.Lock()
+	item.CreatedAt = time.Now()
+	merged := false
+	for _, existing := range q.items {
+		if existing.ID == item.ID {
+			existing.PendingRelays = appendMissing(existing.PendingRelays, item.PendingRelays...)
+			existing.WaitUploads = appendMissing(existing.WaitUploads, item.WaitUploads...)
+			merged = true
+			break
+		}
+	}
+	if !merged {
+		q.items = append(q.items, item)
+	}
+	err := q.saveLocked()
+	q.mu.Unlock()
+
+	q.changed()
+	return err
+}
+
+// Remove descarta o item com o ID informado, apagando o arquivo de um upload temporário.
+func (q *Queue) Remove(id string) error {
+	q.mu.Lock()
+	var removeErr error
+	for i, it := range q.items {
+		if it.ID == id {
+			q.items = append(q.items[:i], q.items[i+1:]...)
+			removeErr = removeTemporary(it)
+			break
+		}
+	}
+	err := errors.Join(q.saveLocked(), removeErr)
+	q.mu.Unlock()
+
+	q.changed()
+	return err
+}
+
+// prune remove os itens concluídos há mais de retention. Uploads ainda
+// aguardados por algum evento são mantidos, pois o evento precisa das URLs.
+func (q *Queue) prune(retention time.Duration) error {
+	q.mu.Lock()
+	awaited := make(map[string]bool)
+	for _, it := range q.items {
+		for _, id := range it.WaitUploads {
+			awaited[id] = true
+		}
+	}
+	n := len(q.items)
+	var removeErrs []error
+	q.items = slices.DeleteFunc(q.items, func(it *Item) bool {
+		if !it.Done() || awaited[it.ID] || time.Since(it.LastAttempt) < retention {
+			return false
+		}
+		removeErrs = append(removeErrs, removeTemporary(it))
+		return true
+	})
+	if len(q.items) == n {
+		q.mu.Unlock()
+		return nil
+	}
+	err := errors.Join(append(removeErrs, q.saveLocked())...)
+	q.mu.Unlock()
+
+	q.changed()
+	return err
+}
+
+// removeTemporary apaga o arquivo de um upload temporário. Um arquivo que já
+// não existe não é considerado erro.
+func removeTemporary(it *Item) error {
+	if it.Type != TypeUpload || !it.Temporary {
+		return nil
+	}
+	if err := os.Remove(it.PreEvent.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("error removing temporary file: %w", err)
+	}
+	return nil
+}
+
+// Items devolve uma cópia dos itens da fila, na ordem de inserção.
+func (q *Queue) Items() []Item {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	out := make([]Item, len(q.items))
+	for i, it := range q.items {
+		out[i] = *it
+	}
+	return out
+}
+
+// update aplica fn ao item com o ID informado e grava a fila.
+func (q *Queue) update(id string, fn func(it *Item)) error {
+	q.mu.Lock()
+	for _, it := range q.items {
+		if it.ID == id {
+			fn(it)
+			break
+		}
+	}
+	err := q.saveLocked()
+	q.mu.Unlock()
+
+	q.changed()
+	return err
+}
+
+// saveLocked grava a fila em um arquivo temporário e o renomeia por cima do
+// original, para que uma interrupção não corrompa a fila. Filas em memória não
+// são gravadas. O chamador deve segurar mu.
+func (q *Queue) saveLocked() error {
+	if q.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(q.items, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding outbox: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(q.path), 0o700); err != nil {
+		return fmt.Errorf("error creating outbox directory: %w", err)
+	}
+	tmp := q.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("error writing outbox: %w", err)
+	}
+	return os.Rename(tmp, q.path)
+}
+
+func (q *Queue) changed() {
+	if q.OnChange != nil {
+		q.OnChange()
+	}
+}
+
+// appendMissing acrescenta a dst os valores que ainda não estão presentes.
+func appendMissing(dst []string, values ...string) []string {
+	for _, v := range values {
+		found := false
+		for _, d := range dst {
+			if d == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			dst = append(dst, v)
+		}
+	}
+	return dst
+}
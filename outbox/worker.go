@@ -0,0 +1,288 @@
+package outbox
+
+import (
+	"NostrFilePublisher/blossom"
+	"NostrFilePublisher/model"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+const (
+	// baseBackoff é o intervalo mínimo entre duas tentativas automáticas do mesmo item.
+	baseBackoff = 30 * time.Second
+
+	// maxBackoff limita o crescimento do intervalo entre tentativas.
+	maxBackoff = 15 * time.Minute
+
+	// doneRetention é o tempo em que os itens concluídos continuam visíveis na
+	// fila antes de serem removidos.
+	doneRetention = 24 * time.Hour
+)
+
+// Handlers contém as operações de rede usadas pelo Worker. Elas são injetadas
+// para que a fila não dependa diretamente do estado global da aplicação.
+type Handlers struct {
+	// PublishEvent publica um evento em um único relay.
+	PublishEvent func(evt nostr.Event, relayURL string) error
+
+	// Upload envia um arquivo para um único servidor Blossom.
+	Upload func(preEvt model.PreEvent, server string) (*model.BlossomResponse, error)
+
+	// Sign assina de novo um evento cujas URLs mudaram depois dos uploads de
+	// que ele dependia.
+	Sign func(evt *nostr.Event) error
+}
+
+// Worker processa periodicamente os itens pendentes da fila, repetindo os
+// relays e servidores que falharam até que todos aceitem o conteúdo.
+type Worker struct {
+	// mu serializa as tentativas, evitando envios duplicados quando uma
+	// tentativa manual coincide com o processamento periódico.
+	mu       sync.Mutex
+	queue    *Queue
+	handlers Handlers
+	wake     chan struct{}
+}
+
+// NewWorker cria um Worker para a fila informada.
+func NewWorker(q *Queue, h Handlers) *Worker {
+	return &Worker{queue: q, handlers: h, wake: make(chan struct{}, 1)}
+}
+
+// Run processa a fila a cada intervalo até que ctx seja cancelado.
+// Como as falhas de conexão simplesmente mantêm os itens pendentes, os envios
+// são concluídos automaticamente quando a conectividade volta.
+func (w *Worker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	force := false
+	for {
+		w.processAll(force)
+		force = false
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-w.wake:
+			force = true
+		}
+	}
+}
+
+// Wake pede ao Worker que processe imediatamente todos os itens pendentes,
+// ignorando o intervalo de espera entre tentativas.
+func (w *Worker) Wake() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Retry processa imediatamente o item informado, ignorando o intervalo de espera.
+func (w *Worker) Retry(id string) {
+	w.process(id)
+}
+
+// processAll remove os itens concluídos antigos e processa os pendentes cujo
+// intervalo de espera já passou.
+func (w *Worker) processAll(force bool) {
+	w.queue.prune(doneRetention)
+	for _, it := range w.queue.Items() {
+		if it.Done() {
+			continue
+		}
+		if !force && time.Since(it.LastAttempt) < backoff(it.Attempts) {
+			continue
+		}
+		w.process(it.ID)
+	}
+}
+
+// process faz uma tentativa de envio para cada relay ou servidor pendente do item.
+func (w *Worker) process(id string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	// O item é relido depois de obter o lock, pois outra tentativa pode tê-lo alterado.
+	var (
+		it    Item
+		found bool
+	)
+	for _, stored := range w.queue.Items() {
+		if stored.ID == id {
+			it, found = stored, true
+			break
+		}
+	}
+	if !found || it.Done() {
+		return
+	}
+
+	var (
+		errs         []string
+		doneRelays   []string
+		doneServers  []string
+		newResponses []model.BlossomResponse
+		newURLs      = make(map[string]string)
+	)
+
+	switch it.Type {
+	case TypeEvent:
+		evt := *it.Event
+		if len(it.WaitUploads) > 0 {
+			urls, ready := w.uploadedURLs(it.WaitUploads)
+			if !ready {
+				return
+			}
+			resolved, err := w.resolve(evt, urls)
+			if err != nil {
+				w.queue.update(it.ID, func(stored *Item) {
+					stored.Attempts++
+					stored.LastAttempt = time.Now()
+					stored.LastError = err.Error()
+				})
+				return
+			}
+			evt = resolved
+			w.queue.update(it.ID, func(stored *Item) {
+				stored.Event = &evt
+				stored.WaitUploads = nil
+			})
+		}
+		for _, url := range it.PendingRelays {
+			if err := w.handlers.PublishEvent(evt, url); err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %v", url, err))
+				continue
+			}
+			doneRelays = append(doneRelays, url)
+		}
+	case TypeUpload:
+		for _, server := range it.PendingServers {
+			resp, err := w.handlers.Upload(*it.PreEvent, server)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %v", server, err))
+				continue
+			}
+			doneServers = append(doneServers, server)
+			newResponses = append(newResponses, *resp)
+			newURLs[blossom.BlobURL(server, it.PreEvent.Sha256)] = resp.URL
+		}
+	}
+
+	w.queue.update(it.ID, func(stored *Item) {
+		stored.Attempts++
+		stored.LastAttempt = time.Now()
+		stored.LastError = strings.Join(errs, "\n")
+		stored.PendingRelays = without(stored.PendingRelays, doneRelays)
+		stored.DoneRelays = appendMissing(stored.DoneRelays, doneRelays...)
+		stored.PendingServers = without(stored.PendingServers, doneServers)
+		stored.Responses = append(stored.Responses, newResponses...)
+		if len(newURLs) > 0 {
+			if stored.URLs == nil {
+				stored.URLs = make(map[string]string)
+			}
+			maps.Copy(stored.URLs, newURLs)
+		}
+		// O arquivo temporário só é apagado com o lock da fila, para não
+		// competir com AdoptFile
+		if stored.Done() {
+			if err := removeTemporary(stored); err != nil {
+				stored.LastError = err.Error()
+			}
+		}
+	})
+}
+
+// uploadedURLs reúne as URLs devolvidas pelos uploads informados. ready é
+// false enquanto algum deles estiver pendente; uploads descartados da fila não
+// são mais aguardados.
+func (w *Worker) uploadedURLs(ids []string) (urls map[string]string, ready bool) {
+	urls = make(map[string]string)
+	for _, it := range w.queue.Items() {
+		if it.Type != TypeUpload || !slices.Contains(ids, it.ID) {
+			continue
+		}
+		if !it.Done() {
+			return nil, false
+		}
+		maps.Copy(urls, it.URLs)
+	}
+	return urls, true
+}
+
+// resolve troca no evento as URLs previstas pelas devolvidas pelos servidores
+// e, se algo mudou, assina o evento de novo com a data atual.
+func (w *Worker) resolve(evt nostr.Event, urls map[string]string) (nostr.Event, error) {
+	evt, changed := rewriteURLs(evt, urls)
+	if !changed {
+		return evt, nil
+	}
+	if w.handlers.Sign == nil {
+		return evt, errors.New("cannot sign the event with the uploaded URLs")
+	}
+	evt.CreatedAt = nostr.Now()
+	if err := w.handlers.Sign(&evt); err != nil {
+		return evt, fmt.Errorf("error signing the event with the uploaded URLs: %w", err)
+	}
+	return evt, nil
+}
+
+// rewriteURLs substitui no conteúdo e nas tags de uma cópia do evento cada URL
+// prevista pela URL devolvida pelo servidor, informando se algo mudou.
+func rewriteURLs(evt nostr.Event, urls map[string]string) (nostr.Event, bool) {
+	changed := false
+	replace := func(s string) string {
+		for expected, actual := range urls {
+			if expected != actual && strings.Contains(s, expected) {
+				s = strings.ReplaceAll(s, expected, actual)
+				changed = true
+			}
+		}
+		return s
+	}
+	evt.Content = replace(evt.Content)
+	tags := make(nostr.Tags, len(evt.Tags))
+	for i, tag := range evt.Tags {
+		tags[i] = make(nostr.Tag, len(tag))
+		for j, v := range tag {
+			tags[i][j] = replace(v)
+		}
+	}
+	evt.Tags = tags
+	return evt, changed
+}
+
+// backoff calcula o intervalo de espera após o número de tentativas informado.
+func backoff(attempts int) time.Duration {
+	if attempts == 0 {
+		return 0
+	}
+	d := baseBackoff << min(attempts-1, 10)
+	return min(d, maxBackoff)
+}
+
+// without devolve os valores de list que não estão em remove.
+func without(list, remove []string) []string {
+	var out []string
+	for _, v := range list {
+		found := false
+		for _, r := range remove {
+			if v == r {
+				found = true
+				break
+			}
+		}
+		if !found {
+			out = append(out, v)
+		}
+	}
+	return out
+}
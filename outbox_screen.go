@@ -0,0 +1,126 @@
+package main
+
+import (
+	"NostrFilePublisher/outbox"
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// outboxScreen exibe a fila de saída, permitindo inspecionar, repetir e descartar itens.
+func outboxScreen(win fyne.Window) fyne.CanvasObject {
+	items := Outbox.Items()
+	var selectedID string
+
+	list := widget.NewList(
+		func() int { return len(items) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			it := items[i]
+			status := "Pendente"
+			if it.Done() {
+				status = "Concluído"
+			} else if len(it.WaitUploads) > 0 && it.LastError == "" {
+				status = "Aguardando uploads"
+			} else if it.LastError != "" {
+				status = fmt.Sprintf("Falhou (%d tentativa(s))", it.Attempts)
+			}
+			o.(*widget.Label).SetText(fmt.Sprintf("[%s] %s", status, it.Description()))
+		},
+	)
+	list.OnSelected = func(id widget.ListItemID) {
+		selectedID = items[id].ID
+	}
+	list.OnUnselected = func(widget.ListItemID) {
+		selectedID = ""
+	}
+
+	refresh := func() {
+		items = Outbox.Items()
+		list.UnselectAll()
+		list.Refresh()
+	}
+	Outbox.OnChange = func() { fyne.Do(refresh) }
+
+	selectedItem := func() (outbox.Item, bool) {
+		for _, it := range items {
+			if it.ID == selectedID {
+				return it, true
+			}
+		}
+		dialog.ShowInformation("Atenção", "Selecione um item da fila.", win)
+		return outbox.Item{}, false
+	}
+
+	detailsButton := widget.NewButton("Detalhes", func() {
+		it, ok := selectedItem()
+		if !ok {
+			return
+		}
+		var b strings.Builder
+		fmt.Fprintf(&b, "Criado em: %s\nTentativas: %d\n", it.CreatedAt.Format("02/01/2006 15:04"), it.Attempts)
+		switch it.Type {
+		case outbox.TypeEvent:
+			if len(it.WaitUploads) > 0 {
+				fmt.Fprintf(&b, "Aguardando os uploads: %s\n", strings.Join(it.WaitUploads, ", "))
+			}
+			fmt.Fprintf(&b, "Relays pendentes: %s\nRelays concluídos: %s\n\n%s\n",
+				strings.Join(it.PendingRelays, ", "), strings.Join(it.DoneRelays, ", "), it.Event.String())
+		case outbox.TypeUpload:
+			fmt.Fprintf(&b, "Arquivo: %s\nSHA-256: %s\nServidores pendentes: %s\n",
+				it.PreEvent.Path, it.PreEvent.Sha256, strings.Join(it.PendingServers, ", "))
+			for _, r := range it.Responses {
+				fmt.Fprintf(&b, "URL: %s\n", r.URL)
+			}
+			for expected, actual := range it.URLs {
+				if expected != actual {
+					fmt.Fprintf(&b, "URL prevista %s substituída por %s\n", expected, actual)
+				}
+			}
+		}
+		if it.LastError != "" {
+			fmt.Fprintf(&b, "\nÚltimo erro:\n%s\n", it.LastError)
+		}
+		details := widget.NewMultiLineEntry()
+		details.SetText(b.String())
+		details.Wrapping = fyne.TextWrapWord
+		d := dialog.NewCustom("Detalhes do Item", "Fechar", details, win)
+		d.Resize(fyne.NewSize(600, 400))
+		d.Show()
+	})
+	retryButton := widget.NewButton("Tentar Agora", func() {
+		it, ok := selectedItem()
+		if !ok {
+			return
+		}
+		go OutboxWorker.Retry(it.ID)
+	})
+	retryAllButton := widget.NewButton("Tentar Todos", func() {
+		OutboxWorker.Wake()
+	})
+	discardButton := widget.NewButton("Descartar", func() {
+		it, ok := selectedItem()
+		if !ok {
+			return
+		}
+		dialog.ShowConfirm("Descartar Item", "Deseja remover este item da fila?", func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+			if err := Outbox.Remove(it.ID); err != nil {
+				dialog.ShowError(err, win)
+			}
+		}, win)
+	})
+
+	return container.NewBorder(
+		widget.NewLabelWithStyle("Fila de Saída", fyne.TextAlignCenter, fyne.TextStyle{Bold: true}),
+		container.NewCenter(container.NewHBox(detailsButton, retryButton, retryAllButton, discardButton)),
+		nil, nil,
+		container.NewScroll(list),
+	)
+}
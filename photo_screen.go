@@ -0,0 +1,334 @@
+package main
+
+import (
+	"NostrFilePublisher/announce"
+	"NostrFilePublisher/imagemeta"
+	"NostrFilePublisher/nip68"
+	"NostrFilePublisher/sniff"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// photoExtensions são as extensões oferecidas no seletor de imagens.
+var photoExtensions = []string{".jpg", ".jpeg", ".png", ".webp", ".gif", ".avif", ".apng"}
+
+// uploadPicture envia uma imagem aos servidores Blossom (sem os metadados, se
+// configurado) e calcula o BlurHash e as dimensões.
+func uploadPicture(ctx context.Context, path string) (nip68.Picture, localUpload, error) {
+	var pic nip68.Picture
+	mimeType, err := sniff.File(path)
+	if err != nil {
+		return pic, localUpload{}, err
+	}
+	if !nip68.Supported(mimeType) {
+		return pic, localUpload{}, fmt.Errorf("%s: formato %s não é aceito em posts de fotos", filepath.Base(path), mimeType)
+	}
+	meta, err := imagemeta.DecodeFile(ctx, path)
+	if err != nil {
+		if ctx.Err() != nil {
+			return pic, localUpload{}, ctx.Err()
+		}
+		// Formatos que não conseguimos decodificar seguem sem BlurHash e dimensões
+		log.Println("Não foi possível decodificar a imagem:", err)
+	}
+	img, upload, err := uploadImage(path, mimeType, meta)
+	if err != nil {
+		return pic, upload, err
+	}
+	pic = nip68.Picture{
+		URL:       img.URL,
+		MimeType:  mimeType,
+		Sha256:    img.Sha256,
+		Dim:       img.Dim,
+		BlurHash:  img.BlurHash,
+		Fallbacks: img.Fallbacks,
+	}
+	return pic, upload, nil
+}
+
+// photoScreen monta a aba de posts de fotos (NIP-68, kind 20), com várias
+// imagens enviadas ao Blossom e descritas em tags imeta.
+func photoScreen(win fyne.Window) fyne.CanvasObject {
+	var pictures []nip68.Picture
+	selected := -1
+
+	titleEntry := widget.NewEntry()
+	titleEntry.SetPlaceHolder("Título do post...")
+
+	contentEntry := widget.NewMultiLineEntry()
+	contentEntry.SetPlaceHolder("Descrição do post...")
+	contentEntry.Wrapping = fyne.TextWrapWord
+
+	hashtagsEntry := widget.NewEntry()
+	hashtagsEntry.SetPlaceHolder("fotografia, natureza, viagem")
+
+	locationEntry := widget.NewEntry()
+	locationEntry.SetPlaceHolder("Local (ex: São Paulo, Brasil)")
+
+	warningEntry := widget.NewEntry()
+	warningEntry.SetPlaceHolder("Motivo do aviso (opcional)")
+	warningEntry.Disable()
+	warningCheck := widget.NewCheck("Conteudo Sensível", func(b bool) {
+		if b {
+			warningEntry.Enable()
+		} else {
+			warningEntry.Disable()
+		}
+	})
+
+	altEntry := widget.NewEntry()
+	altEntry.SetPlaceHolder("Descrição da imagem selecionada (alt)")
+	altEntry.Disable()
+
+	picturesList := widget.NewList(
+		func() int { return len(pictures) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			p := pictures[i]
+			alt := p.Alt
+			if alt == "" {
+				alt = "(sem descrição)"
+			}
+			o.(*widget.Label).SetText(fmt.Sprintf("%d. %s | %s | %s", i+1, p.Dim, alt, p.URL))
+		},
+	)
+	picturesList.OnSelected = func(id widget.ListItemID) {
+		selected = id
+		altEntry.SetText(pictures[id].Alt)
+		altEntry.Enable()
+	}
+	picturesList.OnUnselected = func(widget.ListItemID) {
+		selected = -1
+		altEntry.SetText("")
+		altEntry.Disable()
+	}
+	altEntry.OnChanged = func(s string) {
+		if selected >= 0 && selected < len(pictures) && pictures[selected].Alt != s {
+			pictures[selected].Alt = s
+			picturesList.RefreshItem(selected)
+		}
+	}
+
+	ipfsOpts := newIPFSOptions()
+
+	// addPictures envia as imagens uma a uma, com a opção de cancelar. Com a
+	// opção marcada, o CID IPFS de cada imagem enviada é calculado em seguida.
+	addPictures := func(paths []string) {
+		var added []nip68.Picture
+		var removed []string
+		var pinErrs []error
+		withCID, apiURL := ipfsOpts.enabled.Checked, ipfsOpts.pinAPI(win)
+		runCancellable(win, "Enviando Imagens", fmt.Sprintf("Enviando %d imagem(ns) para os servidores Blossom...", len(paths)),
+			func(ctx context.Context) error {
+				for _, path := range paths {
+					if err := ctx.Err(); err != nil {
+						return err
+					}
+					pic, upload, err := uploadPicture(ctx, path)
+					if err != nil {
+						return err
+					}
+					if withCID {
+						cid, pinErr, err := fileIPFS(ctx, path, upload, apiURL)
+						if err != nil {
+							return fmt.Errorf("Erro ao calcular o CID IPFS de %s: %w", filepath.Base(path), err)
+						}
+						if pinErr != nil {
+							pinErrs = append(pinErrs, fmt.Errorf("%s: %w", filepath.Base(path), pinErr))
+						}
+						ids, fbs := ipfsOpts.refs(cid)
+						pic.Identifiers = ids
+						pic.Fallbacks = append(pic.Fallbacks, fbs...)
+					}
+					added = append(added, pic)
+					removed = append(removed, upload.Removed...)
+				}
+				return nil
+			},
+			func(err error) {
+				pictures = append(pictures, added...)
+				picturesList.Refresh()
+				if err != nil {
+					dialog.ShowError(err, win)
+				} else if len(pinErrs) > 0 {
+					dialog.ShowError(fmt.Errorf("Erro ao fixar no IPFS: %w", errors.Join(pinErrs...)), win)
+				} else if len(removed) > 0 {
+					dialog.ShowInformation("Privacidade", removedMetadataText(dedupe(removed)), win)
+				}
+			})
+	}
+
+	addPictureButton := widget.NewButton("Adicionar Imagem", func() {
+		fd := dialog.NewFileOpen(func(file fyne.URIReadCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, win)
+				return
+			}
+			if file == nil {
+				return
+			}
+			file.Close()
+			addPictures([]string{file.URI().Path()})
+		}, win)
+		fd.SetFilter(storage.NewExtensionFileFilter(photoExtensions))
+		fd.Show()
+	})
+	addFolderButton := widget.NewButton("Adicionar Pasta", func() {
+		dialog.ShowFolderOpen(func(dir fyne.ListableURI, err error) {
+			if err != nil {
+				dialog.ShowError(err, win)
+				return
+			}
+			if dir == nil {
+				return
+			}
+			items, err := dir.List()
+			if err != nil {
+				dialog.ShowError(err, win)
+				return
+			}
+			var paths []string
+			for _, item := range items {
+				for _, ext := range photoExtensions {
+					if strings.EqualFold(item.Extension(), ext) {
+						paths = append(paths, item.Path())
+					}
+				}
+			}
+			if len(paths) == 0 {
+				dialog.ShowInformation("Atenção", "Nenhuma imagem encontrada na pasta.", win)
+				return
+			}
+			addPictures(paths)
+		}, win)
+	})
+	removePictureButton := widget.NewButton("Remover", func() {
+		if selected < 0 || selected >= len(pictures) {
+			dialog.ShowInformation("Atenção", "Selecione uma imagem da lista.", win)
+			return
+		}
+		pictures = append(pictures[:selected], pictures[selected+1:]...)
+		picturesList.UnselectAll()
+		picturesList.Refresh()
+	})
+
+	eventOutput := widget.NewMultiLineEntry()
+	eventOutput.SetPlaceHolder("O evento Nostr gerado aparecerá aqui...")
+	eventOutput.Disable()
+
+	publishButton := widget.NewButton("Gerar e Publicar", func() {
+		if len(pictures) == 0 {
+			dialog.ShowInformation("Atenção", "Por favor, adicione ao menos uma imagem.", win)
+			return
+		}
+		if App.Nsec == "" {
+			dialog.ShowInformation("Atenção", "Por favor, configure sua chave NSEC.", win)
+			return
+		}
+
+		var t nostr.Tags
+		if title := strings.TrimSpace(titleEntry.Text); title != "" {
+			t = append(t, nostr.Tag{"title", title})
+		}
+		t = append(t, nip68.Tags(pictures)...)
+		if warningCheck.Checked {
+			tag := nostr.Tag{"content-warning"}
+			if reason := strings.TrimSpace(warningEntry.Text); reason != "" {
+				tag = append(tag, reason)
+			}
+			t = append(t, tag)
+		}
+		if location := strings.TrimSpace(locationEntry.Text); location != "" {
+			t = append(t, nostr.Tag{"location", location})
+		}
+		for _, h := range announce.Hashtags(hashtagsEntry.Text) {
+			t = append(t, nostr.Tag{"t", strings.ToLower(h)})
+		}
+
+		evt := nostr.Event{
+			Content:   contentEntry.Text,
+			Tags:      t,
+			CreatedAt: nostr.Now(),
+			PubKey:    App.Npub,
+			Kind:      nip68.KindPicture,
+		}
+		if err := evt.Sign(App.Nsec); err != nil {
+			dialog.ShowError(fmt.Errorf("Erro ao assinar o evento: %w", err), win)
+			return
+		}
+		log.Println("Evento Nostr: ", evt.String())
+		eventOutput.SetText(evt.String())
+		eventOutput.Enable()
+
+		showPublishDialog(win, evt)
+	})
+
+	resetFormButton := widget.NewButton("Limpar Formulário", func() {
+		pictures = nil
+		picturesList.UnselectAll()
+		picturesList.Refresh()
+		titleEntry.SetText("")
+		contentEntry.SetText("")
+		hashtagsEntry.SetText("")
+		locationEntry.SetText("")
+		warningCheck.SetChecked(false)
+		warningEntry.SetText("")
+		eventOutput.SetText("")
+		eventOutput.Disable()
+	})
+
+	form := &widget.Form{
+		Items: []*widget.FormItem{
+			{Text: "Título", Widget: titleEntry},
+			{Text: "Hashtags", Widget: hashtagsEntry},
+			{Text: "Local", Widget: locationEntry},
+			{Text: "Aviso", Widget: container.NewBorder(nil, nil, warningCheck, nil, warningEntry)},
+			{Text: "IPFS", Widget: ipfsOpts.widget()},
+		},
+	}
+	picturesBox := container.NewBorder(
+		container.NewHBox(addPictureButton, addFolderButton, removePictureButton),
+		altEntry, nil, nil,
+		picturesList,
+	)
+	picturesBox = container.NewGridWrap(fyne.NewSize(760, 220), picturesBox)
+
+	inputContainer := container.NewVBox(
+		widget.NewLabelWithStyle("Imagens", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		picturesBox,
+		form,
+		widget.NewLabel("Descrição"),
+		contentEntry,
+	)
+	actionsContainer := container.NewVBox(
+		container.NewCenter(container.NewHBox(publishButton, resetFormButton)),
+		widget.NewLabelWithStyle("Evento Gerado", fyne.TextAlignCenter, fyne.TextStyle{Bold: true}),
+		eventOutput,
+	)
+
+	return container.NewBorder(nil, actionsContainer, nil, nil, container.NewScroll(inputContainer))
+}
+
+// dedupe remove os itens repetidos, mantendo a ordem.
+func dedupe(items []string) []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, item := range items {
+		if !seen[item] {
+			seen[item] = true
+			out = append(out, item)
+		}
+	}
+	return out
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"NostrFilePublisher/relay"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// allWriteRelaysOption é a opção do seletor de conjuntos que marca todos os relays de escrita.
+const allWriteRelaysOption = "Todos os relays de escrita"
+
+// showPublishDialog pergunta em quais relays o evento deve ser publicado e qual
+// o quórum necessário, e então publica o evento mostrando o resultado de cada relay.
+func showPublishDialog(win fyne.Window, evt nostr.Event) {
+	App.Mutex.Lock()
+	writeRelays := App.WriteRelays()
+	sets := make(map[string][]string, len(App.RelaySets))
+	setNames := make([]string, 0, len(App.RelaySets))
+	for name, urls := range App.RelaySets {
+		sets[name] = append([]string(nil), urls...)
+		setNames = append(setNames, name)
+	}
+	App.Mutex.Unlock()
+	sort.Strings(setNames)
+
+	if len(writeRelays) == 0 {
+		dialog.ShowInformation("Atenção", "Nenhum relay de escrita configurado. Por favor, adicione um relay na aba Configurações.", win)
+		return
+	}
+
+	quorumSelect := widget.NewSelect(nil, nil)
+	relayCheck := widget.NewCheckGroup(writeRelays, nil)
+	updateQuorum := func() {
+		n := len(relayCheck.Selected)
+		options := []string{"Todos"}
+		for i := 1; i < n; i++ {
+			options = append(options, fmt.Sprintf("%d de %d", i, n))
+		}
+		quorumSelect.Options = options
+		quorumSelect.SetSelectedIndex(0)
+	}
+	relayCheck.OnChanged = func([]string) { updateQuorum() }
+	relayCheck.SetSelected(writeRelays)
+
+	setSelect := widget.NewSelect(append([]string{allWriteRelaysOption}, setNames...), func(name string) {
+		if name == allWriteRelaysOption {
+			relayCheck.SetSelected(writeRelays)
+			return
+		}
+		// Relays somente leitura de um conjunto são ignorados na publicação.
+		var selected []string
+		for _, url := range sets[name] {
+			for _, w := range writeRelays {
+				if url == w {
+					selected = append(selected, url)
+				}
+			}
+		}
+		relayCheck.SetSelected(selected)
+	})
+	setSelect.SetSelectedIndex(0)
+
+	content := container.NewVBox(
+		widget.NewLabel("Conjunto de relays:"),
+		setSelect,
+		container.NewVScroll(relayCheck),
+		widget.NewLabel("Considerar publicado após sucesso em:"),
+		quorumSelect,
+	)
+	d := dialog.NewCustomConfirm("Publicar Evento", "Publicar", "Cancelar", content, func(ok bool) {
+		if !ok {
+			return
+		}
+		if len(relayCheck.Selected) == 0 {
+			dialog.ShowInformation("Atenção", "Selecione ao menos um relay.", win)
+			return
+		}
+		// O índice 0 significa "Todos"; os demais correspondem a "i de n".
+		quorum := quorumSelect.SelectedIndex()
+		relays := append([]string(nil), relayCheck.Selected...)
+		if uploads := Outbox.PendingUploads(evt); len(uploads) > 0 {
+			askPendingUploads(win, evt, relays, uploads, func() { runPublish(win, evt, relays, quorum) })
+			return
+		}
+		runPublish(win, evt, relays, quorum)
+	}, win)
+	d.Resize(fyne.NewSize(450, 400))
+	d.Show()
+}
+
+// askPendingUploads avisa que o evento usa arquivos cujo envio ainda está na
+// fila de saída. O evento pode aguardar na fila, sendo publicado quando os
+// envios terminarem (e assinado de novo se algum servidor devolver outra URL),
+// ou ser publicado agora com links que só funcionarão depois do envio.
+func askPendingUploads(win fyne.Window, evt nostr.Event, relays, uploads []string, publishNow func()) {
+	msg := fmt.Sprintf("%d arquivo(s) deste evento ainda aguardam envio na fila de saída.\n"+
+		"Publicado agora, o evento terá links que só funcionarão depois do envio.", len(uploads))
+	d := dialog.NewConfirm("Envios Pendentes", msg, func(now bool) {
+		if now {
+			publishNow()
+			return
+		}
+		if err := Outbox.AddEventAfter(evt, relays, uploads); err != nil {
+			dialog.ShowError(fmt.Errorf("Erro ao adicionar o evento à fila: %w", err), win)
+			return
+		}
+		dialog.ShowInformation("Evento na Fila", "O evento será publicado quando os envios terminarem.", win)
+	}, win)
+	d.SetConfirmText("Publicar Agora")
+	d.SetDismissText("Aguardar na Fila")
+	d.Show()
+}
+
+// verifyDelay é a espera antes de ler o evento de volta, dando tempo ao relay
+// para armazená-lo.
+const verifyDelay = 2 * time.Second
+
+// recordPublished registra no histórico que o relay aceitou o evento e então o
+// lê de volta do relay, registrando e devolvendo se ele foi de fato armazenado.
+// Falhas na leitura são devolvidas sem registro, pois não indicam que o evento
+// foi descartado.
+func recordPublished(evt nostr.Event, relayURL string) (bool, error) {
+	if err := History.RecordAccepted(evt, relayURL); err != nil {
+		log.Println("Erro ao registrar publicação no histórico:", err)
+	}
+
+	time.Sleep(verifyDelay)
+	verified, err := relay.Verify(evt.ID, relayURL)
+	if err != nil {
+		// Sem resposta do relay não é possível afirmar que o evento foi descartado.
+		log.Printf("Erro ao verificar o evento em %s: %v", relayURL, err)
+		return false, err
+	}
+	if err := History.RecordVerified(evt, relayURL, verified); err != nil {
+		log.Println("Erro ao registrar verificação no histórico:", err)
+	}
+	return verified, nil
+}
+
+// runPublish publica o evento nos relays escolhidos e mantém um diálogo com o
+// status de cada relay, atualizado à medida que as respostas chegam.
+func runPublish(win fyne.Window, evt nostr.Event, urls []string, quorum int) {
+	var mu sync.Mutex
+	statusMap := make(map[string]string, len(urls))
+	for _, url := range urls {
+		statusMap[url] = "Enviando..."
+	}
+
+	resultsList := widget.NewList(
+		func() int { return len(urls) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			mu.Lock()
+			defer mu.Unlock()
+			o.(*widget.Label).SetText(fmt.Sprintf("%s: %s", urls[i], statusMap[urls[i]]))
+		},
+	)
+	quorumLabel := widget.NewLabel("Aguardando o quórum...")
+
+	// Os links usam como dicas os relays que aceitaram o evento
+	var accepted []string
+	shareButton := widget.NewButton("Compartilhar", func() {
+		mu.Lock()
+		relays := append([]string(nil), accepted...)
+		mu.Unlock()
+		showShareDialog(win, evt, relays)
+	})
+	shareButton.Disable()
+	// Notas de texto não são anunciadas por outra nota
+	announceButton := widget.NewButton("Anunciar", func() {
+		mu.Lock()
+		relays := append([]string(nil), accepted...)
+		mu.Unlock()
+		showAnnounceDialog(win, evt, relays)
+	})
+	announceButton.Disable()
+	if evt.Kind == nostr.KindTextNote {
+		announceButton.Hide()
+	}
+
+	resultDialog := dialog.NewCustom("Resultado da Publicação", "Fechar",
+		container.NewBorder(quorumLabel, container.NewCenter(container.NewHBox(shareButton, announceButton)), nil, nil, container.NewScroll(resultsList)), win)
+	resultDialog.Resize(fyne.NewSize(400, 300))
+	resultDialog.Show()
+
+	go func() {
+		results, reached := relay.Publish(evt, urls, quorum, func(res relay.Result) {
+			mu.Lock()
+			statusMap[res.URL] = res.Status()
+			if res.Err != nil {
+				// Relays que falharam ficam na fila de saída para novas tentativas.
+				if err := Outbox.AddEvent(evt, []string{res.URL}); err == nil {
+					statusMap[res.URL] += " (adicionado à fila)"
+				}
+			} else {
+				statusMap[res.URL] += " (verificando...)"
+				accepted = append(accepted, res.URL)
+			}
+			mu.Unlock()
+			fyne.Do(func() {
+				resultsList.Refresh()
+				if res.Err == nil {
+					shareButton.Enable()
+					announceButton.Enable()
+				}
+			})
+
+			if res.Err != nil {
+				return
+			}
+			verified, err := recordPublished(evt, res.URL)
+			mu.Lock()
+			if err != nil {
+				statusMap[res.URL] = "Sucesso (não foi possível verificar)"
+			} else if verified {
+				statusMap[res.URL] = "Sucesso (verificado)"
+			} else {
+				statusMap[res.URL] = "Sucesso (não encontrado na leitura)"
+			}
+			mu.Unlock()
+			fyne.Do(resultsList.Refresh)
+		})
+
+		successes := 0
+		for _, res := range results {
+			if res.Err == nil {
+				successes++
+			}
+		}
+		fyne.Do(func() {
+			if reached {
+				quorumLabel.SetText(fmt.Sprintf("Publicado: quórum atingido (%d de %d). Os demais relays continuam em segundo plano.", successes, len(urls)))
+			} else {
+				quorumLabel.SetText(fmt.Sprintf("Quórum não atingido: %d de %d relays aceitaram o evento.", successes, len(urls)))
+			}
+		})
+	}()
+}
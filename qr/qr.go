@@ -0,0 +1,214 @@
+// Package qr gera códigos QR (ISO/IEC 18004) em Go puro, nos modos
+// alfanumérico e byte, versões 1 a 40, com a escolha automática da menor
+// versão e da máscara de menor penalidade.
+package qr
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"strings"
+)
+
+// Level é o nível de correção de erros.
+type Level int
+
+const (
+	L Level = iota // recupera ~7% dos dados
+	M              // recupera ~15% dos dados
+	Q              // recupera ~25% dos dados
+	H              // recupera ~30% dos dados
+)
+
+// ErrTooLong indica que o texto não cabe nem na versão 40 do código.
+var ErrTooLong = errors.New("qr: data too long")
+
+// formatBits são os bits de nível usados na informação de formato.
+var formatBits = [4]int{L: 1, M: 0, Q: 3, H: 2}
+
+// eccCodewordsPerBlock e numBlocks vêm da tabela 9 da especificação, por nível
+// e versão (o índice 0 não é usado).
+var eccCodewordsPerBlock = [4][41]int{
+	{-1, 7, 10, 15, 20, 26, 18, 20, 24, 30, 18, 20, 24, 26, 30, 22, 24, 28, 30, 28, 28, 28, 28, 30, 30, 26, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	{-1, 10, 16, 26, 18, 24, 16, 18, 22, 22, 26, 30, 22, 22, 24, 24, 28, 28, 26, 26, 26, 26, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28},
+	{-1, 13, 22, 18, 26, 18, 24, 18, 22, 20, 24, 28, 26, 24, 20, 30, 24, 28, 28, 26, 30, 28, 30, 30, 30, 30, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	{-1, 17, 28, 22, 16, 22, 28, 26, 26, 24, 28, 24, 28, 22, 24, 24, 30, 28, 28, 26, 28, 30, 24, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+}
+
+var numBlocks = [4][41]int{
+	{-1, 1, 1, 1, 1, 1, 2, 2, 2, 2, 4, 4, 4, 4, 4, 6, 6, 6, 6, 7, 8, 8, 9, 9, 10, 12, 12, 12, 13, 14, 15, 16, 17, 18, 19, 19, 20, 21, 22, 24, 25},
+	{-1, 1, 1, 1, 2, 2, 4, 4, 4, 5, 5, 5, 8, 9, 9, 10, 10, 11, 13, 14, 16, 17, 17, 18, 20, 21, 23, 25, 26, 28, 29, 31, 33, 35, 37, 38, 40, 43, 45, 47, 49},
+	{-1, 1, 1, 2, 2, 4, 4, 6, 6, 8, 8, 8, 10, 12, 16, 12, 17, 16, 18, 21, 20, 23, 23, 25, 27, 29, 34, 34, 35, 38, 40, 43, 45, 48, 51, 53, 56, 59, 62, 65, 68},
+	{-1, 1, 1, 2, 4, 4, 4, 5, 6, 8, 8, 11, 11, 16, 16, 18, 16, 19, 21, 25, 25, 25, 34, 30, 32, 35, 37, 40, 42, 45, 48, 51, 54, 57, 60, 63, 66, 70, 74, 77, 81},
+}
+
+// alphanumeric é o conjunto de caracteres do modo alfanumérico, na ordem dos valores.
+const alphanumeric = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+
+// Code é um código QR gerado.
+type Code struct {
+	// Size é a largura e a altura em módulos, sem a margem.
+	Size int
+
+	modules    [][]bool
+	isFunction [][]bool
+}
+
+// Black informa se o módulo na coluna x e linha y é escuro.
+func (c *Code) Black(x, y int) bool {
+	return x >= 0 && y >= 0 && x < c.Size && y < c.Size && c.modules[y][x]
+}
+
+// Image desenha o código com scale pixels por módulo e uma margem de border
+// módulos (a especificação recomenda 4).
+func (c *Code) Image(scale, border int) image.Image {
+	side := (c.Size + 2*border) * scale
+	img := image.NewGray(image.Rect(0, 0, side, side))
+	for py := 0; py < side; py++ {
+		for px := 0; px < side; px++ {
+			v := color.Gray{Y: 255}
+			if c.Black(px/scale-border, py/scale-border) {
+				v = color.Gray{Y: 0}
+			}
+			img.SetGray(px, py, v)
+		}
+	}
+	return img
+}
+
+// Encode gera o código QR do texto no nível de correção informado. Textos com
+// apenas dígitos, letras maiúsculas e " $%*+-./:" usam o modo alfanumérico,
+// mais compacto; os demais são codificados como bytes UTF-8.
+func Encode(text string, level Level) (*Code, error) {
+	alnum := isAlphanumeric(text)
+	var version, dataCapacity int
+	for version = 1; version <= 40; version++ {
+		dataCapacity = numRawDataModules(version)/8 - eccCodewordsPerBlock[level][version]*numBlocks[level][version]
+		if dataBits(text, alnum, version) <= dataCapacity*8 {
+			break
+		}
+	}
+	if version > 40 {
+		return nil, ErrTooLong
+	}
+
+	var bb bitBuffer
+	if alnum {
+		bb.append(0x2, 4)
+		bb.append(len(text), charCountBits(true, version))
+		for i := 0; i+1 < len(text); i += 2 {
+			bb.append(strings.IndexByte(alphanumeric, text[i])*45+strings.IndexByte(alphanumeric, text[i+1]), 11)
+		}
+		if len(text)%2 == 1 {
+			bb.append(strings.IndexByte(alphanumeric, text[len(text)-1]), 6)
+		}
+	} else {
+		bb.append(0x4, 4)
+		bb.append(len(text), charCountBits(false, version))
+		for i := 0; i < len(text); i++ {
+			bb.append(int(text[i]), 8)
+		}
+	}
+
+	// Terminador, alinhamento ao byte e bytes de preenchimento
+	capacityBits := dataCapacity * 8
+	bb.append(0, min(4, capacityBits-len(bb)))
+	bb.append(0, (8-len(bb)%8)%8)
+	for pad := 0xEC; len(bb) < capacityBits; pad ^= 0xEC ^ 0x11 {
+		bb.append(pad, 8)
+	}
+	data := make([]byte, len(bb)/8)
+	for i, bit := range bb {
+		if bit {
+			data[i>>3] |= 1 << (7 - uint(i&7))
+		}
+	}
+
+	c := newCode(version)
+	c.drawFunctionPatterns(version, level)
+	c.drawCodewords(addEccAndInterleave(data, version, level))
+
+	// Escolhe a máscara de menor penalidade
+	best, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		c.applyMask(mask)
+		c.drawFormatBits(level, mask)
+		if p := c.penalty(); bestPenalty < 0 || p < bestPenalty {
+			best, bestPenalty = mask, p
+		}
+		c.applyMask(mask) // desfaz (XOR)
+	}
+	c.applyMask(best)
+	c.drawFormatBits(level, best)
+	return c, nil
+}
+
+func isAlphanumeric(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if strings.IndexByte(alphanumeric, s[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func charCountBits(alnum bool, version int) int {
+	switch {
+	case alnum && version <= 9:
+		return 9
+	case alnum && version <= 26:
+		return 11
+	case alnum:
+		return 13
+	case version <= 9:
+		return 8
+	default:
+		return 16
+	}
+}
+
+// dataBits é o número de bits dos dados codificados, sem o preenchimento.
+func dataBits(text string, alnum bool, version int) int {
+	n := 4 + charCountBits(alnum, version)
+	if alnum {
+		return n + len(text)/2*11 + len(text)%2*6
+	}
+	return n + len(text)*8
+}
+
+// numRawDataModules é o número de módulos disponíveis para dados e correção
+// de erros, depois de descontados os padrões de função.
+func numRawDataModules(version int) int {
+	result := (16*version+128)*version + 64
+	if version >= 2 {
+		numAlign := version/7 + 2
+		result -= (25*numAlign-10)*numAlign - 55
+		if version >= 7 {
+			result -= 36
+		}
+	}
+	return result
+}
+
+// alignmentPositions devolve as coordenadas dos centros dos padrões de alinhamento.
+func alignmentPositions(version int) []int {
+	if version == 1 {
+		return nil
+	}
+	numAlign := version/7 + 2
+	step := (version*8 + numAlign*3 + 5) / (numAlign*4 - 4) * 2
+	result := make([]int, numAlign)
+	result[0] = 6
+	for i, pos := numAlign-1, version*4+17-7; i >= 1; i, pos = i-1, pos-step {
+		result[i] = pos
+	}
+	return result
+}
+
+type bitBuffer []bool
+
+func (b *bitBuffer) append(value, length int) {
+	for i := length - 1; i >= 0; i-- {
+		*b = append(*b, (value>>uint(i))&1 != 0)
+	}
+}
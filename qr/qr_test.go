@@ -0,0 +1,174 @@
+package qr
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// Os códigos de "HELLO WORLD" vêm do exemplo clássico de codificação
+// alfanumérica (versão 1), com os blocos de correção Reed-Solomon dos níveis M e Q.
+var (
+	helloDataM = []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	helloEccM  = []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	helloDataQ = []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236}
+	helloEccQ  = []byte{168, 72, 22, 82, 217, 54, 156, 0, 46, 15, 180, 122, 16}
+)
+
+func TestReedSolomon(t *testing.T) {
+	tests := []struct {
+		name      string
+		data, ecc []byte
+	}{
+		{"1-M", helloDataM, helloEccM},
+		{"1-Q", helloDataQ, helloEccQ},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rsRemainder(tt.data, rsDivisor(len(tt.ecc))); !bytes.Equal(got, tt.ecc) {
+				t.Errorf("ecc = %v, want %v", got, tt.ecc)
+			}
+		})
+	}
+}
+
+// readFormat lê a cópia da informação de formato ao redor do padrão de
+// localização superior esquerdo e devolve o nível e a máscara.
+func readFormat(t *testing.T, c *Code) (Level, int) {
+	t.Helper()
+	var bits int
+	set := func(i, x, y int) {
+		if c.Black(x, y) {
+			bits |= 1 << i
+		}
+	}
+	for i := 0; i <= 5; i++ {
+		set(i, 8, i)
+	}
+	set(6, 8, 7)
+	set(7, 8, 8)
+	set(8, 7, 8)
+	for i := 9; i < 15; i++ {
+		set(i, 14-i, 8)
+	}
+	bits ^= 0x5412
+
+	// Os 10 bits finais são o BCH(15,5) dos 5 bits de dados
+	rem := bits
+	for i := 14; i >= 10; i-- {
+		if rem>>i&1 != 0 {
+			rem ^= 0x537 << (i - 10)
+		}
+	}
+	if rem != 0 {
+		t.Fatalf("format bits %015b fail the BCH check", bits)
+	}
+	for level, v := range formatBits {
+		if v == bits>>13 {
+			return Level(level), bits >> 10 & 7
+		}
+	}
+	t.Fatalf("unknown level in format bits %015b", bits)
+	return 0, 0
+}
+
+// readCodewords desfaz a máscara e lê os códigos na ordem em zigue-zague.
+func readCodewords(c *Code, mask int) []byte {
+	c.applyMask(mask)
+	defer c.applyMask(mask)
+	var out []byte
+	var cur, n int
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < c.Size; vert++ {
+			for j := 0; j < 2; j++ {
+				x, y := right-j, vert
+				if (right+1)&2 == 0 {
+					y = c.Size - 1 - vert
+				}
+				if c.isFunction[y][x] {
+					continue
+				}
+				cur = cur<<1 | btoi(c.modules[y][x])
+				if n++; n == 8 {
+					out = append(out, byte(cur))
+					cur, n = 0, 0
+				}
+			}
+		}
+	}
+	return out
+}
+
+func btoi(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func TestEncodeHelloWorld(t *testing.T) {
+	tests := []struct {
+		level     Level
+		data, ecc []byte
+	}{
+		{M, helloDataM, helloEccM},
+		{Q, helloDataQ, helloEccQ},
+	}
+	for _, tt := range tests {
+		c, err := Encode("HELLO WORLD", tt.level)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.Size != 21 {
+			t.Fatalf("level %d: Size = %d, want 21 (version 1)", tt.level, c.Size)
+		}
+		level, mask := readFormat(t, c)
+		if level != tt.level {
+			t.Errorf("format level = %d, want %d", level, tt.level)
+		}
+		want := append(append([]byte{}, tt.data...), tt.ecc...)
+		if got := readCodewords(c, mask); !bytes.Equal(got, want) {
+			t.Errorf("level %d: codewords = %v, want %v", tt.level, got, want)
+		}
+		if !c.Black(8, c.Size-8) {
+			t.Errorf("level %d: dark module missing", tt.level)
+		}
+	}
+}
+
+// TestEncodeVersion confere a escolha da versão nos limites de capacidade da
+// tabela 7 da especificação.
+func TestEncodeVersion(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		level Level
+		size  int
+	}{
+		{"alfanumérico 1-L cheio", strings.Repeat("A", 25), L, 21},
+		{"alfanumérico 1-L mais um", strings.Repeat("A", 26), L, 25},
+		{"bytes 1-L cheio", strings.Repeat("a", 17), L, 21},
+		{"bytes 1-L mais um", strings.Repeat("a", 18), L, 25},
+		{"bytes 1-H cheio", strings.Repeat("a", 7), H, 21},
+		{"bytes 7-M", strings.Repeat("a", 122), M, 45},
+		{"bytes 40-L cheio", strings.Repeat("a", 2953), L, 177},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := Encode(tt.text, tt.level)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.Size != tt.size {
+				t.Errorf("Size = %d, want %d", c.Size, tt.size)
+			}
+		})
+	}
+
+	if _, err := Encode(strings.Repeat("a", 2954), L); err != ErrTooLong {
+		t.Errorf("Encode(2954 bytes) = %v, want ErrTooLong", err)
+	}
+}
@@ -0,0 +1,121 @@
+package relay
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+const (
+	// publishTimeout é o tempo máximo de cada tentativa de conexão e envio.
+	publishTimeout = 10 * time.Second
+
+	// maxAttempts é o número de tentativas feitas em cada relay antes de desistir.
+	maxAttempts = 3
+)
+
+// Result representa o resultado da publicação de um evento em um único relay.
+type Result struct {
+	URL string
+	Err error
+}
+
+// Status devolve uma descrição legível do resultado, no mesmo formato usado pela UI.
+func (r Result) Status() string {
+	if r.Err != nil {
+		return fmt.Sprintf("Falha: %v", r.Err)
+	}
+	return "Sucesso"
+}
+
+// Publish envia o evento para todos os relays em paralelo e retorna assim que
+// `quorum` relays confirmarem o recebimento, ou quando todos terminarem, o que
+// ocorrer primeiro. Um quorum menor ou igual a zero (ou maior que a quantidade de
+// relays) exige a resposta de todos.
+//
+// Os relays que ainda não responderam continuam tentando em segundo plano.
+// onResult, se não for nil, é chamado para cada relay assim que ele termina,
+// inclusive depois que Publish já retornou, e deve ser seguro para uso concorrente.
+//
+// O valor retornado contém os resultados conhecidos no momento em que o quorum foi
+// atingido e indica se ele foi de fato alcançado.
+func Publish(evt nostr.Event, urls []string, quorum int, onResult func(Result)) ([]Result, bool) {
+	if quorum <= 0 || quorum > len(urls) {
+		quorum = len(urls)
+	}
+
+	var (
+		mu        sync.Mutex
+		results   []Result
+		successes int
+		finished  int
+		returned  bool
+	)
+	done := make(chan struct{})
+
+	for _, url := range urls {
+		go func(relayURL string) {
+			res := Result{URL: relayURL, Err: publishWithRetry(evt, relayURL)}
+			if onResult != nil {
+				onResult(res)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			finished++
+			if res.Err == nil {
+				successes++
+			}
+			if returned {
+				return
+			}
+			results = append(results, res)
+			if successes >= quorum || finished == len(urls) {
+				returned = true
+				close(done)
+			}
+		}(url)
+	}
+
+	if len(urls) > 0 {
+		<-done
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	out := make([]Result, len(results))
+	copy(out, results)
+	return out, successes >= quorum && len(urls) > 0
+}
+
+// publishWithRetry tenta publicar o evento em um relay até maxAttempts vezes,
+// aguardando um intervalo crescente entre as tentativas.
+func publishWithRetry(evt nostr.Event, relayURL string) error {
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if err = PublishOne(evt, relayURL); err == nil {
+			return nil
+		}
+		if attempt < maxAttempts {
+			time.Sleep(time.Duration(attempt) * 2 * time.Second)
+		}
+	}
+	return err
+}
+
+// PublishOne conecta a um único relay, publica o evento e fecha a conexão.
+func PublishOne(evt nostr.Event, relayURL string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+
+	r, err := nostr.RelayConnect(ctx, relayURL)
+	if err != nil {
+		return fmt.Errorf("falha ao conectar: %w", err)
+	}
+	defer r.Close()
+
+	return r.Publish(ctx, evt)
+}
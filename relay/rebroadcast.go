@@ -0,0 +1,151 @@
+package relay
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// Progress descreve o andamento de uma retransmissão.
+type Progress struct {
+	// Done e Total contam os pares (evento, relay de destino) já processados.
+	Done, Total int
+
+	// Message descreve o último passo executado.
+	Message string
+}
+
+// FetchAuthorEvents busca nos relays informados os eventos do autor com os
+// kinds informados. Os eventos são deduplicados por ID, apenas os que têm
+// assinatura válida são mantidos e o resultado é ordenado do mais antigo para o mais novo.
+// Um erro só é devolvido se nenhum relay puder ser consultado.
+func FetchAuthorEvents(relayURLs []string, pubkey string, kinds []int) ([]nostr.Event, error) {
+	return fetchEvents(relayURLs, nostr.Filter{Authors: []string{pubkey}, Kinds: kinds})
+}
+
+// FetchAddressable busca nos relays informados a versão mais recente do evento
+// endereçável identificado por kind, autor e tag "d". Devolve found false se
+// nenhum relay tiver o evento.
+func FetchAddressable(relayURLs []string, pubkey string, kind int, identifier string) (evt nostr.Event, found bool, err error) {
+	events, err := fetchEvents(relayURLs, nostr.Filter{
+		Authors: []string{pubkey},
+		Kinds:   []int{kind},
+		Tags:    nostr.TagMap{"d": []string{identifier}},
+	})
+	if err != nil || len(events) == 0 {
+		return evt, false, err
+	}
+	return events[len(events)-1], true, nil
+}
+
+// fetchEvents consulta os relays em paralelo com o filtro informado.
+func fetchEvents(relayURLs []string, filter nostr.Filter) ([]nostr.Event, error) {
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		byID    = make(map[string]nostr.Event)
+		errs    []error
+		queried int
+	)
+	for _, url := range relayURLs {
+		wg.Add(1)
+		go func(relayURL string) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), 2*publishTimeout)
+			defer cancel()
+
+			r, err := nostr.RelayConnect(ctx, relayURL)
+			if err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("%s: %w", relayURL, err))
+				mu.Unlock()
+				return
+			}
+			defer r.Close()
+
+			events, err := r.QuerySync(ctx, filter)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", relayURL, err))
+				return
+			}
+			queried++
+			for _, evt := range events {
+				if ok, _ := evt.CheckSignature(); ok {
+					byID[evt.ID] = *evt
+				}
+			}
+		}(url)
+	}
+	wg.Wait()
+
+	if queried == 0 && len(errs) > 0 {
+		return nil, fmt.Errorf("nenhum relay respondeu: %v", errs)
+	}
+
+	out := make([]nostr.Event, 0, len(byID))
+	for _, evt := range byID {
+		out = append(out, evt)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt < out[j].CreatedAt })
+	return out, nil
+}
+
+// Rebroadcast republica os eventos, sem alterá-los, em cada relay de destino.
+// Eventos que o destino já possui são ignorados. onProgress é chamado a cada
+// passo e devolve-se o número de eventos efetivamente enviados.
+func Rebroadcast(events []nostr.Event, targets []string, onProgress func(Progress)) int {
+	p := Progress{Total: len(events) * len(targets)}
+	report := func(msg string) {
+		p.Message = msg
+		if onProgress != nil {
+			onProgress(p)
+		}
+	}
+
+	sent := 0
+	for _, target := range targets {
+		ids := make([]string, len(events))
+		for i, evt := range events {
+			ids[i] = evt.ID
+		}
+		existing, err := FetchIDs(target, ids)
+		if err != nil {
+			// Sem a consulta prévia todos os eventos são enviados; o relay ignora duplicatas.
+			existing = map[string]bool{}
+			report(fmt.Sprintf("%s: não foi possível consultar eventos existentes: %v", target, err))
+		}
+
+		connectCtx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+		r, err := nostr.RelayConnect(connectCtx, target)
+		cancel()
+		if err != nil {
+			p.Done += len(events)
+			report(fmt.Sprintf("%s: falha ao conectar: %v", target, err))
+			continue
+		}
+
+		for _, evt := range events {
+			p.Done++
+			if existing[evt.ID] {
+				report(fmt.Sprintf("%s: %s já existe, ignorado", target, evt.ID[:12]))
+				continue
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+			err := r.Publish(ctx, evt)
+			cancel()
+			if err != nil {
+				report(fmt.Sprintf("%s: falha ao enviar %s: %v", target, evt.ID[:12], err))
+				continue
+			}
+			sent++
+			report(fmt.Sprintf("%s: %s enviado", target, evt.ID[:12]))
+		}
+		r.Close()
+	}
+	return sent
+}
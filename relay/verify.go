@@ -0,0 +1,53 @@
+package relay
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// Verify consulta o relay pelo ID do evento e informa se ele devolve o evento
+// armazenado. Um "OK" na publicação não garante que o relay guardou o evento;
+// apenas a leitura de volta confirma isso.
+func Verify(evtID string, relayURL string) (bool, error) {
+	found, err := FetchIDs(relayURL, []string{evtID})
+	if err != nil {
+		return false, err
+	}
+	return found[evtID], nil
+}
+
+// idBatchSize limita a quantidade de IDs consultados em uma única assinatura.
+const idBatchSize = 100
+
+// FetchIDs consulta o relay por um conjunto de IDs, em lotes, e devolve quais
+// deles foram encontrados com assinatura válida.
+func FetchIDs(relayURL string, ids []string) (map[string]bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+
+	r, err := nostr.RelayConnect(ctx, relayURL)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao conectar: %w", err)
+	}
+	defer r.Close()
+
+	found := make(map[string]bool, len(ids))
+	for start := 0; start < len(ids); start += idBatchSize {
+		batch := ids[start:min(start+idBatchSize, len(ids))]
+		// Cada lote tem seu próprio prazo, para que consultas longas não expirem.
+		batchCtx, batchCancel := context.WithTimeout(context.Background(), publishTimeout)
+		events, err := r.QuerySync(batchCtx, nostr.Filter{IDs: batch, Limit: len(batch)})
+		batchCancel()
+		if err != nil {
+			return nil, fmt.Errorf("falha na consulta: %w", err)
+		}
+		for _, evt := range events {
+			if ok, _ := evt.CheckSignature(); ok {
+				found[evt.ID] = true
+			}
+		}
+	}
+	return found, nil
+}
@@ -0,0 +1,217 @@
+package remote
+
+import (
+	"NostrFilePublisher/sniff"
+	"NostrFilePublisher/util"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrHashMismatch indica que o arquivo baixado não tem o hash esperado.
+var ErrHashMismatch = errors.New("sha256 mismatch")
+
+// maxAttempts é o número de tentativas de um download antes de desistir. Cada
+// nova tentativa retoma o arquivo de onde a anterior parou.
+const maxAttempts = 5
+
+// Download baixa url para o arquivo dest. Os dados são gravados em dest+".part"
+// e, se um download anterior tiver sido interrompido, ele é retomado com uma
+// requisição Range, desde que o servidor confirme (If-Range) que o arquivo não
+// mudou. Falhas de rede são repetidas, retomando o download, até maxAttempts
+// vezes. Se expectedSha256 não for vazio, o hash do arquivo completo é conferido
+// e um erro ErrHashMismatch é devolvido se for diferente.
+func Download(ctx context.Context, client *http.Client, url, dest, expectedSha256 string, onProgress Progress) (Info, error) {
+	info := Info{URL: url}
+	part := dest + ".part"
+	var contentType string
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		var retry bool
+		contentType, retry, err = downloadAttempt(ctx, client, url, part, onProgress)
+		if err == nil || !retry || ctx.Err() != nil {
+			break
+		}
+		if attempt < maxAttempts {
+			select {
+			case <-ctx.Done():
+				return info, ctx.Err()
+			case <-time.After(time.Duration(attempt) * time.Second):
+			}
+		}
+	}
+	if err != nil {
+		return info, err
+	}
+
+	info.Sha256, info.Size, err = util.HashFile(part)
+	if err != nil {
+		return info, err
+	}
+	if expectedSha256 != "" && !strings.EqualFold(info.Sha256, expectedSha256) {
+		// O arquivo parcial pode estar corrompido; o próximo download recomeça do zero
+		removePartial(part)
+		return info, fmt.Errorf("%w: expected %s, got %s", ErrHashMismatch, expectedSha256, info.Sha256)
+	}
+	if err := os.Rename(part, dest); err != nil {
+		return info, err
+	}
+	os.Remove(validatorPath(part))
+
+	info.MimeType = contentType
+	if info.MimeType == "" {
+		if info.MimeType, err = sniff.File(dest); err != nil {
+			return info, err
+		}
+	}
+	return info, nil
+}
+
+// downloadAttempt faz uma tentativa de download, continuando o arquivo parcial
+// quando possível. retry indica se o erro é transitório e vale tentar de novo.
+func downloadAttempt(ctx context.Context, client *http.Client, url, part string, onProgress Progress) (contentType string, retry bool, err error) {
+	f, err := os.OpenFile(part, os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return "", false, err
+	}
+	defer f.Close()
+	offset, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return "", false, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", false, err
+	}
+	validator, _ := os.ReadFile(validatorPath(part))
+	if offset > 0 && len(validator) > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		req.Header.Set("If-Range", string(validator))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", true, err
+	}
+	defer resp.Body.Close()
+
+	total := resp.ContentLength
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		start, size, ok := parseContentRange(resp.Header.Get("Content-Range"))
+		if !ok || start != offset {
+			return "", false, fmt.Errorf("unexpected Content-Range %q for offset %d", resp.Header.Get("Content-Range"), offset)
+		}
+		total = size
+	case http.StatusOK:
+		// O servidor ignorou o Range (ou o arquivo mudou): recomeça do zero
+		if err := f.Truncate(0); err != nil {
+			return "", false, err
+		}
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return "", false, err
+		}
+		offset = 0
+	case http.StatusRequestedRangeNotSatisfiable:
+		// O arquivo parcial já pode estar completo
+		if _, size, ok := parseContentRange(resp.Header.Get("Content-Range")); ok && size == offset {
+			return contentTypeOf(resp.Header), false, nil
+		}
+		removePartial(part)
+		return "", true, fmt.Errorf("range not satisfiable for %s, restarting download", url)
+	default:
+		retry = resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+		return "", retry, fmt.Errorf("unexpected status fetching %s: %s", url, resp.Status)
+	}
+
+	if v := resp.Header.Get("ETag"); v != "" && !strings.HasPrefix(v, "W/") {
+		os.WriteFile(validatorPath(part), []byte(v), 0o644)
+	} else if v := resp.Header.Get("Last-Modified"); v != "" {
+		os.WriteFile(validatorPath(part), []byte(v), 0o644)
+	} else {
+		os.Remove(validatorPath(part))
+	}
+
+	counter := &progressWriter{read: offset, total: total, onProgress: onProgress}
+	if _, err := io.Copy(io.MultiWriter(f, counter), resp.Body); err != nil {
+		return "", true, fmt.Errorf("error reading %s: %w", url, err)
+	}
+	if total >= 0 && counter.read != total {
+		return "", true, fmt.Errorf("incomplete download of %s: got %d of %d bytes", url, counter.read, total)
+	}
+	return contentTypeOf(resp.Header), false, nil
+}
+
+// parseContentRange interpreta "bytes início-fim/total" e "bytes */total".
+func parseContentRange(s string) (start, total int64, ok bool) {
+	s, found := strings.CutPrefix(s, "bytes ")
+	if !found {
+		return 0, 0, false
+	}
+	rng, size, found := strings.Cut(s, "/")
+	if !found {
+		return 0, 0, false
+	}
+	total, err := strconv.ParseInt(size, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	if rng == "*" {
+		return 0, total, true
+	}
+	first, _, found := strings.Cut(rng, "-")
+	if !found {
+		return 0, 0, false
+	}
+	start, err = strconv.ParseInt(first, 10, 64)
+	return start, total, err == nil
+}
+
+func contentTypeOf(h http.Header) string {
+	return contentType(h.Get("Content-Type"))
+}
+
+// validatorPath é o arquivo que guarda o ETag ou Last-Modified do download
+// parcial, usado para confirmar que o arquivo remoto não mudou ao retomar.
+func validatorPath(part string) string {
+	return part + ".validator"
+}
+
+func removePartial(part string) {
+	os.Remove(part)
+	os.Remove(validatorPath(part))
+}
+
+// PrunePartial apaga de dir os downloads parciais sem modificação há mais de
+// maxAge. Eles ficam em dir para que uma importação interrompida seja
+// retomada, mas os abandonados não devem ocupar espaço para sempre. Arquivos
+// completos não são tocados, pois podem estar à espera de um envio.
+func PrunePartial(dir string, maxAge time.Duration) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || entry.IsDir() || time.Since(info.ModTime()) < maxAge {
+			continue
+		}
+		name := entry.Name()
+		if !strings.HasSuffix(name, ".part") && !strings.HasSuffix(name, validatorPath(".part")) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, name)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,110 @@
+package remote
+
+import (
+	"NostrFilePublisher/sniff"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// Info descreve um arquivo remoto lido por completo.
+type Info struct {
+	URL      string
+	Sha256   string
+	Size     int64
+	MimeType string
+}
+
+// Progress é chamado durante o download com os bytes lidos e o total
+// informado pelo servidor (ou -1 se desconhecido).
+type Progress func(read, total int64)
+
+// Fetch baixa a URL uma única vez, calculando o hash SHA-256 e o tamanho
+// enquanto lê, sem guardar o arquivo. O tipo MIME vem do cabeçalho
+// Content-Type, quando específico, ou dos primeiros bytes do conteúdo.
+// O download é interrompido quando ctx é cancelado; o cliente não deve ter um
+// Timeout global, que cortaria arquivos grandes.
+func Fetch(ctx context.Context, client *http.Client, url string, onProgress Progress) (Info, error) {
+	info := Info{URL: url}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return info, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return info, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return info, fmt.Errorf("unexpected status fetching %s: %s", url, resp.Status)
+	}
+
+	h := sha256.New()
+	counter := &progressWriter{total: resp.ContentLength, onProgress: onProgress}
+	header := &headerWriter{limit: sniff.HeaderSize}
+	size, err := io.Copy(io.MultiWriter(h, counter, header), resp.Body)
+	if err != nil {
+		return info, fmt.Errorf("error reading %s: %w", url, err)
+	}
+	if resp.ContentLength >= 0 && size != resp.ContentLength {
+		return info, fmt.Errorf("incomplete download of %s: got %d of %d bytes", url, size, resp.ContentLength)
+	}
+
+	info.Sha256 = hex.EncodeToString(h.Sum(nil))
+	info.Size = size
+	info.MimeType = contentType(resp.Header.Get("Content-Type"))
+	if info.MimeType == "" {
+		info.MimeType = sniff.Detect(header.buf, url)
+	}
+	return info, nil
+}
+
+// contentType devolve o tipo do cabeçalho sem parâmetros, ou "" se ele for
+// ausente ou genérico.
+func contentType(header string) string {
+	mediaType, _, err := mime.ParseMediaType(header)
+	if err != nil {
+		return ""
+	}
+	switch mediaType {
+	case "application/octet-stream", "binary/octet-stream", "application/binary":
+		return ""
+	}
+	// Servidores de arquivos costumam responder text/plain para tudo o que não conhecem.
+	if strings.HasPrefix(mediaType, "text/plain") {
+		return ""
+	}
+	return mediaType
+}
+
+// progressWriter conta os bytes lidos e informa o progresso.
+type progressWriter struct {
+	read, total int64
+	onProgress  Progress
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	w.read += int64(len(p))
+	if w.onProgress != nil {
+		w.onProgress(w.read, w.total)
+	}
+	return len(p), nil
+}
+
+// headerWriter guarda os primeiros bytes do conteúdo para a detecção do tipo.
+type headerWriter struct {
+	buf   []byte
+	limit int
+}
+
+func (w *headerWriter) Write(p []byte) (int, error) {
+	if room := w.limit - len(w.buf); room > 0 {
+		w.buf = append(w.buf, p[:min(room, len(p))]...)
+	}
+	return len(p), nil
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"NostrFilePublisher/remote"
+	"NostrFilePublisher/util"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	urlX "net/url"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// validateURL valida uma URL http(s) absoluta.
+func validateURL(s string) error {
+	if s == "" {
+		return fmt.Errorf("a URL não pode estar vazia")
+	}
+	parsed, err := urlX.ParseRequestURI(s)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("URL inválida: %s", s)
+	}
+	return nil
+}
+
+// showRemoteSourceDialog pede a URL de um arquivo já hospedado e, opcionalmente,
+// URLs alternativas para o mesmo arquivo. O arquivo é baixado uma única vez
+// para calcular o SHA-256, o tamanho e o tipo MIME, a menos que o usuário
+// informe o hash e o tamanho manualmente. onSaved recebe o resultado.
+func showRemoteSourceDialog(win fyne.Window, placeholder string, onSaved func(info remote.Info, fallbacks []string)) {
+	urlEntry := widget.NewEntry()
+	urlEntry.SetPlaceHolder(placeholder)
+	urlEntry.Validator = validateURL
+
+	fallbacksEntry := widget.NewMultiLineEntry()
+	fallbacksEntry.SetPlaceHolder("URLs alternativas, uma por linha (opcional)")
+	fallbacksEntry.SetMinRowsVisible(3)
+
+	sha256Entry := widget.NewEntry()
+	sha256Entry.SetPlaceHolder("b1674191a88ec5cdd733e4240a81803105dc412d6c6708d53ab94fc248f4f553")
+	sha256Entry.Validator = func(s string) error {
+		if s == "" {
+			return nil
+		}
+		if _, err := hex.DecodeString(s); err != nil || len(s) != 64 {
+			return fmt.Errorf("SHA-256 inválido, deve ter 64 caracteres hexadecimais")
+		}
+		return nil
+	}
+	sizeEntry := widget.NewEntry()
+	sizeEntry.SetPlaceHolder("184292")
+	sizeEntry.Validator = func(s string) error {
+		if s == "" {
+			return nil
+		}
+		if size, err := strconv.ParseInt(s, 10, 64); err != nil || size <= 0 {
+			return fmt.Errorf("tamanho inválido, deve ser um número positivo")
+		}
+		return nil
+	}
+
+	var d dialog.Dialog
+	saveButton := widget.NewButton("Salvar", func() {
+		for _, entry := range []*widget.Entry{urlEntry, sha256Entry, sizeEntry} {
+			if err := entry.Validate(); err != nil {
+				dialog.ShowError(err, win)
+				return
+			}
+		}
+		sourceURL := strings.TrimSpace(urlEntry.Text)
+		var fallbacks []string
+		for _, line := range strings.Split(fallbacksEntry.Text, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || line == sourceURL {
+				continue
+			}
+			if err := validateURL(line); err != nil {
+				dialog.ShowError(err, win)
+				return
+			}
+			fallbacks = append(fallbacks, line)
+		}
+
+		// Hash e tamanho informados manualmente dispensam o download
+		if sha256Entry.Text != "" || sizeEntry.Text != "" {
+			if sha256Entry.Text == "" || sizeEntry.Text == "" {
+				dialog.ShowInformation("Atenção", "Informe o SHA-256 e o tamanho, ou deixe ambos em branco para baixar o arquivo.", win)
+				return
+			}
+			size, _ := strconv.ParseInt(sizeEntry.Text, 10, 64)
+			mimeType, err := util.GetMimeFromUrl(App.HttpClient, sourceURL)
+			if err != nil || mimeType == "" {
+				log.Println("Erro ao detectar MIME da URL:", err)
+				mimeType = "application/octet-stream"
+			}
+			d.Hide()
+			onSaved(remote.Info{URL: sourceURL, Sha256: strings.ToLower(sha256Entry.Text), Size: size, MimeType: mimeType}, fallbacks)
+			return
+		}
+
+		fetchRemoteSource(win, sourceURL, func(info remote.Info) {
+			d.Hide()
+			onSaved(info, fallbacks)
+		})
+	})
+
+	d = dialog.NewCustom("Definir URL Manualmente", "Fechar", container.NewVBox(
+		widget.NewLabel("URL do arquivo:"),
+		urlEntry,
+		widget.NewLabel("URLs alternativas (fallback):"),
+		fallbacksEntry,
+		widget.NewSeparator(),
+		widget.NewLabel("Deixe em branco para baixar o arquivo e calcular o SHA-256, o tamanho e o tipo:"),
+		widget.NewForm(
+			widget.NewFormItem("SHA-256", sha256Entry),
+			widget.NewFormItem("Tamanho (bytes)", sizeEntry),
+		),
+		saveButton,
+	), win)
+	d.Resize(fyne.NewSize(600, 0))
+	d.Show()
+}
+
+// fetchRemoteSource baixa a URL exibindo o progresso, com a opção de cancelar.
+func fetchRemoteSource(win fyne.Window, sourceURL string, onDone func(remote.Info)) {
+	runDownload(win, "Calculando Hash", sourceURL,
+		func(ctx context.Context, client *http.Client, onProgress remote.Progress) (remote.Info, error) {
+			return remote.Fetch(ctx, client, sourceURL, onProgress)
+		}, onDone)
+}
+
+// runDownload executa fn em segundo plano exibindo uma barra de progresso e o
+// botão "Cancelar". onDone é chamado na thread da interface apenas em caso de
+// sucesso; erros são exibidos ao usuário.
+func runDownload(win fyne.Window, title, sourceURL string, fn func(ctx context.Context, client *http.Client, onProgress remote.Progress) (remote.Info, error), onDone func(remote.Info)) {
+	ctx, cancel := context.WithCancel(context.Background())
+	bar := widget.NewProgressBar()
+	status := widget.NewLabel("Baixando " + sourceURL)
+	status.Wrapping = fyne.TextWrapBreak
+	progress := dialog.NewCustom(title, "Cancelar", container.NewVBox(status, bar), win)
+	progress.SetOnClosed(cancel)
+	progress.Resize(fyne.NewSize(500, 0))
+	progress.Show()
+
+	// Sem o Timeout do cliente padrão, que interromperia arquivos grandes; o
+	// cancelamento é feito pelo contexto.
+	client := &http.Client{Transport: App.HttpClient.Transport}
+	lastPercent := -1
+	go func() {
+		info, err := fn(ctx, client, func(read, total int64) {
+			if total <= 0 {
+				return
+			}
+			percent := int(read * 100 / total)
+			if percent == lastPercent {
+				return
+			}
+			lastPercent = percent
+			fyne.Do(func() { bar.SetValue(float64(percent) / 100) })
+		})
+		fyne.Do(func() {
+			if errors.Is(ctx.Err(), context.Canceled) {
+				return
+			}
+			progress.Hide()
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("Erro ao baixar o arquivo: %w", err), win)
+				return
+			}
+			log.Printf("Arquivo remoto: %s | SHA-256 %s | %d bytes | %s", info.URL, info.Sha256, info.Size, info.MimeType)
+			onDone(info)
+		})
+	}()
+}
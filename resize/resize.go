@@ -0,0 +1,104 @@
+package resize
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+
+	"golang.org/x/image/draw"
+)
+
+// DefaultWidths são as larguras padrão das variantes responsivas.
+var DefaultWidths = []int{320, 720, 1280}
+
+// ThumbWidth é a largura da miniatura gerada junto com as variantes.
+const ThumbWidth = 160
+
+// jpegQuality é a qualidade usada ao codificar variantes opacas.
+const jpegQuality = 85
+
+// Rendition é uma versão redimensionada e já codificada de uma imagem.
+type Rendition struct {
+	Width, Height int
+	MimeType      string
+	Data          []byte
+
+	// Thumb indica a miniatura, que não faz parte das variantes responsivas.
+	Thumb bool
+}
+
+// Dim devolve as dimensões no formato "<largura>x<altura>".
+func (r Rendition) Dim() string {
+	return fmt.Sprintf("%dx%d", r.Width, r.Height)
+}
+
+// Renditions gera uma variante para cada largura menor que a da imagem
+// original, mais a miniatura. As imagens nunca são ampliadas. Imagens com
+// transparência são codificadas em PNG; as demais, em JPEG.
+func Renditions(ctx context.Context, img image.Image, widths []int) ([]Rendition, error) {
+	srcWidth := img.Bounds().Dx()
+	opaque := isOpaque(img)
+
+	var out []Rendition
+	for _, width := range widths {
+		if width >= srcWidth {
+			continue
+		}
+		r, err := render(ctx, img, width, opaque)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+
+	thumb, err := render(ctx, img, min(ThumbWidth, srcWidth), opaque)
+	if err != nil {
+		return nil, err
+	}
+	thumb.Thumb = true
+	return append(out, thumb), nil
+}
+
+// Resize redimensiona a imagem para a largura informada, mantendo a proporção.
+func Resize(img image.Image, width int) image.Image {
+	bounds := img.Bounds()
+	height := max(1, bounds.Dy()*width/bounds.Dx())
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+	return dst
+}
+
+// render redimensiona e codifica uma variante.
+func render(ctx context.Context, img image.Image, width int, opaque bool) (Rendition, error) {
+	if err := ctx.Err(); err != nil {
+		return Rendition{}, err
+	}
+	resized := Resize(img, width)
+
+	var buf bytes.Buffer
+	r := Rendition{Width: resized.Bounds().Dx(), Height: resized.Bounds().Dy()}
+	if opaque {
+		r.MimeType = "image/jpeg"
+		if err := jpeg.Encode(&buf, resized, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return Rendition{}, fmt.Errorf("error encoding %dpx rendition: %w", width, err)
+		}
+	} else {
+		r.MimeType = "image/png"
+		if err := png.Encode(&buf, resized); err != nil {
+			return Rendition{}, fmt.Errorf("error encoding %dpx rendition: %w", width, err)
+		}
+	}
+	r.Data = buf.Bytes()
+	return r, nil
+}
+
+// isOpaque informa se a imagem não tem pixels transparentes.
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	return false
+}
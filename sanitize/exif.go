@@ -0,0 +1,88 @@
+package sanitize
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+const (
+	tagOrientation = 0x0112
+	tagGPSInfo     = 0x8825
+	typeShort      = 3
+)
+
+// exifHeader precede os dados TIFF nos segmentos APP1 do JPEG.
+var exifHeader = []byte("Exif\x00\x00")
+
+// exifInfo reúne o que é lido de um bloco EXIF antes de descartá-lo.
+type exifInfo struct {
+	orientation int
+	hasGPS      bool
+
+	// orientationOnly indica um bloco que só contém a orientação, como o
+	// gravado por minimalEXIF.
+	orientationOnly bool
+}
+
+// parseEXIF lê a orientação e a presença do bloco GPS no IFD0 de um bloco
+// TIFF/EXIF. Blocos inválidos devolvem valores vazios: eles serão removidos de
+// qualquer forma.
+func parseEXIF(tiff []byte) exifInfo {
+	var info exifInfo
+	tiff = bytes.TrimPrefix(tiff, exifHeader)
+	if len(tiff) < 8 {
+		return info
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return info
+	}
+	if order.Uint16(tiff[2:4]) != 42 {
+		return info
+	}
+
+	ifd := int(order.Uint32(tiff[4:8]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return info
+	}
+	count := int(order.Uint16(tiff[ifd : ifd+2]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			break
+		}
+		switch order.Uint16(tiff[entry : entry+2]) {
+		case tagOrientation:
+			if order.Uint16(tiff[entry+2:entry+4]) == typeShort {
+				info.orientation = int(order.Uint16(tiff[entry+8 : entry+10]))
+			}
+		case tagGPSInfo:
+			info.hasGPS = true
+		}
+	}
+	if info.orientation < 1 || info.orientation > 8 {
+		info.orientation = 0
+	}
+	info.orientationOnly = count == 1 && info.orientation > 0
+	return info
+}
+
+// minimalEXIF monta um bloco TIFF/EXIF com apenas a tag de orientação.
+func minimalEXIF(orientation int) []byte {
+	b := make([]byte, 0, 26)
+	b = append(b, 'I', 'I', 42, 0)                          // ordem little-endian e número mágico
+	b = binary.LittleEndian.AppendUint32(b, 8)              // deslocamento do IFD0
+	b = binary.LittleEndian.AppendUint16(b, 1)              // uma entrada
+	b = binary.LittleEndian.AppendUint16(b, tagOrientation) // tag
+	b = binary.LittleEndian.AppendUint16(b, typeShort)      // tipo
+	b = binary.LittleEndian.AppendUint32(b, 1)              // quantidade
+	b = binary.LittleEndian.AppendUint16(b, uint16(orientation))
+	b = append(b, 0, 0)                        // preenchimento do valor
+	b = binary.LittleEndian.AppendUint32(b, 0) // sem próximo IFD
+	return b
+}
@@ -0,0 +1,110 @@
+package sanitize
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// Marcadores JPEG usados na leitura dos segmentos.
+const (
+	markerSOI   = 0xD8
+	markerEOI   = 0xD9
+	markerSOS   = 0xDA
+	markerAPP0  = 0xE0
+	markerAPP1  = 0xE1
+	markerAPP2  = 0xE2
+	markerAPP13 = 0xED
+	markerAPP14 = 0xEE
+	markerAPP15 = 0xEF
+	markerCOM   = 0xFE
+)
+
+var (
+	xmpHeader         = []byte("http://ns.adobe.com/xap/1.0/\x00")
+	xmpExtendedHeader = []byte("http://ns.adobe.com/xmp/extension/\x00")
+	iptcHeader        = []byte("Photoshop 3.0\x00")
+)
+
+// sanitizeJPEG percorre os segmentos até o início dos dados da imagem (SOS),
+// mantendo apenas os necessários para decodificá-la e exibi-la corretamente:
+// JFIF (APP0), perfil de cor ICC (APP2) e Adobe (APP14), além dos segmentos
+// que não são de aplicação (tabelas, quadro etc.).
+func sanitizeJPEG(data []byte) ([]byte, Report, error) {
+	var report Report
+	var kept [][]byte
+	pos := 2
+	for {
+		if pos+4 > len(data) || data[pos] != 0xFF {
+			return nil, report, fmt.Errorf("invalid JPEG segment at offset %d", pos)
+		}
+		marker := data[pos+1]
+		if marker == 0xFF {
+			// Bytes de preenchimento entre segmentos.
+			pos++
+			continue
+		}
+		if marker == markerSOS || marker == markerEOI {
+			break
+		}
+		length := int(binary.BigEndian.Uint16(data[pos+2 : pos+4]))
+		end := pos + 2 + length
+		if length < 2 || end > len(data) {
+			return nil, report, fmt.Errorf("truncated JPEG segment 0x%X", marker)
+		}
+		segment := data[pos:end]
+		body := segment[4:]
+		pos = end
+
+		switch {
+		case marker == markerAPP1 && bytes.HasPrefix(body, exifHeader):
+			report.addEXIF(body[len(exifHeader):])
+		case marker == markerAPP1 && (bytes.HasPrefix(body, xmpHeader) || bytes.HasPrefix(body, xmpExtendedHeader)):
+			report.add(XMP)
+		case marker == markerAPP13 && bytes.HasPrefix(body, iptcHeader):
+			report.add(IPTC)
+		case marker == markerCOM:
+			report.add(Comment)
+		case marker >= markerAPP0 && marker <= markerAPP15 &&
+			marker != markerAPP0 && marker != markerAPP2 && marker != markerAPP14:
+			report.add(Unknown)
+		default:
+			kept = append(kept, segment)
+		}
+	}
+	if !report.Changed() {
+		return data, report, nil
+	}
+
+	var out bytes.Buffer
+	out.Grow(len(data))
+	out.Write([]byte{0xFF, markerSOI})
+	insertAt := 0
+	if len(kept) > 0 && kept[0][1] == markerAPP0 {
+		// O JFIF deve continuar sendo o primeiro segmento.
+		insertAt = 1
+	}
+	for i, segment := range kept {
+		if i == insertAt {
+			writeOrientationAPP1(&out, report.Orientation)
+		}
+		out.Write(segment)
+	}
+	if insertAt >= len(kept) {
+		writeOrientationAPP1(&out, report.Orientation)
+	}
+	out.Write(data[pos:])
+	return out.Bytes(), report, nil
+}
+
+// writeOrientationAPP1 grava um segmento APP1 com o EXIF mínimo de orientação,
+// se a imagem tinha uma orientação diferente da padrão.
+func writeOrientationAPP1(out *bytes.Buffer, orientation int) {
+	if orientation <= oriented {
+		return
+	}
+	exif := append(append([]byte{}, exifHeader...), minimalEXIF(orientation)...)
+	out.Write([]byte{0xFF, markerAPP1})
+	out.Write(binary.BigEndian.AppendUint16(nil, uint16(len(exif)+2)))
+	out.Write(exif)
+}
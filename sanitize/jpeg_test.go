@@ -0,0 +1,145 @@
+package sanitize
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/jpeg"
+	"slices"
+	"testing"
+)
+
+// baseJPEG codifica uma imagem pequena, sem segmentos de aplicação.
+func baseJPEG(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewGray(image.Rect(0, 0, 8, 8)), nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// segment monta um segmento JPEG com o marcador e o corpo informados.
+func segment(marker byte, body []byte) []byte {
+	s := []byte{0xFF, marker}
+	s = binary.BigEndian.AppendUint16(s, uint16(len(body)+2))
+	return append(s, body...)
+}
+
+// withSegments insere os segmentos logo após o SOI da imagem base.
+func withSegments(base []byte, segments ...[]byte) []byte {
+	out := []byte{0xFF, markerSOI}
+	for _, s := range segments {
+		out = append(out, s...)
+	}
+	return append(out, base[2:]...)
+}
+
+// exifTIFF monta um bloco EXIF com a orientação e, se gps for verdadeiro, um
+// ponteiro para o IFD de GPS.
+func exifTIFF(orientation int, gps bool) []byte {
+	entries := 1
+	if gps {
+		entries++
+	}
+	b := append([]byte{}, exifHeader...)
+	b = append(b, 'I', 'I', 42, 0)
+	b = binary.LittleEndian.AppendUint32(b, 8)
+	b = binary.LittleEndian.AppendUint16(b, uint16(entries))
+	b = binary.LittleEndian.AppendUint16(b, tagOrientation)
+	b = binary.LittleEndian.AppendUint16(b, typeShort)
+	b = binary.LittleEndian.AppendUint32(b, 1)
+	b = binary.LittleEndian.AppendUint32(b, uint32(orientation))
+	if gps {
+		b = binary.LittleEndian.AppendUint16(b, tagGPSInfo)
+		b = binary.LittleEndian.AppendUint16(b, 4)
+		b = binary.LittleEndian.AppendUint32(b, 1)
+		b = binary.LittleEndian.AppendUint32(b, 0)
+	}
+	return binary.LittleEndian.AppendUint32(b, 0)
+}
+
+func TestSanitizeJPEG(t *testing.T) {
+	base := baseJPEG(t)
+	jfif := segment(markerAPP0, []byte("JFIF\x00\x01\x01\x00\x00\x01\x00\x01\x00\x00"))
+	icc := segment(markerAPP2, []byte("ICC_PROFILE\x00\x01\x01"))
+	exifGPS := segment(markerAPP1, exifTIFF(6, true))
+	xmp := segment(markerAPP1, append(append([]byte{}, xmpHeader...), "<x:xmpmeta/>"...))
+	iptc := segment(markerAPP13, append(append([]byte{}, iptcHeader...), 0, 0))
+	comment := segment(markerCOM, []byte("Canon EOS"))
+	vendor := segment(0xE5, []byte("vendor data"))
+	orientationOnly := segment(markerAPP1, append(append([]byte{}, exifHeader...), minimalEXIF(3)...))
+
+	tests := []struct {
+		name        string
+		in          []byte
+		removed     []string
+		orientation int
+		// want é a imagem esperada depois da limpeza
+		want []byte
+	}{
+		{"sem metadados", base, nil, 0, base},
+		{"JFIF e ICC mantidos", withSegments(base, jfif, icc), nil, 0, withSegments(base, jfif, icc)},
+		{"EXIF com GPS", withSegments(base, jfif, exifGPS), []string{EXIF, GPS}, 6,
+			withSegments(base, jfif, segment(markerAPP1, append(append([]byte{}, exifHeader...), minimalEXIF(6)...)))},
+		{"XMP, IPTC e comentário", withSegments(base, xmp, iptc, comment), []string{XMP, IPTC, Comment}, 0, base},
+		{"APP desconhecido", withSegments(base, icc, vendor), []string{Unknown}, 0, withSegments(base, icc)},
+		{"só orientação", withSegments(base, orientationOnly), nil, 3, withSegments(base, orientationOnly)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, report, err := Sanitize(tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(report.Removed, tt.removed) {
+				t.Errorf("Removed = %q, want %q", report.Removed, tt.removed)
+			}
+			if report.Orientation != tt.orientation {
+				t.Errorf("Orientation = %d, want %d", report.Orientation, tt.orientation)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("output differs from the expected segments")
+			}
+			if _, err := jpeg.Decode(bytes.NewReader(got)); err != nil {
+				t.Errorf("sanitized JPEG does not decode: %v", err)
+			}
+
+			// Sanitizar de novo não muda nada
+			again, report, err := Sanitize(got)
+			if err != nil || report.Changed() || !bytes.Equal(again, got) {
+				t.Errorf("second pass changed the image: %q, %v", report.Removed, err)
+			}
+		})
+	}
+}
+
+func TestSanitizeJPEGTruncated(t *testing.T) {
+	full := withSegments(baseJPEG(t), segment(markerAPP1, exifTIFF(1, true)))
+	for _, n := range []int{3, 5, 20} {
+		if _, _, err := Sanitize(full[:n]); err == nil {
+			t.Errorf("Sanitize(%d bytes) returned no error", n)
+		}
+	}
+}
+
+func TestOrientation(t *testing.T) {
+	base := baseJPEG(t)
+	tests := []struct {
+		name string
+		in   []byte
+		want int
+	}{
+		{"sem EXIF", base, 1},
+		{"girada", withSegments(base, segment(markerAPP1, exifTIFF(8, false))), 8},
+		{"orientação inválida", withSegments(base, segment(markerAPP1, exifTIFF(9, true))), 1},
+		{"formato não suportado", []byte("GIF89a"), 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Orientation(tt.in); got != tt.want {
+				t.Errorf("Orientation = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package sanitize
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+)
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// xmpKeyword é a palavra-chave dos blocos de texto PNG que carregam XMP.
+const xmpKeyword = "XML:com.adobe.xmp"
+
+// sanitizePNG remove os blocos eXIf, tEXt, zTXt e iTXt. Se havia orientação, um
+// bloco eXIf mínimo é gravado logo após o IHDR, antes dos dados da imagem.
+func sanitizePNG(data []byte) ([]byte, Report, error) {
+	var report Report
+	var out bytes.Buffer
+	out.Grow(len(data))
+	out.Write(pngSignature)
+
+	var ihdrEnd int
+	pos := len(pngSignature)
+	for pos < len(data) {
+		if pos+12 > len(data) {
+			return nil, report, fmt.Errorf("truncated PNG chunk at offset %d", pos)
+		}
+		length := int(binary.BigEndian.Uint32(data[pos : pos+4]))
+		chunkType := string(data[pos+4 : pos+8])
+		end := pos + 12 + length
+		if length < 0 || end > len(data) {
+			return nil, report, fmt.Errorf("truncated PNG chunk %q", chunkType)
+		}
+		body := data[pos+8 : pos+8+length]
+
+		switch chunkType {
+		case "eXIf":
+			report.addEXIF(body)
+		case "tEXt", "zTXt", "iTXt":
+			keyword, _, _ := bytes.Cut(body, []byte{0})
+			if string(keyword) == xmpKeyword {
+				report.add(XMP)
+			} else {
+				report.add(PNGText)
+			}
+		default:
+			out.Write(data[pos:end])
+			if chunkType == "IHDR" {
+				ihdrEnd = out.Len()
+			}
+		}
+		pos = end
+		if chunkType == "IEND" {
+			break
+		}
+	}
+	if !report.Changed() {
+		return data, report, nil
+	}
+	if report.Orientation <= oriented || ihdrEnd == 0 {
+		return out.Bytes(), report, nil
+	}
+
+	clean := out.Bytes()
+	var withEXIF bytes.Buffer
+	withEXIF.Grow(len(clean) + 40)
+	withEXIF.Write(clean[:ihdrEnd])
+	writePNGChunk(&withEXIF, "eXIf", minimalEXIF(report.Orientation))
+	withEXIF.Write(clean[ihdrEnd:])
+	return withEXIF.Bytes(), report, nil
+}
+
+// writePNGChunk grava um bloco PNG com tamanho e CRC.
+func writePNGChunk(out *bytes.Buffer, chunkType string, body []byte) {
+	out.Write(binary.BigEndian.AppendUint32(nil, uint32(len(body))))
+	start := out.Len()
+	out.WriteString(chunkType)
+	out.Write(body)
+	out.Write(binary.BigEndian.AppendUint32(nil, crc32.ChecksumIEEE(out.Bytes()[start:])))
+}
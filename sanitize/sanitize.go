@@ -0,0 +1,129 @@
+package sanitize
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// ErrUnsupported indica que o formato da imagem não é tratado pelo sanitizador.
+var ErrUnsupported = errors.New("unsupported image format")
+
+// Nomes dos metadados informados em Report.Removed.
+const (
+	EXIF    = "EXIF"
+	GPS     = "GPS"
+	XMP     = "XMP"
+	IPTC    = "IPTC"
+	Comment = "comentários"
+	PNGText = "textos PNG"
+	Unknown = "metadados desconhecidos"
+)
+
+// oriented é a orientação padrão, que dispensa a tag EXIF.
+const oriented = 1
+
+// Report descreve o que foi removido de uma imagem.
+type Report struct {
+	// Removed lista os metadados removidos, sem repetições, na ordem em que foram encontrados.
+	Removed []string
+
+	// Orientation é a orientação EXIF preservada (1 a 8), ou 0 se a imagem não tinha orientação.
+	Orientation int
+}
+
+// Changed informa se algum metadado foi removido.
+func (r Report) Changed() bool {
+	return len(r.Removed) > 0
+}
+
+// add registra um metadado removido, ignorando repetições.
+func (r *Report) add(name string) {
+	for _, n := range r.Removed {
+		if n == name {
+			return
+		}
+	}
+	r.Removed = append(r.Removed, name)
+}
+
+// addEXIF registra a remoção de um bloco EXIF e guarda sua orientação. Um
+// bloco que só contém a orientação não conta como removido, já que é regravado
+// igual; assim, sanitizar uma imagem já limpa não a altera.
+func (r *Report) addEXIF(tiff []byte) {
+	info := parseEXIF(tiff)
+	if !info.orientationOnly {
+		r.add(EXIF)
+	}
+	if info.hasGPS {
+		r.add(GPS)
+	}
+	if info.orientation > oriented && r.Orientation == 0 {
+		r.Orientation = info.orientation
+	}
+}
+
+// Sanitize remove EXIF, GPS, XMP e outros metadados de uma imagem JPEG, PNG ou
+// WebP. A orientação EXIF é preservada em um bloco EXIF mínimo, para que a
+// imagem continue sendo exibida na posição correta. Os dados de imagem não são
+// recodificados.
+func Sanitize(data []byte) ([]byte, Report, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8}):
+		return sanitizeJPEG(data)
+	case bytes.HasPrefix(data, pngSignature):
+		return sanitizePNG(data)
+	case len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		return sanitizeWebP(data)
+	}
+	return nil, Report{}, ErrUnsupported
+}
+
+// Supported informa se o tipo MIME é tratado por Sanitize.
+func Supported(mimeType string) bool {
+	switch mimeType {
+	case "image/jpeg", "image/png", "image/webp":
+		return true
+	}
+	return false
+}
+
+// File sanitiza a imagem em path. Se algo for removido, a versão limpa é
+// gravada em um arquivo temporário, cujo caminho é devolvido; cabe ao chamador
+// removê-lo. Se nada for removido, o próprio path é devolvido.
+func File(path string) (string, Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", Report{}, err
+	}
+	clean, report, err := Sanitize(data)
+	if err != nil || !report.Changed() {
+		return path, report, err
+	}
+
+	tmp, err := os.CreateTemp("", "clean-*"+filepath.Ext(path))
+	if err != nil {
+		return "", report, err
+	}
+	if _, err := tmp.Write(clean); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", report, err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return "", report, err
+	}
+	return tmp.Name(), report, nil
+}
+
+// Orientation devolve a orientação EXIF da imagem (1 a 8), ou 1 se ela não
+// tiver orientação ou não for um formato suportado.
+func Orientation(data []byte) int {
+	_, report, err := Sanitize(data)
+	if err != nil || report.Orientation == 0 {
+		return oriented
+	}
+	return report.Orientation
+}
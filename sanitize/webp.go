@@ -0,0 +1,90 @@
+package sanitize
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// Bits do campo de flags do bloco VP8X.
+const (
+	vp8xFlagXMP  = 0x04
+	vp8xFlagEXIF = 0x08
+)
+
+// sanitizeWebP remove os blocos EXIF e XMP de um WebP estendido (VP8X) e
+// ajusta as flags e o tamanho do RIFF. Se havia orientação, um bloco EXIF
+// mínimo é mantido no fim do arquivo, como manda a especificação.
+func sanitizeWebP(data []byte) ([]byte, Report, error) {
+	var report Report
+	var chunks [][]byte
+	vp8x := -1
+
+	pos := 12
+	for pos < len(data) {
+		if pos+8 > len(data) {
+			return nil, report, fmt.Errorf("truncated WebP chunk at offset %d", pos)
+		}
+		fourCC := string(data[pos : pos+4])
+		length := int(binary.LittleEndian.Uint32(data[pos+4 : pos+8]))
+		end := pos + 8 + length + length%2
+		if end > len(data) {
+			if pos+8+length > len(data) {
+				return nil, report, fmt.Errorf("truncated WebP chunk %q", fourCC)
+			}
+			// Último bloco sem o byte de preenchimento.
+			end = pos + 8 + length
+		}
+		body := data[pos+8 : pos+8+length]
+
+		switch fourCC {
+		case "EXIF":
+			report.addEXIF(body)
+		case "XMP ":
+			report.add(XMP)
+		default:
+			if fourCC == "VP8X" {
+				vp8x = len(chunks)
+			}
+			chunks = append(chunks, data[pos:end])
+		}
+		pos = end
+	}
+	if !report.Changed() {
+		return data, report, nil
+	}
+
+	keepOrientation := report.Orientation > oriented && vp8x >= 0
+	if vp8x >= 0 {
+		header := append([]byte{}, chunks[vp8x]...)
+		if len(header) > 8 {
+			header[8] &^= vp8xFlagXMP | vp8xFlagEXIF
+			if keepOrientation {
+				header[8] |= vp8xFlagEXIF
+			}
+		}
+		chunks[vp8x] = header
+	}
+
+	var body bytes.Buffer
+	body.WriteString("WEBP")
+	for _, chunk := range chunks {
+		body.Write(chunk)
+		if len(chunk)%2 != 0 {
+			body.WriteByte(0)
+		}
+	}
+	if keepOrientation {
+		exif := minimalEXIF(report.Orientation)
+		body.WriteString("EXIF")
+		body.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(exif))))
+		body.Write(exif)
+	}
+
+	var out bytes.Buffer
+	out.Grow(body.Len() + 8)
+	out.WriteString("RIFF")
+	out.Write(binary.LittleEndian.AppendUint32(nil, uint32(body.Len())))
+	out.Write(body.Bytes())
+	return out.Bytes(), report, nil
+}
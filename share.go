@@ -0,0 +1,106 @@
+package main
+
+import (
+	"NostrFilePublisher/nip71"
+	"NostrFilePublisher/qr"
+	"fmt"
+	"log"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+)
+
+// maxRelayHints limita as dicas de relay incluídas nos links, mantendo o
+// código QR legível.
+const maxRelayHints = 3
+
+// shareLinks são as formas de compartilhar um evento publicado.
+type shareLinks struct {
+	// Bech32 é o nevent (eventos regulares) ou naddr (endereçáveis).
+	Bech32 string
+	// URI é o Bech32 com o prefixo "nostr:" (NIP-21).
+	URI string
+	// BlobURL é a URL do arquivo publicado, quando houver.
+	BlobURL string
+}
+
+// eventShareLinks monta os links do evento com as dicas dos relays que o aceitaram.
+func eventShareLinks(evt nostr.Event, relays []string) (shareLinks, error) {
+	var links shareLinks
+	hints := relays[:min(len(relays), maxRelayHints)]
+	var err error
+	if nostr.IsAddressableKind(evt.Kind) {
+		links.Bech32, err = nip19.EncodeEntity(evt.PubKey, evt.Kind, evt.Tags.GetD(), hints)
+	} else {
+		links.Bech32, err = nip19.EncodeEvent(evt.ID, hints, evt.PubKey)
+	}
+	if err != nil {
+		return links, err
+	}
+	links.URI = "nostr:" + links.Bech32
+
+	if tag := evt.Tags.Find("url"); tag != nil {
+		links.BlobURL = tag[1]
+	} else if variants := nip71.ParseVariants(evt.Tags); len(variants) > 0 {
+		links.BlobURL = variants[0].URL
+	}
+	return links, nil
+}
+
+// showShareDialog exibe os links do evento com botões para copiá-los e um
+// código QR do URI nostr: para abrir o evento no celular.
+func showShareDialog(win fyne.Window, evt nostr.Event, relays []string) {
+	links, err := eventShareLinks(evt, relays)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("Erro ao gerar os links do evento: %w", err), win)
+		return
+	}
+
+	row := func(label, value string) fyne.CanvasObject {
+		entry := widget.NewEntry()
+		entry.SetText(value)
+		return copyableRow(label, entry)
+	}
+	name := "nevent"
+	if strings.HasPrefix(links.Bech32, "naddr") {
+		name = "naddr"
+	}
+	rows := container.NewVBox(row(name, links.Bech32), row("URI", links.URI))
+	if links.BlobURL != "" {
+		rows.Add(row("Arquivo", links.BlobURL))
+	}
+
+	// Os leitores aceitam o bech32 em maiúsculas, que cabe no modo
+	// alfanumérico do QR e gera um código menor
+	var qrObject fyne.CanvasObject
+	if code, err := qr.Encode(strings.ToUpper(links.URI), qr.M); err != nil {
+		qrObject = widget.NewLabel(fmt.Sprintf("Não foi possível gerar o código QR: %v", err))
+	} else {
+		img := canvas.NewImageFromImage(code.Image(4, 4))
+		img.FillMode = canvas.ImageFillContain
+		img.ScaleMode = canvas.ImageScalePixels
+		img.SetMinSize(fyne.NewSize(260, 260))
+		qrObject = img
+	}
+
+	d := dialog.NewCustom("Compartilhar Evento", "Fechar", container.NewVBox(rows, container.NewCenter(qrObject)), win)
+	d.Resize(fyne.NewSize(650, 0))
+	d.Show()
+}
+
+// copyableRow exibe um campo com um rótulo e um botão que copia o texto atual
+// do campo para a área de transferência.
+func copyableRow(label string, entry *widget.Entry) fyne.CanvasObject {
+	copyButton := widget.NewButtonWithIcon("", theme.ContentCopyIcon(), func() {
+		myApp.Clipboard().SetContent(entry.Text)
+		log.Println("Copiado:", entry.Text)
+	})
+	return container.NewBorder(nil, nil, widget.NewLabel(label), copyButton, entry)
+}
@@ -0,0 +1,279 @@
+package sniff
+
+import (
+	"NostrFilePublisher/mpegaudio"
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// HeaderSize é a quantidade de bytes do início do arquivo usada na detecção.
+const HeaderSize = 4096
+
+// octetStream é o tipo genérico devolvido quando nada é reconhecido.
+const octetStream = "application/octet-stream"
+
+// Detect identifica o tipo MIME pelo número mágico no cabeçalho. Se o
+// cabeçalho não for reconhecido, usa a extensão de name (um caminho ou URL) e,
+// por fim, http.DetectContentType.
+func Detect(header []byte, name string) string {
+	if t := byMagic(header); t != "" {
+		return t
+	}
+	detected := http.DetectContentType(header)
+	if ext := ByExtension(name); ext != "" {
+		// A extensão só prevalece sobre resultados genéricos da biblioteca
+		// padrão. Se ela também indica texto simples, o charset detectado pelo
+		// conteúdo é mantido.
+		switch {
+		case detected == octetStream:
+			return ext
+		case strings.HasPrefix(detected, "text/plain") && !strings.HasPrefix(ext, "text/plain"):
+			return ext
+		}
+	}
+	return detected
+}
+
+// File identifica o tipo MIME de um arquivo local. Além do cabeçalho, o
+// índice de arquivos zip é lido para distinguir EPUB, APK, JAR e documentos
+// do Office e do OpenDocument.
+func File(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	header := make([]byte, HeaderSize)
+	n, err := io.ReadFull(f, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	header = header[:n]
+
+	t := Detect(header, path)
+	if t == zipType {
+		if stat, err := f.Stat(); err == nil {
+			if refined := zipContents(f, stat.Size()); refined != "" {
+				return refined, nil
+			}
+		}
+		if ext := ByExtension(path); ext != "" && zipBased[ext] {
+			return ext, nil
+		}
+	}
+	return t, nil
+}
+
+// extensionTypes cobre extensões ausentes ou com tipos divergentes nas
+// tabelas MIME de alguns sistemas.
+var extensionTypes = map[string]string{
+	".mkv":  "video/x-matroska",
+	".mka":  "audio/x-matroska",
+	".webm": "video/webm",
+	".mov":  "video/quicktime",
+	".mp4":  "video/mp4",
+	".m4v":  "video/x-m4v",
+	".m4a":  "audio/mp4",
+	".m4b":  "audio/mp4",
+	".aac":  "audio/aac",
+	".flac": "audio/flac",
+	".opus": "audio/ogg",
+	".ogg":  "audio/ogg",
+	".oga":  "audio/ogg",
+	".ogv":  "video/ogg",
+	".mp3":  "audio/mpeg",
+	".wav":  "audio/wav",
+	".aiff": "audio/aiff",
+	".avi":  "video/x-msvideo",
+	".ts":   "video/mp2t",
+	".3gp":  "video/3gpp",
+	".heic": "image/heic",
+	".avif": "image/avif",
+	".webp": "image/webp",
+	".epub": "application/epub+zip",
+	".apk":  "application/vnd.android.package-archive",
+	".jar":  "application/java-archive",
+	".7z":   "application/x-7z-compressed",
+	".rar":  "application/vnd.rar",
+	".xz":   "application/x-xz",
+	".zst":  "application/zstd",
+	".gz":   "application/gzip",
+	".bz2":  "application/x-bzip2",
+	".tar":  "application/x-tar",
+	".zip":  "application/zip",
+	".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	".xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	".pptx": "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+	".odt":  "application/vnd.oasis.opendocument.text",
+	".ods":  "application/vnd.oasis.opendocument.spreadsheet",
+	".odp":  "application/vnd.oasis.opendocument.presentation",
+	".srt":  "application/x-subrip",
+	".vtt":  "text/vtt",
+	".md":   "text/markdown",
+	".json": "application/json",
+	".wasm": "application/wasm",
+}
+
+// ByExtension devolve o tipo MIME pela extensão de um caminho ou URL, ou ""
+// se a extensão for desconhecida.
+func ByExtension(name string) string {
+	if i := strings.IndexAny(name, "?#"); i >= 0 && strings.Contains(name, "://") {
+		name = name[:i]
+	}
+	ext := strings.ToLower(filepath.Ext(name))
+	if ext == "" {
+		return ""
+	}
+	if t, ok := extensionTypes[ext]; ok {
+		return t
+	}
+	return mime.TypeByExtension(ext)
+}
+
+// byMagic reconhece o formato pelos primeiros bytes, ou devolve "".
+func byMagic(h []byte) string {
+	has := func(offset int, sig string) bool {
+		return len(h) >= offset+len(sig) && string(h[offset:offset+len(sig)]) == sig
+	}
+
+	switch {
+	case has(0, "\xef\xbb\xbf"), has(0, "\xff\xfe"), has(0, "\xfe\xff"):
+		// Texto com BOM: fica para a extensão e para http.DetectContentType,
+		// antes que o 0xFF inicial seja confundido com sincronismo de áudio.
+		return ""
+	case has(4, "ftyp"):
+		return isoBrand(h)
+	case has(0, "\x1a\x45\xdf\xa3"):
+		if bytes.Contains(h, []byte("webm")) {
+			return "video/webm"
+		}
+		return "video/x-matroska"
+	case has(0, "fLaC"):
+		return "audio/flac"
+	case has(0, "OggS"):
+		switch {
+		case has(28, "OpusHead"), has(28, "\x01vorbis"), has(28, "Speex"), has(28, "\x7fFLAC"):
+			return "audio/ogg"
+		case has(28, "\x80theora"):
+			return "video/ogg"
+		}
+		return "application/ogg"
+	case has(0, "ID3"):
+		return "audio/mpeg"
+	case len(h) >= 2 && h[0] == 0xFF && (h[1]&0xF6) == 0xF0:
+		// ADTS: sincronismo de 12 bits e camada 0.
+		return "audio/aac"
+	case mpegaudio.IsStream(h, 0):
+		return "audio/mpeg"
+	case has(0, "RIFF") && has(8, "WAVE"):
+		return "audio/wav"
+	case has(0, "RIFF") && has(8, "WEBP"):
+		return "image/webp"
+	case has(0, "RIFF") && has(8, "AVI "):
+		return "video/x-msvideo"
+	case has(0, "FORM") && (has(8, "AIFF") || has(8, "AIFC")):
+		return "audio/aiff"
+	case has(0, "#!AMR"):
+		return "audio/amr"
+	case has(0, "caff"):
+		return "audio/x-caf"
+	case has(0, "MThd"):
+		return "audio/midi"
+	case has(0, "FLV\x01"):
+		return "video/x-flv"
+	case has(0, "\x00\x00\x01\xba"):
+		return "video/mpeg"
+	case len(h) > 376 && h[0] == 0x47 && h[188] == 0x47 && h[376] == 0x47:
+		return "video/mp2t"
+	case has(0, "7z\xbc\xaf\x27\x1c"):
+		return "application/x-7z-compressed"
+	case has(0, "Rar!\x1a\x07"):
+		return "application/vnd.rar"
+	case has(0, "\xfd7zXZ\x00"):
+		return "application/x-xz"
+	case has(0, "\x28\xb5\x2f\xfd"):
+		return "application/zstd"
+	case has(0, "BZh"):
+		return "application/x-bzip2"
+	case has(0, "\x1f\x8b"):
+		return "application/gzip"
+	case has(257, "ustar"):
+		return "application/x-tar"
+	case has(0, "%PDF-"):
+		return "application/pdf"
+	case has(0, "\x00asm"):
+		return "application/wasm"
+	case has(0, "SQLite format 3\x00"):
+		return "application/vnd.sqlite3"
+	case has(0, "wOFF"):
+		return "font/woff"
+	case has(0, "wOF2"):
+		return "font/woff2"
+	case has(0, "OTTO"):
+		return "font/otf"
+	case has(0, "\x7fELF"):
+		return "application/x-elf"
+	case has(0, "PK\x03\x04"):
+		return zipHeader(h)
+	}
+	return ""
+}
+
+// isoBrand distingue os formatos baseados em ISO BMFF (MP4, MOV, M4A, HEIC,
+// AVIF, 3GP) pela marca principal da caixa ftyp.
+func isoBrand(h []byte) string {
+	if len(h) < 12 {
+		return "video/mp4"
+	}
+	switch brand := string(h[8:12]); {
+	case brand == "qt  ":
+		return "video/quicktime"
+	case brand == "M4A " || brand == "M4B " || brand == "M4P ":
+		return "audio/mp4"
+	case brand == "M4V " || brand == "M4VH" || brand == "M4VP":
+		return "video/x-m4v"
+	case brand == "avif" || brand == "avis":
+		return "image/avif"
+	case brand == "heic" || brand == "heix" || brand == "hevc" || brand == "mif1" || brand == "msf1":
+		return "image/heic"
+	case strings.HasPrefix(brand, "3gp"):
+		return "video/3gpp"
+	case strings.HasPrefix(brand, "3g2"):
+		return "video/3gpp2"
+	}
+	return "video/mp4"
+}
+
+// Valid informa se s é um tipo MIME bem formado, como "video/mp4".
+func Valid(s string) bool {
+	mediaType, _, err := mime.ParseMediaType(s)
+	return err == nil && strings.Count(mediaType, "/") == 1 &&
+		!strings.HasPrefix(mediaType, "/") && !strings.HasSuffix(mediaType, "/")
+}
+
+// Common são tipos frequentes oferecidos como sugestão ao corrigir o tipo detectado.
+var Common = []string{
+	"video/mp4",
+	"video/webm",
+	"video/quicktime",
+	"video/x-matroska",
+	"audio/mpeg",
+	"audio/mp4",
+	"audio/ogg",
+	"audio/flac",
+	"audio/wav",
+	"image/jpeg",
+	"image/png",
+	"image/webp",
+	"image/gif",
+	"application/pdf",
+	"application/zip",
+	"application/epub+zip",
+	"application/octet-stream",
+}
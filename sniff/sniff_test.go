@@ -0,0 +1,56 @@
+package sniff
+
+import (
+	"bytes"
+	"testing"
+)
+
+// mp3Frames devolve n quadros MPEG-1 Layer III de 128 kbps a 44,1 kHz (417 bytes).
+func mp3Frames(n int) []byte {
+	frame := append([]byte{0xFF, 0xFB, 0x90, 0x64}, make([]byte, 413)...)
+	return bytes.Repeat(frame, n)
+}
+
+// tsPackets devolve n pacotes MPEG-TS de 188 bytes.
+func tsPackets(n int) []byte {
+	packet := append([]byte{0x47}, make([]byte, 187)...)
+	return bytes.Repeat(packet, n)
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name   string
+		header []byte
+		file   string
+		want   string
+	}{
+		{"UTF-16LE com BOM", []byte("\xff\xfeH\x00e\x00l\x00l\x00o\x00"), "notes.txt", "text/plain; charset=utf-16le"},
+		{"UTF-8 com extensão de texto", []byte("Olá\n"), "notes.txt", "text/plain; charset=utf-8"},
+		{"UTF-16BE com BOM", []byte("\xfe\xff\x00H\x00i"), "", "text/plain; charset=utf-16be"},
+		{"UTF-8 com BOM", []byte("\xef\xbb\xbf1\n00:00:01,000 --> 00:00:02,000\nOi\n"), "legenda.srt", "application/x-subrip"},
+		{"MP3 com ID3", append([]byte("ID3\x04\x00\x00\x00\x00\x00\x00"), mp3Frames(1)...), "", "audio/mpeg"},
+		{"MP3 sem ID3", mp3Frames(3), "", "audio/mpeg"},
+		{"MP3 de um quadro curto", mp3Frames(1)[:100], "", "audio/mpeg"},
+		{"segundo quadro inválido", append(mp3Frames(1), 0, 0, 0, 0), "", "application/octet-stream"},
+		{"taxa de bits livre", []byte{0xFF, 0xFB, 0x00, 0x64, 0, 0, 0, 0}, "", "application/octet-stream"},
+		{"taxa de amostragem reservada", []byte{0xFF, 0xFB, 0x9C, 0x64, 0, 0, 0, 0}, "", "application/octet-stream"},
+		{"versão reservada", []byte{0xFF, 0xEB, 0x90, 0x64, 0, 0, 0, 0}, "", "application/octet-stream"},
+		{"ADTS", []byte{0xFF, 0xF1, 0x50, 0x80, 0, 0x1F, 0xFC}, "", "audio/aac"},
+		{"MPEG-TS", tsPackets(3), "", "video/mp2t"},
+		{"dois sincronismos TS", append(tsPackets(2), make([]byte, 12)...), "", "application/octet-stream"},
+		{"MP4", []byte("\x00\x00\x00\x18ftypisom\x00\x00\x02\x00"), "", "video/mp4"},
+		{"M4A", []byte("\x00\x00\x00\x18ftypM4A \x00\x00\x02\x00"), "", "audio/mp4"},
+		{"WebM", []byte("\x1a\x45\xdf\xa3\x9f\x42\x86\x81\x01\x42\x82\x84webm"), "", "video/webm"},
+		{"Opus", append(append([]byte("OggS"), make([]byte, 24)...), "OpusHead"...), "", "audio/ogg"},
+		{"PNG", []byte("\x89PNG\r\n\x1a\n"), "", "image/png"},
+		{"extensão em texto simples", []byte("# Título\n"), "README.md", "text/markdown"},
+		{"extensão não supera o conteúdo", []byte("%PDF-1.7\n"), "foto.jpg", "application/pdf"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Detect(tt.header, tt.file); got != tt.want {
+				t.Errorf("Detect = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package sniff
+
+import (
+	"archive/zip"
+	"encoding/binary"
+	"io"
+	"strings"
+)
+
+const zipType = "application/zip"
+
+// zipBased indica os tipos que usam o contêiner zip, para os quais a extensão
+// pode refinar a detecção.
+var zipBased = map[string]bool{
+	"application/epub+zip":                    true,
+	"application/vnd.android.package-archive": true,
+	"application/java-archive":                true,
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document":   true,
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":         true,
+	"application/vnd.openxmlformats-officedocument.presentationml.presentation": true,
+	"application/vnd.oasis.opendocument.text":                                   true,
+	"application/vnd.oasis.opendocument.spreadsheet":                            true,
+	"application/vnd.oasis.opendocument.presentation":                           true,
+}
+
+// zipHeader lê a primeira entrada do zip. EPUB e OpenDocument começam com um
+// arquivo "mimetype" sem compressão que contém o próprio tipo.
+func zipHeader(h []byte) string {
+	if len(h) < 30 {
+		return zipType
+	}
+	nameLen := int(binary.LittleEndian.Uint16(h[26:28]))
+	extraLen := int(binary.LittleEndian.Uint16(h[28:30]))
+	if 30+nameLen > len(h) {
+		return zipType
+	}
+	name := string(h[30 : 30+nameLen])
+	if name == "mimetype" {
+		start := 30 + nameLen + extraLen
+		size := int(binary.LittleEndian.Uint32(h[18:22]))
+		if size > 0 && size < 128 && start+size <= len(h) {
+			if t := strings.TrimSpace(string(h[start : start+size])); zipBased[t] {
+				return t
+			}
+		}
+	}
+	if t := zipKind([]string{name}); t != "" {
+		return t
+	}
+	return zipType
+}
+
+// zipContents lê o índice central do zip e identifica o formato pelas entradas.
+func zipContents(r io.ReaderAt, size int64) string {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return ""
+	}
+	names := make([]string, len(zr.File))
+	for i, f := range zr.File {
+		names[i] = f.Name
+		if f.Name == "mimetype" && f.UncompressedSize64 < 128 {
+			if t := readMimetypeEntry(f); zipBased[t] {
+				return t
+			}
+		}
+	}
+	return zipKind(names)
+}
+
+// readMimetypeEntry lê o conteúdo da entrada "mimetype" de EPUB e OpenDocument.
+func readMimetypeEntry(f *zip.File) string {
+	rc, err := f.Open()
+	if err != nil {
+		return ""
+	}
+	defer rc.Close()
+	b, err := io.ReadAll(io.LimitReader(rc, 128))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(b))
+}
+
+// zipKind identifica formatos baseados em zip pelos nomes das entradas.
+func zipKind(names []string) string {
+	set := make(map[string]bool, len(names))
+	var hasWord, hasXL, hasPPT bool
+	for _, n := range names {
+		set[n] = true
+		switch {
+		case strings.HasPrefix(n, "word/"):
+			hasWord = true
+		case strings.HasPrefix(n, "xl/"):
+			hasXL = true
+		case strings.HasPrefix(n, "ppt/"):
+			hasPPT = true
+		}
+	}
+	switch {
+	case set["AndroidManifest.xml"]:
+		return "application/vnd.android.package-archive"
+	case set["META-INF/container.xml"] && set["mimetype"]:
+		return "application/epub+zip"
+	case set["[Content_Types].xml"] && hasWord:
+		return "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	case set["[Content_Types].xml"] && hasXL:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case set["[Content_Types].xml"] && hasPPT:
+		return "application/vnd.openxmlformats-officedocument.presentationml.presentation"
+	case set["META-INF/MANIFEST.MF"]:
+		return "application/java-archive"
+	}
+	return ""
+}
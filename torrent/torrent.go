@@ -0,0 +1,239 @@
+package torrent
+
+import (
+	"context"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// blockSize é o tamanho dos blocos que formam as folhas da árvore de Merkle do BitTorrent v2.
+const blockSize = 16 << 10
+
+// targetPieces e maxPieceLength orientam a escolha do tamanho das peças: a
+// menor potência de dois a partir de 16 KiB que mantenha o número de peças
+// perto de targetPieces, sem passar de maxPieceLength.
+const (
+	targetPieces   = 1500
+	maxPieceLength = 16 << 20
+)
+
+// createdBy identifica o aplicativo no campo "created by" do arquivo .torrent.
+const createdBy = "NostrFilePublisher"
+
+// ErrEmptyFile indica que o arquivo não tem conteúdo para compartilhar.
+var ErrEmptyFile = errors.New("cannot create a torrent for an empty file")
+
+// Torrent é um torrent híbrido (BitTorrent v1 e v2) de um único arquivo.
+type Torrent struct {
+	Name        string
+	Length      int64
+	PieceLength int64
+
+	// InfoHashV1 é o SHA-1 do dicionário info; InfoHashV2 é o SHA-256 do mesmo dicionário.
+	InfoHashV1 [sha1.Size]byte
+	InfoHashV2 [sha256.Size]byte
+
+	// WebSeeds são URLs HTTP que servem o arquivo inteiro (BEP-19).
+	WebSeeds []string
+
+	// Trackers são os anunciadores incluídos no .torrent e no link magnet.
+	Trackers []string
+
+	CreatedAt time.Time
+
+	info        map[string]any
+	pieceLayers map[string]any
+}
+
+// PieceLengthFor devolve o tamanho de peça usado para um arquivo do tamanho informado.
+func PieceLengthFor(size int64) int64 {
+	pieceLength := int64(blockSize)
+	for size/pieceLength > targetPieces && pieceLength < maxPieceLength {
+		pieceLength *= 2
+	}
+	return pieceLength
+}
+
+// Build lê o arquivo uma única vez e calcula, para cada peça, o SHA-1 da
+// versão 1 e a raiz da subárvore de Merkle da versão 2 (BEP-52). name é o
+// nome do arquivo dentro do torrent.
+func Build(ctx context.Context, path, name string) (*Torrent, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if stat.Size() == 0 {
+		return nil, ErrEmptyFile
+	}
+	if name == "" || strings.ContainsAny(name, "/\\") {
+		return nil, fmt.Errorf("invalid torrent file name %q", name)
+	}
+
+	t := &Torrent{Name: name, Length: stat.Size(), PieceLength: PieceLengthFor(stat.Size()), CreatedAt: time.Now()}
+	blocksPerPiece := int(t.PieceLength / blockSize)
+
+	var v1Pieces []byte
+	var layer [][sha256.Size]byte // raízes das subárvores de cada peça (camada de peças)
+	var leaves [][sha256.Size]byte
+	buf := make([]byte, t.PieceLength)
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		n, err := io.ReadFull(f, buf)
+		if n > 0 {
+			piece := buf[:n]
+			sum := sha1.Sum(piece)
+			v1Pieces = append(v1Pieces, sum[:]...)
+
+			leaves = leaves[:0]
+			for len(piece) > 0 {
+				block := piece[:min(blockSize, len(piece))]
+				leaves = append(leaves, sha256.Sum256(block))
+				piece = piece[len(block):]
+			}
+			// A última peça é completada com folhas zeradas até o tamanho de uma peça inteira
+			layer = append(layer, merkleRoot(leaves, blocksPerPiece, [sha256.Size]byte{}))
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Arquivos de uma só peça não têm camada de peças: a raiz vem direto dos
+	// blocos, completados até a próxima potência de dois
+	var root [sha256.Size]byte
+	if len(layer) == 1 {
+		root = merkleRoot(leaves, nextPow2(len(leaves)), [sha256.Size]byte{})
+	} else {
+		pad := merkleRoot(nil, blocksPerPiece, [sha256.Size]byte{})
+		root = merkleRoot(layer, nextPow2(len(layer)), pad)
+		hashes := make([]byte, 0, len(layer)*sha256.Size)
+		for _, h := range layer {
+			hashes = append(hashes, h[:]...)
+		}
+		t.pieceLayers = map[string]any{string(root[:]): hashes}
+	}
+
+	t.info = map[string]any{
+		"name":         name,
+		"piece length": t.PieceLength,
+		"length":       t.Length,
+		"pieces":       v1Pieces,
+		"meta version": 2,
+		"file tree": map[string]any{
+			name: map[string]any{
+				"": map[string]any{
+					"length":      t.Length,
+					"pieces root": root[:],
+				},
+			},
+		},
+	}
+	info, err := bencode(t.info)
+	if err != nil {
+		return nil, err
+	}
+	t.InfoHashV1 = sha1.Sum(info)
+	t.InfoHashV2 = sha256.Sum256(info)
+	return t, nil
+}
+
+// V1 devolve o infohash v1 em hexadecimal (40 caracteres).
+func (t *Torrent) V1() string {
+	return hex.EncodeToString(t.InfoHashV1[:])
+}
+
+// V2 devolve o infohash v2 em hexadecimal (64 caracteres).
+func (t *Torrent) V2() string {
+	return hex.EncodeToString(t.InfoHashV2[:])
+}
+
+// Magnet monta o link magnet com os dois infohashes, o nome, o tamanho, os
+// trackers e os web seeds.
+func (t *Torrent) Magnet() string {
+	var b strings.Builder
+	b.WriteString("magnet:?xt=urn:btih:" + t.V1())
+	// btmh é o multihash do infohash v2: 0x12 (sha2-256) e 0x20 (32 bytes)
+	b.WriteString("&xt=urn:btmh:1220" + t.V2())
+	b.WriteString("&dn=" + url.QueryEscape(t.Name))
+	b.WriteString("&xl=" + strconv.FormatInt(t.Length, 10))
+	for _, tr := range t.Trackers {
+		b.WriteString("&tr=" + url.QueryEscape(tr))
+	}
+	for _, ws := range t.WebSeeds {
+		b.WriteString("&ws=" + url.QueryEscape(ws))
+	}
+	return b.String()
+}
+
+// Encode gera o conteúdo do arquivo .torrent.
+func (t *Torrent) Encode() ([]byte, error) {
+	meta := map[string]any{
+		"info":          t.info,
+		"created by":    createdBy,
+		"creation date": t.CreatedAt.Unix(),
+	}
+	if t.pieceLayers != nil {
+		meta["piece layers"] = t.pieceLayers
+	}
+	if len(t.Trackers) > 0 {
+		meta["announce"] = t.Trackers[0]
+		tiers := make([]any, len(t.Trackers))
+		for i, tr := range t.Trackers {
+			tiers[i] = []string{tr}
+		}
+		meta["announce-list"] = tiers
+	}
+	if len(t.WebSeeds) > 0 {
+		meta["url-list"] = t.WebSeeds
+	}
+	return bencode(meta)
+}
+
+// merkleRoot calcula a raiz de uma árvore binária SHA-256 com `width` folhas
+// (uma potência de dois), completando as folhas que faltam com pad.
+func merkleRoot(hashes [][sha256.Size]byte, width int, pad [sha256.Size]byte) [sha256.Size]byte {
+	level := make([][sha256.Size]byte, width)
+	copy(level, hashes)
+	for i := len(hashes); i < width; i++ {
+		level[i] = pad
+	}
+	for len(level) > 1 {
+		next := level[:len(level)/2]
+		for i := range next {
+			var pair [2 * sha256.Size]byte
+			copy(pair[:], level[2*i][:])
+			copy(pair[sha256.Size:], level[2*i+1][:])
+			next[i] = sha256.Sum256(pair[:])
+		}
+		level = next
+	}
+	return level[0]
+}
+
+// nextPow2 devolve a menor potência de dois maior ou igual a n.
+func nextPow2(n int) int {
+	p := 1
+	for p < n {
+		p *= 2
+	}
+	return p
+}
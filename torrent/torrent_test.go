@@ -0,0 +1,132 @@
+package torrent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBencode(t *testing.T) {
+	tests := []struct {
+		name string
+		in   any
+		want string
+	}{
+		{"inteiro", 42, "i42e"},
+		{"inteiro negativo", int64(-7), "i-7e"},
+		{"string", "spam", "4:spam"},
+		{"string vazia", "", "0:"},
+		{"bytes", []byte{0, 0xFF}, "2:\x00\xff"},
+		{"lista de strings", []string{"a", "bc"}, "l1:a2:bce"},
+		{"lista mista", []any{1, "x", []string{}}, "li1e1:xlee"},
+		{"dicionário ordenado", map[string]any{"zeta": 1, "alpha": 2, "mid": "v"}, "d5:alphai2e3:mid1:v4:zetai1ee"},
+		// A ordem é a dos bytes: maiúsculas antes de minúsculas e prefixos antes
+		{"ordem por bytes", map[string]any{"b": 1, "a": 2, "B": 3, "ab": 4}, "d1:Bi3e1:ai2e2:abi4e1:bi1ee"},
+		{"chave vazia", map[string]any{"": map[string]any{"length": 3}}, "d0:d6:lengthi3eee"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := bencode(tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("bencode = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := bencode(map[string]any{"x": 1.5}); err == nil {
+		t.Error("bencode(float) returned no error")
+	}
+}
+
+func TestPieceLengthFor(t *testing.T) {
+	tests := []struct {
+		size int64
+		want int64
+	}{
+		{1, 16 << 10},
+		{1500 * 16 << 10, 16 << 10},
+		{1500*16<<10 + 16<<10, 32 << 10},
+		{1 << 30, 1 << 20},
+		{1 << 40, maxPieceLength},
+	}
+	for _, tt := range tests {
+		if got := PieceLengthFor(tt.size); got != tt.want {
+			t.Errorf("PieceLengthFor(%d) = %d, want %d", tt.size, got, tt.want)
+		}
+	}
+}
+
+// TestBuild compara os infohashes com os de uma implementação independente
+// do BEP-3 e do BEP-52, para arquivos de uma peça, de várias peças de um bloco
+// e de peças com dois blocos e a última incompleta. O conteúdo é byte(i % 251).
+func TestBuild(t *testing.T) {
+	tests := []struct {
+		size        int
+		pieceLength int64
+		v1, v2      string
+		layers      bool
+	}{
+		{1000, 16 << 10, "923dbeb1d4e790bd450d1d346ff49ffba573d0c1",
+			"9e1c1fec8df5beaba01da3c9f0a433b3292e3008bb40b218b24b84d93c3bd4ff", false},
+		{40000, 16 << 10, "d70939ae59b832647dceeebaca407014fe567aea",
+			"de1539d5dd0f12203813a363e47ed482757745fde864b536ad4c54385692b17a", true},
+		{25_000_000, 32 << 10, "31d664f1a1e33df2e2c4cd486c5e6ab2a9d74f2b",
+			"ff96a178f0b29802a2244e7002060fd76358a900b1d107e9ff7134a20eea8b8b", true},
+	}
+	for _, tt := range tests {
+		data := make([]byte, tt.size)
+		for i := range data {
+			data[i] = byte(i % 251)
+		}
+		path := filepath.Join(t.TempDir(), "fixture.bin")
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		tor, err := Build(context.Background(), path, "fixture.bin")
+		if err != nil {
+			t.Fatalf("Build(%d bytes): %v", tt.size, err)
+		}
+		if tor.PieceLength != tt.pieceLength {
+			t.Errorf("%d bytes: PieceLength = %d, want %d", tt.size, tor.PieceLength, tt.pieceLength)
+		}
+		if tor.V1() != tt.v1 {
+			t.Errorf("%d bytes: V1 = %s, want %s", tt.size, tor.V1(), tt.v1)
+		}
+		if tor.V2() != tt.v2 {
+			t.Errorf("%d bytes: V2 = %s, want %s", tt.size, tor.V2(), tt.v2)
+		}
+		if (tor.pieceLayers != nil) != tt.layers {
+			t.Errorf("%d bytes: piece layers present = %v, want %v", tt.size, tor.pieceLayers != nil, tt.layers)
+		}
+		if magnet := tor.Magnet(); !strings.Contains(magnet, "btih:"+tt.v1) || !strings.Contains(magnet, "btmh:1220"+tt.v2) {
+			t.Errorf("%d bytes: magnet %s lacks the infohashes", tt.size, magnet)
+		}
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	dir := t.TempDir()
+	empty := filepath.Join(dir, "empty")
+	if err := os.WriteFile(empty, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Build(context.Background(), empty, "empty"); err != ErrEmptyFile {
+		t.Errorf("Build(empty) = %v, want ErrEmptyFile", err)
+	}
+
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, []byte("data"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"", "a/b", `a\b`} {
+		if _, err := Build(context.Background(), file, name); err == nil {
+			t.Errorf("Build(name %q) returned no error", name)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"NostrFilePublisher/nip35"
+	"NostrFilePublisher/torrent"
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// buildTorrent gera o torrent híbrido (v1 e v2) do arquivo enviado, com as
+// URLs dos servidores Blossom como web seeds. O torrent descreve exatamente
+// os bytes servidos pelos web seeds, mesmo que os metadados da imagem tenham
+// sido removidos antes do envio. onDone recebe nil em caso de erro, que é
+// exibido ao usuário.
+func buildTorrent(win fyne.Window, path string, uploaded localUpload, onDone func(*torrent.Torrent)) {
+	var t *torrent.Torrent
+	runCancellable(win, "Gerando Torrent", "Calculando os infohashes BitTorrent v1 e v2 do arquivo...",
+		func(ctx context.Context) error {
+			err := withUploadedSource(path, uploaded, func(source string) (err error) {
+				t, err = torrent.Build(ctx, source, filepath.Base(path))
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			for _, r := range uploaded.Responses {
+				if r.URL != "" && !slices.Contains(t.WebSeeds, r.URL) {
+					t.WebSeeds = append(t.WebSeeds, r.URL)
+				}
+			}
+			return nil
+		},
+		func(err error) {
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("Erro ao gerar o torrent: %w", err), win)
+				onDone(nil)
+				return
+			}
+			log.Printf("Torrent gerado: v1 %s | v2 %s | peça de %d bytes", t.V1(), t.V2(), t.PieceLength)
+			onDone(t)
+		})
+}
+
+// showTorrentDialog exibe os infohashes e o link magnet do torrent, permite
+// informar trackers, salvar o arquivo .torrent e publicar o evento de torrent
+// da NIP-35 (kind 2003). onChange é chamado quando os trackers mudam, o que
+// altera o link magnet.
+func showTorrentDialog(win fyne.Window, t *torrent.Torrent, title, description string, hashtags []string, onChange func()) {
+	readOnly := func(value string) *widget.Entry {
+		entry := widget.NewEntry()
+		entry.SetText(value)
+		return entry
+	}
+	magnetEntry := readOnly(t.Magnet())
+
+	trackersEntry := widget.NewMultiLineEntry()
+	trackersEntry.SetPlaceHolder("udp://tracker.example.org:1337/announce\n(um por linha, opcional)")
+	trackersEntry.SetMinRowsVisible(3)
+	trackersEntry.SetText(strings.Join(t.Trackers, "\n"))
+	trackersEntry.OnChanged = func(s string) {
+		t.Trackers = nil
+		for _, line := range strings.Split(s, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				t.Trackers = append(t.Trackers, line)
+			}
+		}
+		magnetEntry.SetText(t.Magnet())
+		onChange()
+	}
+
+	titleEntry := widget.NewEntry()
+	titleEntry.SetText(title)
+	if title == "" {
+		titleEntry.SetText(t.Name)
+	}
+
+	webSeeds := widget.NewLabel("Web seeds:\n" + strings.Join(t.WebSeeds, "\n"))
+	webSeeds.Wrapping = fyne.TextWrapBreak
+
+	saveButton := widget.NewButton("Salvar .torrent", func() {
+		data, err := t.Encode()
+		if err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		fd := dialog.NewFileSave(func(w fyne.URIWriteCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, win)
+				return
+			}
+			if w == nil {
+				return
+			}
+			defer w.Close()
+			if _, err := w.Write(data); err != nil {
+				dialog.ShowError(fmt.Errorf("Erro ao salvar o arquivo .torrent: %w", err), win)
+				return
+			}
+			log.Println("Arquivo .torrent salvo em", w.URI().Path())
+		}, win)
+		fd.SetFileName(t.Name + ".torrent")
+		fd.Show()
+	})
+
+	publishButton := widget.NewButton("Publicar Evento de Torrent (Kind 2003)", func() {
+		if App.Nsec == "" {
+			dialog.ShowInformation("Atenção", "Por favor, configure sua chave NSEC.", win)
+			return
+		}
+		evt := nip35.Event(strings.TrimSpace(titleEntry.Text), description, t.V1(),
+			[]nip35.File{{Name: t.Name, Size: t.Length}}, t.Trackers, hashtags)
+		evt.CreatedAt = nostr.Now()
+		evt.PubKey = App.Npub
+		if err := evt.Sign(App.Nsec); err != nil {
+			dialog.ShowError(fmt.Errorf("Erro ao assinar o evento: %w", err), win)
+			return
+		}
+		showPublishDialog(win, evt)
+	})
+
+	d := dialog.NewCustom("Torrent", "Fechar", container.NewVBox(
+		widget.NewLabel(fmt.Sprintf("%s | %d bytes | peças de %d KiB", t.Name, t.Length, t.PieceLength>>10)),
+		copyableRow("Infohash v1", readOnly(t.V1())),
+		copyableRow("Infohash v2", readOnly(t.V2())),
+		copyableRow("Magnet", magnetEntry),
+		webSeeds,
+		widget.NewLabel("Trackers:"),
+		trackersEntry,
+		widget.NewForm(widget.NewFormItem("Título", titleEntry)),
+		container.NewCenter(container.NewHBox(saveButton, publishButton)),
+	), win)
+	d.Resize(fyne.NewSize(650, 0))
+	d.Show()
+}
@@ -1,22 +1,44 @@
 package util
 
 import (
+	"NostrFilePublisher/sniff"
 	"bytes"
+	"encoding/hex"
+	"fmt"
 	"io"
 	"net/http"
+	"os"
 	"strings"
+
+	"github.com/minio/sha256-simd"
 )
 
+// HashFile calcula o hash SHA-256 (em hexadecimal) e o tamanho de um arquivo.
+func HashFile(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), size, nil
+}
+
 // GetMimeFromUrl faz uma requisição HTTP para a URL fornecida e tenta determinar o tipo MIME do conteúdo.
-// Ele lê os primeiros 500 bytes do corpo da resposta para detectar o tipo MIME.
+// Ele lê os primeiros bytes do corpo da resposta e usa o pacote sniff, com a extensão da URL como alternativa.
 func GetMimeFromUrl(httpClient *http.Client, url string) (string, error) {
-	const byteLimit = 500
+	const byteLimit = sniff.HeaderSize
 
 	req, err := http.NewRequest(http.MethodGet, url, nil)
 	if err != nil {
 		return "", err
 	}
-	req.Header.Set("Range", "bytes=0-500")
+	req.Header.Set("Range", fmt.Sprintf("bytes=0-%d", byteLimit-1))
 
 	resp, err := httpClient.Do(req)
 	if err != nil {
@@ -41,5 +63,5 @@ func GetMimeFromUrl(httpClient *http.Client, url string) (string, error) {
 		return contentType, nil
 	}
 
-	return http.DetectContentType(buf.Bytes()), nil
+	return sniff.Detect(buf.Bytes(), url), nil
 }
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"NostrFilePublisher/history"
+	"NostrFilePublisher/nip71"
+	"NostrFilePublisher/relay"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+)
+
+// videoFormTags são as tags que o formulário de vídeo gera. As demais tags de
+// um evento carregado para edição são preservadas na republicação.
+var videoFormTags = []string{
+	"d", "imeta", "url", "m", "x", "size", "fallback", "r", "title", "summary",
+	"image", "thumb", "blurhash", "published_at", "t", "i", "content-warning",
+}
+
+// addressableVideos devolve, do histórico local, a versão mais recente de cada
+// vídeo endereçável do autor, da mais nova para a mais antiga.
+func addressableVideos(pubkey string) []history.Entry {
+	latest := make(map[string]history.Entry)
+	var order []string
+	for _, e := range History.Entries() {
+		if e.Event.PubKey != pubkey || !nip71.IsAddressable(e.Event.Kind) {
+			continue
+		}
+		addr := fmt.Sprintf("%d:%s", e.Event.Kind, e.Event.Tags.GetD())
+		prev, ok := latest[addr]
+		if !ok {
+			order = append(order, addr)
+		}
+		if !ok || e.Event.CreatedAt > prev.Event.CreatedAt {
+			latest[addr] = e
+		}
+	}
+	out := make([]history.Entry, len(order))
+	for i, addr := range order {
+		out[i] = latest[addr]
+	}
+	slices.SortFunc(out, func(a, b history.Entry) int { return int(b.Event.CreatedAt - a.Event.CreatedAt) })
+	return out
+}
+
+// showLoadVideoDialog permite escolher um dos nossos vídeos endereçáveis para
+// edição, pelo naddr ou a partir do histórico local. onLoaded recebe a versão
+// mais recente do evento encontrada.
+func showLoadVideoDialog(win fyne.Window, onLoaded func(nostr.Event)) {
+	App.Mutex.Lock()
+	pubkey := App.Npub
+	readRelays := App.ReadRelays()
+	App.Mutex.Unlock()
+	if pubkey == "" {
+		dialog.ShowInformation("Atenção", "Por favor, configure sua chave NSEC na aba de Configurações.", win)
+		return
+	}
+
+	naddrEntry := widget.NewEntry()
+	naddrEntry.SetPlaceHolder("naddr1...")
+
+	entries := addressableVideos(pubkey)
+	selected := -1
+	list := widget.NewList(
+		func() int { return len(entries) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			e := entries[i]
+			o.(*widget.Label).SetText(fmt.Sprintf("[kind %d] %s - d=%s - %s",
+				e.Event.Kind, e.Title(), e.Event.Tags.GetD(), e.Event.CreatedAt.Time().Format("02/01/2006 15:04")))
+		},
+	)
+	list.OnSelected = func(id widget.ListItemID) { selected = id }
+	list.OnUnselected = func(widget.ListItemID) { selected = -1 }
+
+	content := container.NewBorder(
+		container.NewVBox(
+			widget.NewLabel("Endereço do evento (naddr):"),
+			naddrEntry,
+			widget.NewLabel("Ou escolha um vídeo do histórico local:"),
+		),
+		nil, nil, nil,
+		container.NewScroll(list),
+	)
+	d := dialog.NewCustomConfirm("Editar Vídeo Publicado", "Carregar", "Cancelar", content, func(ok bool) {
+		if !ok {
+			return
+		}
+		naddr := strings.TrimPrefix(strings.TrimSpace(naddrEntry.Text), "nostr:")
+		if naddr == "" {
+			if selected < 0 || selected >= len(entries) {
+				dialog.ShowInformation("Atenção", "Informe um naddr ou selecione um vídeo do histórico.", win)
+				return
+			}
+			onLoaded(entries[selected].Event)
+			return
+		}
+
+		prefix, value, err := nip19.Decode(naddr)
+		if err != nil || prefix != "naddr" {
+			dialog.ShowError(fmt.Errorf("naddr inválido: %s", naddr), win)
+			return
+		}
+		pointer := value.(nostr.EntityPointer)
+		if !nip71.IsAddressable(pointer.Kind) {
+			dialog.ShowError(fmt.Errorf("o naddr aponta para um evento de kind %d, que não é um vídeo endereçável", pointer.Kind), win)
+			return
+		}
+		if pointer.PublicKey != pubkey {
+			dialog.ShowError(fmt.Errorf("o evento pertence a outra chave e não pode ser republicado com a sua"), win)
+			return
+		}
+		relays := append([]string(nil), pointer.Relays...)
+		for _, url := range readRelays {
+			if !slices.Contains(relays, url) {
+				relays = append(relays, url)
+			}
+		}
+
+		var evt nostr.Event
+		var found bool
+		runCancellable(win, "Buscando Evento", "Buscando a versão mais recente do evento nos relays...",
+			func(ctx context.Context) (err error) {
+				evt, found, err = relay.FetchAddressable(relays, pointer.PublicKey, pointer.Kind, pointer.Identifier)
+				return err
+			},
+			func(err error) {
+				// O histórico local pode ter uma versão mais nova que a dos relays consultados
+				for _, e := range entries {
+					if e.Event.Kind == pointer.Kind && e.Event.Tags.GetD() == pointer.Identifier &&
+						(!found || e.Event.CreatedAt > evt.CreatedAt) {
+						evt, found = e.Event, true
+					}
+				}
+				if !found {
+					if err == nil {
+						err = fmt.Errorf("evento não encontrado nos relays nem no histórico local")
+					}
+					dialog.ShowError(err, win)
+					return
+				}
+				onLoaded(evt)
+			})
+	}, win)
+	d.Resize(fyne.NewSize(650, 450))
+	d.Show()
+}